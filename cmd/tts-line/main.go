@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/airenas/go-app/pkg/goapp"
 	"github.com/airenas/tts-line/internal/pkg/cache"
-	"github.com/airenas/tts-line/internal/pkg/file"
+	"github.com/airenas/tts-line/internal/pkg/jobs"
 	"github.com/airenas/tts-line/internal/pkg/mongodb"
+	"github.com/airenas/tts-line/internal/pkg/pipeline"
 	"github.com/airenas/tts-line/internal/pkg/processor"
 	"github.com/airenas/tts-line/internal/pkg/service"
 	sapi "github.com/airenas/tts-line/internal/pkg/service/api"
@@ -63,21 +65,36 @@ func mainInt(ctx context.Context) error {
 	data := service.Data{}
 	data.Port = goapp.Config.GetInt("port")
 	utils.MaxLogDataSize = goapp.Config.GetInt("maxLogDataSize")
-	synt := &synthesizer.MainWorker{}
-	synt.AllowCustomCode = goapp.Config.GetBool("allowCustom")
 	sp, err := mongodb.NewSessionProvider(goapp.Config.GetString("mongo.url"))
 	if err != nil {
 		return fmt.Errorf("init mongo session provider: %w", err)
 	}
 	defer sp.Close()
 
-	if err = addProcessors(synt, sp, goapp.Config); err != nil {
+	spec, err := pipeline.LoadSpec(goapp.Config)
+	if err != nil {
+		return fmt.Errorf("load pipeline: %w", err)
+	}
+	pb, err := pipeline.NewBuilder(pipeline.NewRegistry(sp), spec, goapp.Config)
+	if err != nil {
+		return fmt.Errorf("init pipeline builder: %w", err)
+	}
+	synt, err := pb.Synthesize()
+	if err != nil {
 		return fmt.Errorf("init processors: %w", err)
 	}
-
-	if err = addSSMLProcessors(synt, sp, goapp.Config); err != nil {
-		return fmt.Errorf("init SSML processors: %w", err)
+	synt.AllowCustomCode = goapp.Config.GetBool("allowCustom")
+	visemes, err := synthesizer.NewVisemes(goapp.Sub(goapp.Config, "visemes"))
+	if err != nil {
+		return fmt.Errorf("init visemes: %w", err)
+	}
+	synt.Visemes = visemes
+	subtitles, err := synthesizer.NewSubtitles(goapp.Sub(goapp.Config, "subtitles"))
+	if err != nil {
+		return fmt.Errorf("init subtitles: %w", err)
 	}
+	synt.Subtitles = subtitles
+	logPipeline("synthesize", synt)
 
 	//cache
 	cc := goapp.Sub(goapp.Config, "cache")
@@ -92,434 +109,192 @@ func mainInt(ctx context.Context) error {
 	}
 
 	// input configuration
-	data.SyntData.Configurator, err = service.NewTTSConfigurator(goapp.Sub(goapp.Config, "options"))
+	configurator, err := service.NewTTSConfigurator(goapp.Sub(goapp.Config, "options"))
 	if err != nil {
 		return fmt.Errorf("init configurator: %w", err)
 	}
+	data.SyntData.Configurator = configurator
+	data.VoicesData = configurator
+	configurator.SetSSMLMaxChars(goapp.Config.GetInt("validator.maxChars"))
+	data.SSMLValidator = configurator
 
 	// init custom synthesize method
-	data.SyntCustomData.Configurator, err = service.NewTTSConfiguratorNoSSML(goapp.Sub(goapp.Config, "options"))
+	customConfigurator, err := service.NewTTSConfiguratorNoSSML(goapp.Sub(goapp.Config, "options"))
 	if err != nil {
 		return fmt.Errorf("init custom configurator: %w", err)
 	}
-	syntC := &synthesizer.MainWorker{}
-	err = addCustomProcessors(syntC, sp, goapp.Config)
+	if goapp.Config.GetBool("lexicons.enabled") {
+		configurator.EnableStoredLexicons()
+		customConfigurator.EnableStoredLexicons()
+	}
+	data.SyntCustomData.Configurator = customConfigurator
+	syntC, err := pb.Custom()
 	if err != nil {
 		return fmt.Errorf("init custom processors: %w", err)
 	}
+	syntC.Visemes = visemes
+	syntC.Subtitles = subtitles
+	logPipeline("custom", syntC)
 	data.SyntCustomData.Processor = syntC
 	data.InfoGetterData, err = prepareInfoGetter(sp)
 	if err != nil {
 		return fmt.Errorf("init info getter: %w", err)
 	}
-	printBanner()
-
-	go startPerfEndpoint()
-
-	err = service.StartWebServer(&data)
-	if err != nil {
-		return fmt.Errorf("start the service: %w", err)
-	}
-	return nil
-}
-
-func addProcessors(synt *synthesizer.MainWorker, sp *mongodb.SessionProvider, cfg *viper.Viper) error {
-	pr, err := processor.NewAddMetrics(processor.NewMetricsCharsFunc("/synthesize"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
-	}
-	synt.Add(pr)
-	//validator
-	pr, err = processor.NewValidator(cfg.GetInt("validator.maxChars"))
-	if err != nil {
-		return errors.Wrap(err, "can't init validator")
-	}
-	synt.Add(pr)
-
-	ts, err := mongodb.NewTextSaver(sp)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	sv, err := processor.NewSaver(ts, utils.RequestOriginal)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	synt.Add(sv)
-	// cleaner
-	pr, err = processor.NewCleaner(cfg.GetString("clean.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init normalize/clean processor")
-	}
-	synt.Add(pr)
-
-	// normalizer
-	pr, err = processor.NewNormalizer(cfg.GetString("normalize.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init text normalizer processor")
-	}
-	synt.Add(pr)
-	//synt.Add(processor.NewURLReplacer())
-	//db saver
-	sv, err = processor.NewSaver(ts, utils.RequestCleaned)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	synt.Add(sv)
-
-	//number replacer
-	pr, err = processor.NewNumberReplace(cfg.GetString("numberReplace.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init number replace")
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewTagger(cfg.GetString("tagger.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init tagger")
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewURLReplacer(cfg.GetString("urlReader.url"), cfg.GetString("wordTagger.url"))
-	if err != nil {
-		return fmt.Errorf("can't init url replacer: %w", err)
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewTransliterator(cfg.GetString("transliterator.url"))
-	if err != nil {
-		return fmt.Errorf("can't init transliterator: %w", err)
-	}
-	synt.Add(pr)
-
-	//db saver
-	sv, err = processor.NewSaver(ts, utils.RequestNormalized)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	synt.Add(sv)
 
-	pr, err = processor.NewNER()
-	if err != nil {
-		return fmt.Errorf("can't init ner: %w", err)
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewReadSymbols()
-	if err != nil {
-		return fmt.Errorf("can't init readSymbols: %w", err)
-	}
-	synt.Add(pr)
-
-	synt.Add(processor.NewSplitter(cfg.GetInt("splitter.maxChars")))
-
-	partRunner := synthesizer.NewPartRunner(cfg.GetInt("partRunner.workers"))
-	synt.Add(partRunner)
-
-	suffixLoader, err := file.NewLoader(cfg.GetString("suffixLoader.path"))
-	if err != nil {
-		return errors.Wrap(err, "can't init suffix Loader")
-	}
-
-	if cfg.GetBool("loudness.adjust") {
-		synt.Add(processor.NewCalcLoudness(cfg.GetInt("loudness.workers")))
-	}
-	synt.Add(processor.NewJoinAudio(suffixLoader))
-
-	pr, err = processor.NewConverter(cfg.GetString("audioConvert.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init mp3 converter")
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewAddMetrics(processor.NewMetricsWaveLenFunc("/synthesize"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
-	}
-	synt.Add(pr)
-
-	if cfg.GetString("filer.dir") != "" {
-		pr, err = processor.NewFiler(cfg.GetString("filer.dir"))
+	jc := goapp.Sub(goapp.Config, "jobs")
+	if jc != nil {
+		data.Jobs, err = prepareJobRunner(data.SyntData.Processor, sp, jc)
 		if err != nil {
-			return errors.Wrap(err, "can't init filer")
+			return fmt.Errorf("init job runner: %w", err)
 		}
-		synt.Add(pr)
-	}
-	return addPartProcessors(partRunner, cfg)
-}
-
-func addSSMLProcessors(synt *synthesizer.MainWorker, sp *mongodb.SessionProvider, cfg *viper.Viper) error {
-	pr, err := processor.NewAddMetrics(processor.NewMetricsCharsFunc("/synthesize"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
-	}
-	synt.AddSSML(pr)
-	//validator
-	pr, err = processor.NewSSMLValidator(cfg.GetInt("validator.maxChars"))
-	if err != nil {
-		return errors.Wrap(err, "can't init validator")
-	}
-	synt.AddSSML(pr)
-
-	ts, err := mongodb.NewTextSaver(sp)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	sv, err := processor.NewSaver(ts, utils.RequestOriginalSSML)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-	synt.AddSSML(sv)
-
-	var processors []synthesizer.Processor
-
-	// cleaner
-	pr, err = processor.NewCleaner(cfg.GetString("clean.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init normalize/clean processor")
-	}
-	processors = append(processors, pr)
-	// normalizer
-	pr, err = processor.NewNormalizer(cfg.GetString("normalize.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init text normalizer processor")
-	}
-	processors = append(processors, pr)
-	// processors = append(processors, processor.NewURLReplacer())
-	//number replacer
-	pr, err = processor.NewNumberReplace(cfg.GetString("numberReplace.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init number replace")
-	}
-	processors = append(processors, pr)
-	pr, err = processor.NewSSMLTagger(cfg.GetString("tagger.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init tagger")
-	}
-	processors = append(processors, pr)
-
-	pr, err = processor.NewURLReplacer(cfg.GetString("urlReader.url"), cfg.GetString("wordTagger.url"))
-	if err != nil {
-		return fmt.Errorf("can't init url replacer: %w", err)
-	}
-	processors = append(processors, pr)
-
-	pr, err = processor.NewTransliterator(cfg.GetString("transliterator.url"))
-	if err != nil {
-		return fmt.Errorf("can't init transliterator: %w", err)
-	}
-	processors = append(processors, pr)
-
-	pr, err = processor.NewNER()
-	if err != nil {
-		return fmt.Errorf("can't init ner: %w", err)
-	}
-	processors = append(processors, pr)
-	pr, err = processor.NewReadSymbols()
-	if err != nil {
-		return fmt.Errorf("can't init readSymbols: %w", err)
-	}
-	processors = append(processors, pr)
-
-	processors = append(processors, processor.NewSplitter(cfg.GetInt("splitter.maxChars")))
-
-	partRunner := synthesizer.NewPartRunner(cfg.GetInt("partRunner.workers"))
-	processors = append(processors, partRunner)
-
-	synt.AddSSML(processor.NewSSMLPartRunner(processors))
-
-	suffixLoader, err := file.NewLoader(cfg.GetString("suffixLoader.path"))
-	if err != nil {
-		return errors.Wrap(err, "can't init suffix Loader")
-	}
-	if cfg.GetBool("loudness.adjust") {
-		synt.AddSSML(processor.NewCalcLoudnessSSML(cfg.GetInt("loudness.workers")))
-	}
-	synt.AddSSML(processor.NewJoinSSMLAudio(suffixLoader))
-
-	pr, err = processor.NewConverter(cfg.GetString("audioConvert.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init mp3 converter")
-	}
-	synt.AddSSML(pr)
-
-	pr, err = processor.NewAddMetrics(processor.NewMetricsWaveLenFunc("/synthesize"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
+		defer data.Jobs.Close() // before sp.Close, the interrupted jobs are saved
+	} else {
+		goapp.Log.Info().Msg("No async jobs will be used")
 	}
-	synt.AddSSML(pr)
 
-	if cfg.GetString("filer.dir") != "" {
-		pr, err = processor.NewFiler(cfg.GetString("filer.dir"))
+	if goapp.Config.GetBool("lexicons.enabled") && goapp.Config.GetString("lexicons.adminToken") != "" {
+		ls, err := mongodb.NewLexiconStore(sp)
 		if err != nil {
-			return errors.Wrap(err, "can't init filer")
+			return fmt.Errorf("init lexicon store: %w", err)
 		}
-		synt.AddSSML(pr)
-	}
-	return addPartProcessors(partRunner, cfg)
-}
-
-func addCustomProcessors(synt *synthesizer.MainWorker, sp *mongodb.SessionProvider, cfg *viper.Viper) error {
-	pr, err := processor.NewAddMetrics(processor.NewMetricsCharsFunc("/synthesizeCustom"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewValidator(cfg.GetInt("validator.maxChars"))
-	if err != nil {
-		return errors.Wrap(err, "can't init validator")
-	}
-	synt.Add(pr)
-
-	ts, err := mongodb.NewTextSaver(sp)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
-	}
-
-	pr, err = processor.NewLoader(ts)
-	if err != nil {
-		return errors.Wrap(err, "can't init text from DB loader")
-	}
-	synt.Add(pr)
-
-	pr, err = processor.NewComparator(cfg.GetString("comparator.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init text comparator")
+		data.Lexicons, err = service.NewLexiconsData(ls, goapp.Sub(goapp.Config, "lexicons"))
+		if err != nil {
+			return fmt.Errorf("init lexicons: %w", err)
+		}
+	} else {
+		goapp.Log.Info().Msg("No lexicon management routes will be used")
 	}
-	synt.Add(pr)
 
-	sv, err := processor.NewSaver(ts, utils.RequestUser)
-	if err != nil {
-		return errors.Wrap(err, "can't init text to DB saver")
+	if goapp.Config.GetBool("analyze.enabled") {
+		syntA, err := pb.Analyze()
+		if err != nil {
+			return fmt.Errorf("init analyze: %w", err)
+		}
+		logPipeline("analyze", syntA)
+		data.Analyze = &service.AnalyzeData{Processor: syntA, Configurator: configurator}
+	} else {
+		goapp.Log.Info().Msg("No analyze method will be used")
 	}
-	synt.Add(sv)
 
-	pr, err = processor.NewTaggerAccents(cfg.GetString("tagger.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init tagger")
+	rc := goapp.Sub(goapp.Config, "ready")
+	if rc != nil {
+		data.Ready, err = prepareReadyData(goapp.Config, rc, sp)
+		if err != nil {
+			return fmt.Errorf("init ready: %w", err)
+		}
+	} else {
+		goapp.Log.Info().Msg("No readiness probes will be used")
 	}
-	synt.Add(pr)
 
-	pr, err = processor.NewTransliterator(cfg.GetString("transliterator.url"))
-	if err != nil {
-		return fmt.Errorf("can't init transliterator: %w", err)
+	wc := goapp.Sub(goapp.Config, "ws")
+	if wc != nil {
+		data.WS, err = prepareWSData(goapp.Config, wc)
+		if err != nil {
+			return fmt.Errorf("init ws: %w", err)
+		}
+	} else {
+		goapp.Log.Info().Msg("No websocket synthesis will be used")
 	}
-	synt.Add(pr)
+	printBanner()
 
-	pr, err = processor.NewNER()
-	if err != nil {
-		return fmt.Errorf("can't init ner: %w", err)
-	}
-	synt.Add(pr)
+	go startPerfEndpoint()
 
-	pr, err = processor.NewReadSymbols()
-	if err != nil {
-		return fmt.Errorf("can't init readSymbols: %w", err)
+	if port := goapp.Config.GetInt("grpc.port"); port > 0 {
+		stop, err := service.StartGRPCServer(port, &data.SyntData)
+		if err != nil {
+			return fmt.Errorf("start the gRPC service: %w", err)
+		}
+		defer stop()
+	} else {
+		goapp.Log.Info().Msg("No gRPC service will be started")
 	}
-	synt.Add(pr)
-
-	synt.Add(processor.NewSplitter(cfg.GetInt("splitter.maxChars")))
-
-	partRunner := synthesizer.NewPartRunner(cfg.GetInt("partRunner.workers"))
-	synt.Add(partRunner)
 
-	suffixLoader, err := file.NewLoader(cfg.GetString("suffixLoader.path"))
+	err = service.StartWebServer(&data)
 	if err != nil {
-		return errors.Wrap(err, "can't init suffix Loader")
-	}
-
-	if cfg.GetBool("loudness.adjust") {
-		synt.Add(processor.NewCalcLoudness(cfg.GetInt("loudness.workers")))
+		return fmt.Errorf("start the service: %w", err)
 	}
-	synt.Add(processor.NewJoinAudio(suffixLoader))
+	return nil
+}
 
-	pr, err = processor.NewConverter(cfg.GetString("audioConvert.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init audioConvert converter")
-	}
-	synt.Add(pr)
+type infoGetter struct {
+	ts *mongodb.TextSaver
+}
 
-	pr, err = processor.NewAddMetrics(processor.NewMetricsWaveLenFunc("/synthesizeCustom"))
-	if err != nil {
-		return errors.Wrap(err, "can't init metrics processor")
-	}
-	synt.Add(pr)
+func (ig *infoGetter) Provide(rID string) (*sapi.InfoResult, error) {
+	res := sapi.InfoResult{}
+	var err error
+	res.Count, err = ig.ts.GetCount(rID, utils.RequestUser)
+	return &res, err
+}
 
-	if cfg.GetString("filer.dir") != "" {
-		pr, err = processor.NewFiler(cfg.GetString("filer.dir"))
-		if err != nil {
-			return errors.Wrap(err, "can't init filer")
-		}
-		synt.Add(pr)
+func logPipeline(name string, mw *synthesizer.MainWorker) {
+	goapp.Log.Info().Str("pipeline", name).Msgf("text processors:\n%s", mw.GetProcessorsInfo())
+	if info := mw.GetSSMLProcessorsInfo(); info != "" {
+		goapp.Log.Info().Str("pipeline", name).Msgf("SSML processors:\n%s", info)
 	}
-	return addPartProcessors(partRunner, cfg)
 }
 
-func addPartProcessors(partRunner *synthesizer.PartRunner, cfg *viper.Viper) error {
-	ppr, err := processor.NewObsceneFilter(cfg.GetString("obscene.url"))
+func prepareInfoGetter(sp *mongodb.SessionProvider) (*infoGetter, error) {
+	ts, err := mongodb.NewTextSaver(sp)
 	if err != nil {
-		return errors.Wrap(err, "can't init obscene filter service")
+		return nil, errors.Wrap(err, "can't init text to DB saver")
 	}
-	partRunner.Add(ppr)
+	return &infoGetter{ts: ts}, nil
+}
 
-	ppr, err = processor.NewAcronyms(cfg.GetString("acronyms.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init acronyms service")
+func prepareJobRunner(synt service.Synthesizer, sp *mongodb.SessionProvider, cfg *viper.Viper) (*service.JobRunner, error) {
+	var store service.JobStore
+	var err error
+	switch st := cfg.GetString("store"); st {
+	case "", "memory":
+		store, err = jobs.NewMemoryStore(cfg.GetDuration("ttl"))
+	case "mongo":
+		store, err = mongodb.NewJobStore(sp, cfg.GetDuration("ttl"))
+	default:
+		return nil, errors.Errorf("unknown job store '%s'", st)
 	}
-	partRunner.Add(ppr)
-
-	ppr, err = processor.NewAccentuator(cfg.GetString("accenter.url"))
 	if err != nil {
-		return errors.Wrap(err, "can't init accenter")
+		return nil, errors.Wrap(err, "can't init job store")
 	}
-	partRunner.Add(ppr)
+	return service.NewJobRunner(synt, store, cfg)
+}
 
-	ppr, err = processor.NewClitics(cfg.GetString("clitics.url"))
+func prepareWSData(cfg, wsCfg *viper.Viper) (*service.WSData, error) {
+	splitter, err := processor.NewSentenceSplitter(cfg.GetString("tagger.url"))
 	if err != nil {
-		return errors.Wrap(err, "can't init clitics")
+		return nil, errors.Wrap(err, "can't init sentence splitter")
 	}
-	partRunner.Add(ppr)
+	return service.NewWSData(splitter, wsCfg)
+}
 
-	ppr, err = processor.NewTranscriber(cfg.GetString("transcriber.url"))
-	if err != nil {
-		return errors.Wrap(err, "can't init transcriber")
-	}
-	partRunner.Add(ppr)
+// readyHTTPServices are the config sections of the downstream HTTP services
+var readyHTTPServices = []string{"clean", "normalize", "numberReplace", "tagger", "urlReader", "wordTagger",
+	"transliterator", "comparator", "obscene", "acronyms", "accenter", "clitics", "transcriber", "acousticModel", "vocoder"}
 
-	ppr, err = processor.NewAcousticModel(goapp.Sub(cfg, "acousticModel"))
+func prepareReadyData(cfg, readyCfg *viper.Viper, sp *mongodb.SessionProvider) (*service.ReadyData, error) {
+	res, err := service.NewReadyData(readyCfg)
 	if err != nil {
-		return errors.Wrap(err, "can't init acousticModel")
+		return nil, err
 	}
-	partRunner.Add(ppr)
-
-	if !cfg.GetBool("acousticModel.hasVocoder") {
-		ppr, err = processor.NewVocoder(cfg.GetString("vocoder.url"))
+	for _, name := range readyHTTPServices {
+		urlStr := cfg.GetString(name + ".url")
+		if urlStr == "" || (name == "vocoder" && cfg.GetBool("acousticModel.hasVocoder")) {
+			continue
+		}
+		// the AM url contains the voice template
+		p, err := service.NewHTTPProbe(strings.ReplaceAll(urlStr, "{{voice}}", ""))
 		if err != nil {
-			return errors.Wrap(err, "can't init vocoder")
+			return nil, errors.Wrapf(err, "can't init %s probe", name)
 		}
-		partRunner.Add(ppr)
+		res.Add(name, p)
 	}
-
-	return nil
-}
-
-type infoGetter struct {
-	ts *mongodb.TextSaver
-}
-
-func (ig *infoGetter) Provide(rID string) (*sapi.InfoResult, error) {
-	res := sapi.InfoResult{}
-	var err error
-	res.Count, err = ig.ts.GetCount(rID, utils.RequestUser)
-	return &res, err
-}
-
-func prepareInfoGetter(sp *mongodb.SessionProvider) (*infoGetter, error) {
-	ts, err := mongodb.NewTextSaver(sp)
+	p, err := service.NewGRPCProbe(cfg.GetString("audioConvert.url"))
 	if err != nil {
-		return nil, errors.Wrap(err, "can't init text to DB saver")
+		return nil, errors.Wrap(err, "can't init audioConvert probe")
 	}
-	return &infoGetter{ts: ts}, nil
+	res.Add("audioConvert", p)
+	res.Add("mongo", sp.Healthy)
+	return res, nil
 }
 
 func startPerfEndpoint() {
@@ -29,6 +29,28 @@ func ToAccentString(w string, a int) (string, error) {
 	return string(rn[:pos]) + as + string(rn[pos+1:]), nil
 }
 
+// FromAccentString is the inverse of ToAccentString, it returns the word without the accent mark and the accent
+func FromAccentString(v string) (string, int, error) {
+	rns := []rune(v)
+	sb := strings.Builder{}
+	acc, li := 0, 0
+	for i := 0; i < len(rns); i++ {
+		if i < (len(rns)-3) && rns[i] == '{' &&
+			unicode.IsLetter(rns[i+1]) && Value(rns[i+2]) > 0 && rns[i+3] == '}' {
+			if acc != 0 {
+				return "", 0, errors.Errorf("several accents in %s", v)
+			}
+			acc = Value(rns[i+2])*100 + li + 1
+			sb.WriteRune(rns[i+1])
+			i = i + 3
+		} else {
+			sb.WriteRune(rns[i])
+		}
+		li++
+	}
+	return sb.String(), acc, nil
+}
+
 // Value returns accent value as int or 0
 func Value(r rune) int {
 	if r == Kairinis {
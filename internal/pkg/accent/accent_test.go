@@ -32,6 +32,29 @@ func TestToAccentString(t *testing.T) {
 	}
 }
 
+func TestFromAccentString(t *testing.T) {
+	tests := []struct {
+		v   string
+		e   string
+		a   int
+		err bool
+	}{
+		{v: "m{a\\}ma", e: "mama", a: 102},
+		{v: "m{a/}ma", e: "mama", a: 202},
+		{v: "{m~}ama", e: "mama", a: 301},
+		{v: "ūk{ū~}s", e: "ūkūs", a: 303},
+		{v: "mama", e: "mama", a: 0},
+		{v: "m{a/}m{a/}", err: true},
+	}
+
+	for i, tc := range tests {
+		v, a, err := FromAccentString(tc.v)
+		assert.Equal(t, tc.err, err != nil, "Fail %d", i)
+		assert.Equal(t, tc.e, v, "Fail %d", i)
+		assert.Equal(t, tc.a, a, "Fail %d", i)
+	}
+}
+
 func TestValue(t *testing.T) {
 	tests := []struct {
 		v rune
@@ -0,0 +1,39 @@
+package audio
+
+import (
+	"fmt"
+)
+
+// Resample changes the sample rate of 16 bit mono PCM data using the linear interpolation
+func Resample(b []byte, from, to uint32, bytesPerSample int) ([]byte, error) {
+	if bytesPerSample != 2 {
+		return nil, fmt.Errorf("unsupported bytes per sample %d", bytesPerSample)
+	}
+	if from == 0 || to == 0 {
+		return nil, fmt.Errorf("wrong sample rate %d -> %d", from, to)
+	}
+	if from == to {
+		return b, nil
+	}
+	l := len(b) / bytesPerSample
+	if l == 0 {
+		return []byte{}, nil
+	}
+	rl := int(int64(l) * int64(to) / int64(from))
+	res := make([]byte, rl*bytesPerSample)
+	sample := func(i int) float64 {
+		return float64(int16(b[i*2]) | int16(b[i*2+1])<<8)
+	}
+	for i := 0; i < rl; i++ {
+		pos := float64(i) * float64(from) / float64(to)
+		at := int(pos)
+		v := sample(at)
+		if at+1 < l {
+			v += (sample(at+1) - v) * (pos - float64(at))
+		}
+		s := toInt16(v)
+		res[i*2] = byte(s & 0xFF)
+		res[i*2+1] = byte((s >> 8) & 0xFF)
+	}
+	return res, nil
+}
@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func toBytes(s ...int16) []byte {
+	res := make([]byte, 0, len(s)*2)
+	for _, v := range s {
+		res = append(res, byte(v&0xFF), byte((v>>8)&0xFF))
+	}
+	return res
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		b        []byte
+		from, to uint32
+		want     []byte
+		wantErr  bool
+	}{
+		{name: "same", b: toBytes(1, 2, 3), from: 100, to: 100, want: toBytes(1, 2, 3)},
+		{name: "up", b: toBytes(0, 100, -100, 0), from: 100, to: 200, want: toBytes(0, 50, 100, 0, -100, -50, 0, 0)},
+		{name: "down", b: toBytes(0, 50, 100, 0, -100, -50), from: 200, to: 100, want: toBytes(0, 100, -100)},
+		{name: "empty", b: []byte{}, from: 200, to: 100, want: []byte{}},
+		{name: "wrong rate", b: toBytes(1), from: 0, to: 100, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resample(tt.b, tt.from, tt.to, 2)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResample_FailBytes(t *testing.T) {
+	_, err := Resample(toBytes(1, 2), 100, 200, 3)
+	assert.Error(t, err)
+}
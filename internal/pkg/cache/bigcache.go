@@ -77,14 +77,16 @@ func (c *BigCacher) Work(ctx context.Context, inp *api.TTSRequestConfig) (*api.R
 	}
 	log.Ctx(ctx).Debug().Msg("Not found in cache")
 	res, err := c.realSynt.Work(ctx, inp)
-	if res != nil && err == nil {
+	if res != nil && err == nil && len(res.SkippedStages) == 0 { // do not keep degraded audio
 		_ = c.cache.Set(k, res.Audio)
 	}
 	return res, err
 }
 
 func (c *BigCacher) isOK(inp *api.TTSRequestConfig) bool {
-	return (c.maxTextLen == 0 || len(inp.Text) <= c.maxTextLen) && inp.OutputTextFormat == api.TextNone && len(inp.SpeechMarkTypes) == 0
+	return (c.maxTextLen == 0 || len(inp.Text) <= c.maxTextLen) && inp.OutputTextFormat == api.TextNone && len(inp.SpeechMarkTypes) == 0 &&
+		inp.AudioStream == nil &&
+		len(inp.Lexicons) == 0 // stored lexicons may change
 }
 
 func getCleanDuration(dur time.Duration) time.Duration {
@@ -103,6 +105,9 @@ func key(inp *api.TTSRequestConfig) string {
 	h.Write([]byte(strconv.FormatInt(inp.MaxEdgeSilenceMillis, 10)))
 	h.Write([]byte(inp.SymbolMode))
 	h.Write([]byte(fmt.Sprintf("%s", inp.SelectedSymbols)))
+	for _, e := range inp.Lexicon {
+		h.Write([]byte(fmt.Sprintf("%v", *e)))
+	}
 	//	return inp.Text + "_" + inp.OutputFormat.String() + "_" + fmt.Sprintf("%.4f", inp.Speed) + "_" + inp.Voice + "_" + strconv.FormatInt(inp.MaxEdgeSilenceMillis, 10)
 	return strconv.FormatUint(h.Sum64(), 36)
 }
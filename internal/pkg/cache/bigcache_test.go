@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"strings"
 	"testing"
@@ -83,6 +84,20 @@ func TestWork_Failure(t *testing.T) {
 	synthesizerMock.AssertNumberOfCalls(t, "Work", 2)
 }
 
+func TestWork_SkipDegraded(t *testing.T) {
+	initTest(t)
+	c, _ := NewCacher(synthesizerMock, newTestConfig("duration: 10s"))
+	assert.NotNil(t, c)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav"), SkippedStages: []string{"acronyms"}}, nil)
+
+	res, err := c.Work(context.TODO(), newtestInput("olia"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"acronyms"}, res.SkippedStages)
+	_, err = c.Work(context.TODO(), newtestInput("olia"))
+	assert.Nil(t, err)
+	synthesizerMock.AssertNumberOfCalls(t, "Work", 2)
+}
+
 func TestWork_NoCache(t *testing.T) {
 	initTest(t)
 	c, _ := NewCacher(synthesizerMock, newTestConfig("duration: 0s"))
@@ -173,6 +188,8 @@ func TestBigCacher_isOK(t *testing.T) {
 		{"Normalized", args{&api.TTSRequestConfig{Text: "aaa", OutputTextFormat: api.TextNormalized}}, false},
 		{"Long", args{&api.TTSRequestConfig{Text: "111111111111111", OutputTextFormat: api.TextNone}}, false},
 		{"tags", args{&api.TTSRequestConfig{Text: "aaa", OutputTextFormat: api.TextNone, SpeechMarkTypes: map[string]bool{"word": true}}}, false},
+		{"stream", args{&api.TTSRequestConfig{Text: "aaa", OutputTextFormat: api.TextNone, AudioStream: &bytes.Buffer{}}}, false},
+		{"lexicons", args{&api.TTSRequestConfig{Text: "aaa", OutputTextFormat: api.TextNone, Lexicons: []string{"l1"}}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -216,6 +233,10 @@ func Test_key(t *testing.T) {
 		{"max sil duration", args{&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioM4A,
 			OutputTextFormat: api.TextAccented, Speed: 0.56, Voice: "aaa", MaxEdgeSilenceMillis: 50}},
 			"xkfaexfzey56"},
+		{"lexicon", args{&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioM4A,
+			OutputTextFormat: api.TextAccented, Speed: 0.56, Voice: "aaa", MaxEdgeSilenceMillis: 50,
+			Lexicon: []*api.LexiconEntry{{Grapheme: "olia", Accented: "{o/}lia"}}}},
+			"3dl0trc67bzk9"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
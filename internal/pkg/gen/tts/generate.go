@@ -0,0 +1,2 @@
+//go:generate protoc --go-grpc_out=require_unimplemented_servers=false:./../.. --go_out=./../.. protos/tts.proto
+package tts
@@ -0,0 +1,634 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: protos/tts.proto
+
+package tts
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// SynthesizeInput mirrors the JSON input of /synthesize
+type SynthesizeInput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Text  string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	// text, ssml
+	TextType string `protobuf:"bytes,2,opt,name=text_type,json=textType,proto3" json:"text_type,omitempty"`
+	// m4a, mp3, wav, ulaw, none
+	OutputFormat string `protobuf:"bytes,3,opt,name=output_format,json=outputFormat,proto3" json:"output_format,omitempty"`
+	// normalized, accented, transcribed, vtt, srt, ssml
+	OutputTextFormat string  `protobuf:"bytes,4,opt,name=output_text_format,json=outputTextFormat,proto3" json:"output_text_format,omitempty"`
+	SaveRequest      *bool   `protobuf:"varint,5,opt,name=save_request,json=saveRequest,proto3,oneof" json:"save_request,omitempty"`
+	Speed            float64 `protobuf:"fixed64,6,opt,name=speed,proto3" json:"speed,omitempty"`
+	Voice            string  `protobuf:"bytes,7,opt,name=voice,proto3" json:"voice,omitempty"`
+	Priority         int32   `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	// word, sentence, ssml, phoneme, viseme
+	SpeechMarkTypes      []string `protobuf:"bytes,9,rep,name=speech_mark_types,json=speechMarkTypes,proto3" json:"speech_mark_types,omitempty"`
+	MaxEdgeSilenceMillis *int64   `protobuf:"varint,10,opt,name=max_edge_silence_millis,json=maxEdgeSilenceMillis,proto3,oneof" json:"max_edge_silence_millis,omitempty"`
+	// read, readSelected, readAll
+	SymbolMode      string   `protobuf:"bytes,11,opt,name=symbol_mode,json=symbolMode,proto3" json:"symbol_mode,omitempty"`
+	SelectedSymbols []string `protobuf:"bytes,12,rep,name=selected_symbols,json=selectedSymbols,proto3" json:"selected_symbols,omitempty"`
+	// overrides the pronunciation of the words
+	Lexicon []*LexiconEntry `protobuf:"bytes,13,rep,name=lexicon,proto3" json:"lexicon,omitempty"`
+	// names of the stored lexicons, the first one is preferred
+	Lexicons      []string `protobuf:"bytes,14,rep,name=lexicons,proto3" json:"lexicons,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SynthesizeInput) Reset() {
+	*x = SynthesizeInput{}
+	mi := &file_protos_tts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynthesizeInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynthesizeInput) ProtoMessage() {}
+
+func (x *SynthesizeInput) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_tts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynthesizeInput.ProtoReflect.Descriptor instead.
+func (*SynthesizeInput) Descriptor() ([]byte, []int) {
+	return file_protos_tts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SynthesizeInput) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetTextType() string {
+	if x != nil {
+		return x.TextType
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetOutputFormat() string {
+	if x != nil {
+		return x.OutputFormat
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetOutputTextFormat() string {
+	if x != nil {
+		return x.OutputTextFormat
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetSaveRequest() bool {
+	if x != nil && x.SaveRequest != nil {
+		return *x.SaveRequest
+	}
+	return false
+}
+
+func (x *SynthesizeInput) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *SynthesizeInput) GetVoice() string {
+	if x != nil {
+		return x.Voice
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *SynthesizeInput) GetSpeechMarkTypes() []string {
+	if x != nil {
+		return x.SpeechMarkTypes
+	}
+	return nil
+}
+
+func (x *SynthesizeInput) GetMaxEdgeSilenceMillis() int64 {
+	if x != nil && x.MaxEdgeSilenceMillis != nil {
+		return *x.MaxEdgeSilenceMillis
+	}
+	return 0
+}
+
+func (x *SynthesizeInput) GetSymbolMode() string {
+	if x != nil {
+		return x.SymbolMode
+	}
+	return ""
+}
+
+func (x *SynthesizeInput) GetSelectedSymbols() []string {
+	if x != nil {
+		return x.SelectedSymbols
+	}
+	return nil
+}
+
+func (x *SynthesizeInput) GetLexicon() []*LexiconEntry {
+	if x != nil {
+		return x.Lexicon
+	}
+	return nil
+}
+
+func (x *SynthesizeInput) GetLexicons() []string {
+	if x != nil {
+		return x.Lexicons
+	}
+	return nil
+}
+
+// LexiconEntry is the user's pronunciation of a word
+type LexiconEntry struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Grapheme string                 `protobuf:"bytes,1,opt,name=grapheme,proto3" json:"grapheme,omitempty"`
+	// accented word, e.g. M{a/}ikrosoftas
+	Accented string `protobuf:"bytes,2,opt,name=accented,proto3" json:"accented,omitempty"`
+	// syllables separated by '-', e.g. mai-kro-sof-tas
+	Syllables string `protobuf:"bytes,3,opt,name=syllables,proto3" json:"syllables,omitempty"`
+	// pronounced word with syllables and accent marks 3, 4 or 9, e.g. mai4-kro-sof-tas
+	Transcription string `protobuf:"bytes,4,opt,name=transcription,proto3" json:"transcription,omitempty"`
+	// limits the entry to the word forms of the lemma
+	Lemma string `protobuf:"bytes,5,opt,name=lemma,proto3" json:"lemma,omitempty"`
+	// limits the entry to the words with the morphological info starting with the value, e.g. Ncf
+	Mi            string `protobuf:"bytes,6,opt,name=mi,proto3" json:"mi,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LexiconEntry) Reset() {
+	*x = LexiconEntry{}
+	mi := &file_protos_tts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LexiconEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LexiconEntry) ProtoMessage() {}
+
+func (x *LexiconEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_tts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LexiconEntry.ProtoReflect.Descriptor instead.
+func (*LexiconEntry) Descriptor() ([]byte, []int) {
+	return file_protos_tts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LexiconEntry) GetGrapheme() string {
+	if x != nil {
+		return x.Grapheme
+	}
+	return ""
+}
+
+func (x *LexiconEntry) GetAccented() string {
+	if x != nil {
+		return x.Accented
+	}
+	return ""
+}
+
+func (x *LexiconEntry) GetSyllables() string {
+	if x != nil {
+		return x.Syllables
+	}
+	return ""
+}
+
+func (x *LexiconEntry) GetTranscription() string {
+	if x != nil {
+		return x.Transcription
+	}
+	return ""
+}
+
+func (x *LexiconEntry) GetLemma() string {
+	if x != nil {
+		return x.Lemma
+	}
+	return ""
+}
+
+func (x *LexiconEntry) GetMi() string {
+	if x != nil {
+		return x.Mi
+	}
+	return ""
+}
+
+type SpeechMark struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// from the start of the audio
+	TimeMillis     int64 `protobuf:"varint,1,opt,name=time_millis,json=timeMillis,proto3" json:"time_millis,omitempty"`
+	DurationMillis int64 `protobuf:"varint,2,opt,name=duration_millis,json=durationMillis,proto3" json:"duration_millis,omitempty"`
+	// word, sentence, ssml, phoneme, viseme
+	Type  string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Value string `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// character offsets of the marked text in the input, for SSML in the raw XML
+	Start         int32 `protobuf:"varint,5,opt,name=start,proto3" json:"start,omitempty"`
+	End           int32 `protobuf:"varint,6,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpeechMark) Reset() {
+	*x = SpeechMark{}
+	mi := &file_protos_tts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeechMark) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechMark) ProtoMessage() {}
+
+func (x *SpeechMark) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_tts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechMark.ProtoReflect.Descriptor instead.
+func (*SpeechMark) Descriptor() ([]byte, []int) {
+	return file_protos_tts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SpeechMark) GetTimeMillis() int64 {
+	if x != nil {
+		return x.TimeMillis
+	}
+	return 0
+}
+
+func (x *SpeechMark) GetDurationMillis() int64 {
+	if x != nil {
+		return x.DurationMillis
+	}
+	return 0
+}
+
+func (x *SpeechMark) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SpeechMark) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *SpeechMark) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *SpeechMark) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+type SynthesizeReply struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Audio       []byte                 `protobuf:"bytes,1,opt,name=audio,proto3" json:"audio,omitempty"`
+	Text        string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	RequestId   string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	SpeechMarks []*SpeechMark          `protobuf:"bytes,4,rep,name=speech_marks,json=speechMarks,proto3" json:"speech_marks,omitempty"`
+	// failed optional pipeline stages, the result is degraded if not empty
+	SkippedStages []string `protobuf:"bytes,5,rep,name=skipped_stages,json=skippedStages,proto3" json:"skipped_stages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SynthesizeReply) Reset() {
+	*x = SynthesizeReply{}
+	mi := &file_protos_tts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynthesizeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynthesizeReply) ProtoMessage() {}
+
+func (x *SynthesizeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_tts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynthesizeReply.ProtoReflect.Descriptor instead.
+func (*SynthesizeReply) Descriptor() ([]byte, []int) {
+	return file_protos_tts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SynthesizeReply) GetAudio() []byte {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+func (x *SynthesizeReply) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *SynthesizeReply) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SynthesizeReply) GetSpeechMarks() []*SpeechMark {
+	if x != nil {
+		return x.SpeechMarks
+	}
+	return nil
+}
+
+func (x *SynthesizeReply) GetSkippedStages() []string {
+	if x != nil {
+		return x.SkippedStages
+	}
+	return nil
+}
+
+type SynthesizeStreamReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*SynthesizeStreamReply_Chunk
+	//	*SynthesizeStreamReply_Result
+	Payload       isSynthesizeStreamReply_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SynthesizeStreamReply) Reset() {
+	*x = SynthesizeStreamReply{}
+	mi := &file_protos_tts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SynthesizeStreamReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SynthesizeStreamReply) ProtoMessage() {}
+
+func (x *SynthesizeStreamReply) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_tts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SynthesizeStreamReply.ProtoReflect.Descriptor instead.
+func (*SynthesizeStreamReply) Descriptor() ([]byte, []int) {
+	return file_protos_tts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SynthesizeStreamReply) GetPayload() isSynthesizeStreamReply_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SynthesizeStreamReply) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Payload.(*SynthesizeStreamReply_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *SynthesizeStreamReply) GetResult() *SynthesizeReply {
+	if x != nil {
+		if x, ok := x.Payload.(*SynthesizeStreamReply_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isSynthesizeStreamReply_Payload interface {
+	isSynthesizeStreamReply_Payload()
+}
+
+type SynthesizeStreamReply_Chunk struct {
+	Chunk []byte `protobuf:"bytes,1,opt,name=chunk,proto3,oneof"`
+}
+
+type SynthesizeStreamReply_Result struct {
+	Result *SynthesizeReply `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*SynthesizeStreamReply_Chunk) isSynthesizeStreamReply_Payload() {}
+
+func (*SynthesizeStreamReply_Result) isSynthesizeStreamReply_Payload() {}
+
+var File_protos_tts_proto protoreflect.FileDescriptor
+
+const file_protos_tts_proto_rawDesc = "" +
+	"\n" +
+	"\x10protos/tts.proto\x12\x06tts.v1\"\xb2\x04\n" +
+	"\x0fSynthesizeInput\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1b\n" +
+	"\ttext_type\x18\x02 \x01(\tR\btextType\x12#\n" +
+	"\routput_format\x18\x03 \x01(\tR\foutputFormat\x12,\n" +
+	"\x12output_text_format\x18\x04 \x01(\tR\x10outputTextFormat\x12&\n" +
+	"\fsave_request\x18\x05 \x01(\bH\x00R\vsaveRequest\x88\x01\x01\x12\x14\n" +
+	"\x05speed\x18\x06 \x01(\x01R\x05speed\x12\x14\n" +
+	"\x05voice\x18\a \x01(\tR\x05voice\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\x05R\bpriority\x12*\n" +
+	"\x11speech_mark_types\x18\t \x03(\tR\x0fspeechMarkTypes\x12:\n" +
+	"\x17max_edge_silence_millis\x18\n" +
+	" \x01(\x03H\x01R\x14maxEdgeSilenceMillis\x88\x01\x01\x12\x1f\n" +
+	"\vsymbol_mode\x18\v \x01(\tR\n" +
+	"symbolMode\x12)\n" +
+	"\x10selected_symbols\x18\f \x03(\tR\x0fselectedSymbols\x12.\n" +
+	"\alexicon\x18\r \x03(\v2\x14.tts.v1.LexiconEntryR\alexicon\x12\x1a\n" +
+	"\blexicons\x18\x0e \x03(\tR\blexiconsB\x0f\n" +
+	"\r_save_requestB\x1a\n" +
+	"\x18_max_edge_silence_millis\"\xb0\x01\n" +
+	"\fLexiconEntry\x12\x1a\n" +
+	"\bgrapheme\x18\x01 \x01(\tR\bgrapheme\x12\x1a\n" +
+	"\baccented\x18\x02 \x01(\tR\baccented\x12\x1c\n" +
+	"\tsyllables\x18\x03 \x01(\tR\tsyllables\x12$\n" +
+	"\rtranscription\x18\x04 \x01(\tR\rtranscription\x12\x14\n" +
+	"\x05lemma\x18\x05 \x01(\tR\x05lemma\x12\x0e\n" +
+	"\x02mi\x18\x06 \x01(\tR\x02mi\"\xa8\x01\n" +
+	"\n" +
+	"SpeechMark\x12\x1f\n" +
+	"\vtime_millis\x18\x01 \x01(\x03R\n" +
+	"timeMillis\x12'\n" +
+	"\x0fduration_millis\x18\x02 \x01(\x03R\x0edurationMillis\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x12\x14\n" +
+	"\x05start\x18\x05 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x06 \x01(\x05R\x03end\"\xb8\x01\n" +
+	"\x0fSynthesizeReply\x12\x14\n" +
+	"\x05audio\x18\x01 \x01(\fR\x05audio\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\x125\n" +
+	"\fspeech_marks\x18\x04 \x03(\v2\x12.tts.v1.SpeechMarkR\vspeechMarks\x12%\n" +
+	"\x0eskipped_stages\x18\x05 \x03(\tR\rskippedStages\"m\n" +
+	"\x15SynthesizeStreamReply\x12\x16\n" +
+	"\x05chunk\x18\x01 \x01(\fH\x00R\x05chunk\x121\n" +
+	"\x06result\x18\x02 \x01(\v2\x17.tts.v1.SynthesizeReplyH\x00R\x06resultB\t\n" +
+	"\apayload2\x93\x01\n" +
+	"\x03TTS\x12>\n" +
+	"\n" +
+	"Synthesize\x12\x17.tts.v1.SynthesizeInput\x1a\x17.tts.v1.SynthesizeReply\x12L\n" +
+	"\x10SynthesizeStream\x12\x17.tts.v1.SynthesizeInput\x1a\x1d.tts.v1.SynthesizeStreamReply0\x01B\rZ\vgen/tts;ttsb\x06proto3"
+
+var (
+	file_protos_tts_proto_rawDescOnce sync.Once
+	file_protos_tts_proto_rawDescData []byte
+)
+
+func file_protos_tts_proto_rawDescGZIP() []byte {
+	file_protos_tts_proto_rawDescOnce.Do(func() {
+		file_protos_tts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_protos_tts_proto_rawDesc), len(file_protos_tts_proto_rawDesc)))
+	})
+	return file_protos_tts_proto_rawDescData
+}
+
+var file_protos_tts_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_protos_tts_proto_goTypes = []any{
+	(*SynthesizeInput)(nil),       // 0: tts.v1.SynthesizeInput
+	(*LexiconEntry)(nil),          // 1: tts.v1.LexiconEntry
+	(*SpeechMark)(nil),            // 2: tts.v1.SpeechMark
+	(*SynthesizeReply)(nil),       // 3: tts.v1.SynthesizeReply
+	(*SynthesizeStreamReply)(nil), // 4: tts.v1.SynthesizeStreamReply
+}
+var file_protos_tts_proto_depIdxs = []int32{
+	1, // 0: tts.v1.SynthesizeInput.lexicon:type_name -> tts.v1.LexiconEntry
+	2, // 1: tts.v1.SynthesizeReply.speech_marks:type_name -> tts.v1.SpeechMark
+	3, // 2: tts.v1.SynthesizeStreamReply.result:type_name -> tts.v1.SynthesizeReply
+	0, // 3: tts.v1.TTS.Synthesize:input_type -> tts.v1.SynthesizeInput
+	0, // 4: tts.v1.TTS.SynthesizeStream:input_type -> tts.v1.SynthesizeInput
+	3, // 5: tts.v1.TTS.Synthesize:output_type -> tts.v1.SynthesizeReply
+	4, // 6: tts.v1.TTS.SynthesizeStream:output_type -> tts.v1.SynthesizeStreamReply
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_protos_tts_proto_init() }
+func file_protos_tts_proto_init() {
+	if File_protos_tts_proto != nil {
+		return
+	}
+	file_protos_tts_proto_msgTypes[0].OneofWrappers = []any{}
+	file_protos_tts_proto_msgTypes[4].OneofWrappers = []any{
+		(*SynthesizeStreamReply_Chunk)(nil),
+		(*SynthesizeStreamReply_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protos_tts_proto_rawDesc), len(file_protos_tts_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_protos_tts_proto_goTypes,
+		DependencyIndexes: file_protos_tts_proto_depIdxs,
+		MessageInfos:      file_protos_tts_proto_msgTypes,
+	}.Build()
+	File_protos_tts_proto = out.File
+	file_protos_tts_proto_goTypes = nil
+	file_protos_tts_proto_depIdxs = nil
+}
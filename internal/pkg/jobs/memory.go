@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// MemoryStore keeps jobs in memory until TTL expires
+type MemoryStore struct {
+	ttl     time.Duration
+	lock    sync.Mutex
+	data    map[string]*item
+	closeCh chan struct{}
+	once    sync.Once
+}
+
+type item struct {
+	job     *api.Job
+	expires time.Time
+}
+
+// NewMemoryStore creates in memory job store
+func NewMemoryStore(ttl time.Duration) (*MemoryStore, error) {
+	if ttl <= 0 {
+		return nil, errors.New("no ttl")
+	}
+	res := &MemoryStore{ttl: ttl, data: make(map[string]*item), closeCh: make(chan struct{})}
+	go res.cleanLoop(getCleanDuration(ttl))
+	goapp.Log.Info().Str("ttl", ttl.String()).Msg("Memory job store initialized")
+	return res, nil
+}
+
+// Save stores the job copy
+func (s *MemoryStore) Save(ctx context.Context, job *api.Job) error {
+	j := *job
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.data[job.ID] = &item{job: &j, expires: job.Updated.Add(s.ttl)}
+	return nil
+}
+
+// Get returns the job copy or utils.ErrNoRecord
+func (s *MemoryStore) Get(ctx context.Context, ID string) (*api.Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	it, ok := s.data[ID]
+	if !ok || time.Now().After(it.expires) {
+		return nil, utils.ErrNoRecord
+	}
+	j := *it.job
+	return &j, nil
+}
+
+// Close stops the cleaning loop
+func (s *MemoryStore) Close() {
+	s.once.Do(func() { close(s.closeCh) })
+}
+
+func (s *MemoryStore) cleanLoop(every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case now := <-ticker.C:
+			s.clean(now)
+		}
+	}
+}
+
+func (s *MemoryStore) clean(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for k, it := range s.data {
+		if now.After(it.expires) {
+			delete(s.data, k)
+		}
+	}
+}
+
+func getCleanDuration(ttl time.Duration) time.Duration {
+	res := ttl / 10
+	if res < time.Second {
+		return time.Second
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMemoryStore(t *testing.T) {
+	s, err := NewMemoryStore(time.Minute)
+	require.Nil(t, err)
+	defer s.Close()
+	assert.NotNil(t, s)
+}
+
+func TestNewMemoryStore_Fail(t *testing.T) {
+	_, err := NewMemoryStore(0)
+	assert.NotNil(t, err)
+}
+
+func TestMemoryStore_SaveGet(t *testing.T) {
+	s, _ := NewMemoryStore(time.Minute)
+	defer s.Close()
+	err := s.Save(context.TODO(), &api.Job{ID: "1", Status: api.JobQueued, Updated: time.Now()})
+	require.Nil(t, err)
+	j, err := s.Get(context.TODO(), "1")
+	require.Nil(t, err)
+	assert.Equal(t, api.JobQueued, j.Status)
+	j.Status = api.JobDone
+	j, _ = s.Get(context.TODO(), "1")
+	assert.Equal(t, api.JobQueued, j.Status)
+}
+
+func TestMemoryStore_GetMissing(t *testing.T) {
+	s, _ := NewMemoryStore(time.Minute)
+	defer s.Close()
+	_, err := s.Get(context.TODO(), "1")
+	assert.Equal(t, utils.ErrNoRecord, err)
+}
+
+func TestMemoryStore_Expired(t *testing.T) {
+	s, _ := NewMemoryStore(time.Minute)
+	defer s.Close()
+	_ = s.Save(context.TODO(), &api.Job{ID: "1", Updated: time.Now().Add(-2 * time.Minute)})
+	_, err := s.Get(context.TODO(), "1")
+	assert.Equal(t, utils.ErrNoRecord, err)
+	s.clean(time.Now())
+	assert.Empty(t, s.data)
+}
+
+func TestGetCleanDuration(t *testing.T) {
+	assert.Equal(t, time.Second, getCleanDuration(time.Second))
+	assert.Equal(t, time.Minute, getCleanDuration(10*time.Minute))
+}
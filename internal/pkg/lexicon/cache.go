@@ -0,0 +1,166 @@
+package lexicon
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+)
+
+// Store provides the stored lexicons
+type Store interface {
+	// Version changes on every change of the lexicon's entries, 0 if there is no such lexicon
+	Version(ctx context.Context, name string) (int64, error)
+	Entries(ctx context.Context, name string) ([]*api.LexiconEntryRecord, error)
+}
+
+// Cache keeps the stored lexicons in memory.
+// The version of a lexicon is checked after checkInterval, the entries are reloaded only if it has changed.
+// The store is called outside the lock, the concurrent loads of the same lexicon are merged.
+// Unknown lexicons are not kept, the least recently used lexicon is dropped if there are more than maxItems
+type Cache struct {
+	store         Store
+	checkInterval time.Duration
+	maxItems      int
+	now           func() time.Time
+	group         singleflight.Group
+
+	lock  sync.Mutex
+	items map[string]*cacheItem
+}
+
+type cacheItem struct {
+	lex     *Lexicon
+	version int64
+	checked time.Time
+	used    time.Time
+}
+
+// NewCache creates the stored lexicons cache
+func NewCache(store Store, checkInterval time.Duration, maxItems int) (*Cache, error) {
+	if store == nil {
+		return nil, fmt.Errorf("no lexicon store")
+	}
+	if checkInterval <= 0 {
+		return nil, fmt.Errorf("wrong check interval %v", checkInterval)
+	}
+	if maxItems <= 0 {
+		return nil, fmt.Errorf("wrong max items %d", maxItems)
+	}
+	return &Cache{store: store, checkInterval: checkInterval, maxItems: maxItems, now: time.Now,
+		items: map[string]*cacheItem{}}, nil
+}
+
+// Get returns the lexicons by names in the same order, an unknown lexicon is empty
+func (c *Cache) Get(ctx context.Context, names []string) (List, error) {
+	res := make(List, 0, len(names))
+	for _, n := range names {
+		lex, err := c.get(ctx, n)
+		if err != nil {
+			return nil, fmt.Errorf("can't load lexicon '%s': %w", n, err)
+		}
+		res = append(res, lex)
+	}
+	return res, nil
+}
+
+func (c *Cache) get(ctx context.Context, name string) (*Lexicon, error) {
+	if lex := c.fresh(name); lex != nil {
+		return lex, nil
+	}
+	// the load is shared by the waiting requests, so it must not fail if the first request is cancelled
+	res, err, _ := c.group.Do(name, func() (interface{}, error) {
+		return c.load(context.WithoutCancel(ctx), name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Lexicon), nil
+}
+
+// fresh returns the cached lexicon if it does not need the version check
+func (c *Cache) fresh(name string) *Lexicon {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item := c.items[name]
+	if item == nil {
+		return nil
+	}
+	now := c.now()
+	item.used = now
+	if now.Sub(item.checked) >= c.checkInterval {
+		return nil
+	}
+	return item.lex
+}
+
+func (c *Cache) load(ctx context.Context, name string) (*Lexicon, error) {
+	now := c.now()
+	version, err := c.store.Version(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		c.remove(name)
+		return newLexicon(0), nil
+	}
+	if lex := c.touch(name, version, now); lex != nil {
+		return lex, nil
+	}
+	entries, err := c.store.Entries(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	lex := newLexicon(len(entries))
+	for _, e := range entries {
+		// entries are validated on save, skip the old ones if the rules have changed
+		if err := lex.add(&e.LexiconEntry); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("lexicon", name).Str("id", e.ID).Msg("skip lexicon entry")
+		}
+	}
+	log.Ctx(ctx).Info().Str("lexicon", name).Int64("version", version).Int("entries", len(entries)).Msg("lexicon loaded")
+	c.put(name, &cacheItem{lex: lex, version: version, checked: now, used: now})
+	return lex, nil
+}
+
+// touch marks the cached lexicon as checked if its version has not changed
+func (c *Cache) touch(name string, version int64, now time.Time) *Lexicon {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item := c.items[name]
+	if item == nil || item.version != version {
+		return nil
+	}
+	item.checked = now
+	return item.lex
+}
+
+func (c *Cache) put(name string, item *cacheItem) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.items[name]; !ok && len(c.items) >= c.maxItems {
+		old := ""
+		for n, it := range c.items {
+			if old == "" || it.used.Before(c.items[old].used) {
+				old = n
+			}
+		}
+		delete(c.items, old)
+	}
+	c.items[name] = item
+}
+
+func (c *Cache) remove(name string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.items, name)
+}
@@ -0,0 +1,185 @@
+package lexicon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+)
+
+type mockStore struct{ mock.Mock }
+
+func (m *mockStore) Version(ctx context.Context, name string) (int64, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockStore) Entries(ctx context.Context, name string) ([]*api.LexiconEntryRecord, error) {
+	args := m.Called(ctx, name)
+	return mockEntries(args.Get(0)), args.Error(1)
+}
+
+func mockEntries(a interface{}) []*api.LexiconEntryRecord {
+	if a == nil {
+		return nil
+	}
+	return a.([]*api.LexiconEntryRecord)
+}
+
+func initCacheTest(t *testing.T) (*Cache, *mockStore, *time.Time) {
+	t.Helper()
+	st := &mockStore{}
+	c, err := NewCache(st, time.Minute, 2)
+	require.Nil(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, st, &now
+}
+
+func newRecord(grapheme, accented string) *api.LexiconEntryRecord {
+	return &api.LexiconEntryRecord{ID: grapheme, LexiconEntry: api.LexiconEntry{Grapheme: grapheme, Accented: accented}}
+}
+
+func TestNewCache(t *testing.T) {
+	c, err := NewCache(&mockStore{}, time.Second, 10)
+	assert.Nil(t, err)
+	assert.NotNil(t, c)
+	_, err = NewCache(nil, time.Second, 10)
+	assert.NotNil(t, err)
+	_, err = NewCache(&mockStore{}, 0, 10)
+	assert.NotNil(t, err)
+	_, err = NewCache(&mockStore{}, time.Second, 0)
+	assert.NotNil(t, err)
+}
+
+func TestCache_Get(t *testing.T) {
+	c, st, _ := initCacheTest(t)
+	st.On("Version", mock.Anything, "l1").Return(int64(1), nil)
+	st.On("Version", mock.Anything, "l2").Return(int64(0), nil)
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{newRecord("Tesla", "T{e/}sla"),
+		newRecord("kasa", "kasa")}, nil)
+
+	res, err := c.Get(context.TODO(), []string{"l2", "l1"})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(res))
+	assert.Nil(t, res[0].Find("Tesla", "", ""))
+	assert.Equal(t, 202, res.Find("Tesla", "", "").Accent)
+	assert.Nil(t, res.Find("kasa", "", ""))
+	st.AssertNotCalled(t, "Entries", mock.Anything, "l2")
+}
+
+func TestCache_Get_NotKeepsUnknown(t *testing.T) {
+	c, st, _ := initCacheTest(t)
+	st.On("Version", mock.Anything, "l1").Return(int64(0), nil)
+
+	for i := 0; i < 2; i++ {
+		res, err := c.Get(context.TODO(), []string{"l1"})
+		require.Nil(t, err)
+		assert.Nil(t, res.Find("Tesla", "", ""))
+	}
+	st.AssertNumberOfCalls(t, "Version", 2)
+	assert.Equal(t, 0, len(c.items))
+}
+
+func TestCache_Get_Evicts(t *testing.T) {
+	c, st, now := initCacheTest(t)
+	for _, n := range []string{"l1", "l2", "l3"} {
+		st.On("Version", mock.Anything, n).Return(int64(1), nil)
+		st.On("Entries", mock.Anything, n).Return([]*api.LexiconEntryRecord{newRecord("Tesla", "T{e/}sla")}, nil)
+	}
+
+	for _, n := range []string{"l1", "l2", "l1", "l3"} {
+		*now = now.Add(time.Second)
+		_, err := c.Get(context.TODO(), []string{n})
+		require.Nil(t, err)
+	}
+	assert.Equal(t, 2, len(c.items))
+	assert.NotNil(t, c.items["l1"])
+	assert.NotNil(t, c.items["l3"])
+	st.AssertNumberOfCalls(t, "Entries", 3)
+}
+
+func TestCache_Get_MergesLoads(t *testing.T) {
+	c, st, _ := initCacheTest(t)
+	started, release := make(chan bool), make(chan bool)
+	st.On("Version", mock.Anything, "l1").Run(func(mock.Arguments) {
+		started <- true
+		<-release
+	}).Return(int64(1), nil).Once()
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{newRecord("Tesla", "T{e/}sla")}, nil).Once()
+	st.On("Version", mock.Anything, "l2").Return(int64(0), nil)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.Get(ctx, []string{"l1"})
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := c.Get(context.TODO(), []string{"l1"})
+		errs <- err
+	}()
+	// other lexicons are not blocked by the load
+	_, err := c.Get(context.TODO(), []string{"l2"})
+	require.Nil(t, err)
+	cancel()
+	time.Sleep(10 * time.Millisecond) // let the second request join the load
+	close(release)
+	assert.Nil(t, <-errs)
+	assert.Nil(t, <-errs)
+	st.AssertNumberOfCalls(t, "Entries", 1)
+}
+
+func TestCache_Get_Cached(t *testing.T) {
+	c, st, now := initCacheTest(t)
+	st.On("Version", mock.Anything, "l1").Return(int64(1), nil)
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{newRecord("Tesla", "T{e/}sla")}, nil)
+
+	_, err := c.Get(context.TODO(), []string{"l1"})
+	require.Nil(t, err)
+	*now = now.Add(time.Second)
+	_, err = c.Get(context.TODO(), []string{"l1"})
+	require.Nil(t, err)
+	st.AssertNumberOfCalls(t, "Version", 1)
+	*now = now.Add(time.Minute)
+	res, err := c.Get(context.TODO(), []string{"l1"})
+	require.Nil(t, err)
+	assert.Equal(t, 202, res.Find("Tesla", "", "").Accent)
+	st.AssertNumberOfCalls(t, "Version", 2)
+	st.AssertNumberOfCalls(t, "Entries", 1)
+}
+
+func TestCache_Get_Reloads(t *testing.T) {
+	c, st, now := initCacheTest(t)
+	st.On("Version", mock.Anything, "l1").Return(int64(1), nil).Once()
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{newRecord("Tesla", "T{e/}sla")}, nil).Once()
+	_, err := c.Get(context.TODO(), []string{"l1"})
+	require.Nil(t, err)
+
+	st.On("Version", mock.Anything, "l1").Return(int64(2), nil).Once()
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{newRecord("Tesla", "Tesl{a/}")}, nil).Once()
+	*now = now.Add(time.Minute)
+	res, err := c.Get(context.TODO(), []string{"l1"})
+	require.Nil(t, err)
+	assert.Equal(t, 205, res.Find("Tesla", "", "").Accent)
+	st.AssertNumberOfCalls(t, "Entries", 2)
+}
+
+func TestCache_Get_Fail(t *testing.T) {
+	c, st, _ := initCacheTest(t)
+	st.On("Version", mock.Anything, "l1").Return(int64(0), errors.New("olia"))
+	_, err := c.Get(context.TODO(), []string{"l1"})
+	assert.NotNil(t, err)
+
+	st.On("Version", mock.Anything, "l2").Return(int64(1), nil)
+	st.On("Entries", mock.Anything, "l2").Return(nil, errors.New("olia"))
+	_, err = c.Get(context.TODO(), []string{"l2"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,153 @@
+package lexicon
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/airenas/tts-line/internal/pkg/accent"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/transcription"
+)
+
+// Entry is a parsed pronunciation of the word
+type Entry struct {
+	Lemma         string
+	Mi            string              // prefix of the word's morphological info
+	Accent        int                 // as ProcessedWord.UserAccent
+	Syllables     string              // lowercased, separated by '-'
+	Transcription *transcription.Data // nil if not provided
+}
+
+// Lexicon finds the user's pronunciation of the word
+type Lexicon struct {
+	entries map[string][]*Entry
+}
+
+// New parses the entries, fails on the first wrong one
+func New(entries []*api.LexiconEntry) (*Lexicon, error) {
+	res := newLexicon(len(entries))
+	for i, e := range entries {
+		if err := res.add(e); err != nil {
+			return nil, fmt.Errorf("wrong lexicon entry %d: %w", i, err)
+		}
+	}
+	return res, nil
+}
+
+func newLexicon(size int) *Lexicon {
+	return &Lexicon{entries: make(map[string][]*Entry, size)}
+}
+
+func (l *Lexicon) add(e *api.LexiconEntry) error {
+	pe, err := Parse(e)
+	if err != nil {
+		return err
+	}
+	k := strings.ToLower(strings.TrimSpace(e.Grapheme))
+	l.entries[k] = append(l.entries[k], pe)
+	return nil
+}
+
+// Find returns the entry of the word or nil.
+// The entry with the same lemma is preferred, then the one with the morphological info filter
+func (l *Lexicon) Find(word, lemma, mi string) *Entry {
+	var res *Entry
+	best := -1
+	for _, e := range l.entries[strings.ToLower(word)] {
+		if e.Lemma != "" && !strings.EqualFold(e.Lemma, lemma) {
+			continue
+		}
+		if e.Mi != "" && !strings.HasPrefix(mi, e.Mi) {
+			continue
+		}
+		if sc := e.specificity(); sc > best {
+			res, best = e, sc
+		}
+	}
+	return res
+}
+
+func (e *Entry) specificity() int {
+	res := 0
+	if e.Lemma != "" {
+		res += 2
+	}
+	if e.Mi != "" {
+		res++
+	}
+	return res
+}
+
+// List is a list of lexicons, the first one is preferred
+type List []*Lexicon
+
+// Find returns the entry of the word from the first lexicon having it, or nil
+func (l List) Find(word, lemma, mi string) *Entry {
+	for _, lex := range l {
+		if res := lex.Find(word, lemma, mi); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// Parse validates the entry
+func Parse(e *api.LexiconEntry) (*Entry, error) {
+	if e == nil {
+		return nil, fmt.Errorf("no entry")
+	}
+	grapheme := strings.TrimSpace(e.Grapheme)
+	if !isWord(grapheme) {
+		return nil, fmt.Errorf("wrong grapheme '%s'", e.Grapheme)
+	}
+	if e.Accented == "" && e.Syllables == "" && e.Transcription == "" {
+		return nil, fmt.Errorf("no accented, syllables or transcription for '%s'", grapheme)
+	}
+	res := &Entry{Lemma: strings.TrimSpace(e.Lemma), Mi: strings.TrimSpace(e.Mi)}
+	if strings.ContainsFunc(res.Mi, unicode.IsSpace) {
+		return nil, fmt.Errorf("wrong mi '%s' for '%s'", e.Mi, grapheme)
+	}
+	if e.Accented != "" {
+		w, acc, err := accent.FromAccentString(e.Accented)
+		if err != nil {
+			return nil, err
+		}
+		if acc == 0 || !strings.EqualFold(w, grapheme) || !accent.IsWordOrWithAccent(e.Accented) {
+			return nil, fmt.Errorf("wrong accented '%s' for '%s'", e.Accented, grapheme)
+		}
+		res.Accent = acc
+	}
+	if e.Syllables != "" {
+		if !okSyllables(e.Syllables) || !strings.EqualFold(strings.ReplaceAll(e.Syllables, "-", ""), grapheme) {
+			return nil, fmt.Errorf("wrong syllables '%s' for '%s'", e.Syllables, grapheme)
+		}
+		res.Syllables = strings.ToLower(e.Syllables)
+	}
+	if e.Transcription != "" {
+		if !okTranscription(e.Transcription) {
+			return nil, fmt.Errorf("wrong transcription '%s' for '%s'", e.Transcription, grapheme)
+		}
+		res.Transcription = transcription.Parse(e.Transcription)
+	}
+	return res, nil
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func okSyllables(s string) bool {
+	return !strings.HasPrefix(s, "-") && !strings.HasSuffix(s, "-") && !strings.Contains(s, "--") &&
+		isWord(strings.ReplaceAll(s, "-", ""))
+}
+
+// okTranscription checks the transcriber's user format: letters, syllables and accents 3, 4 or 9
+func okTranscription(s string) bool {
+	return okSyllables(transcription.TrimAccent(s))
+}
@@ -0,0 +1,84 @@
+package lexicon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/transcription"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      *api.LexiconEntry
+		want    *Entry
+		wantErr bool
+	}{
+		{name: "accented", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla"}, want: &Entry{Accent: 202}},
+		{name: "lemma", in: &api.LexiconEntry{Grapheme: " teslos ", Accented: "t{e/}slos", Lemma: " Tesla "},
+			want: &Entry{Accent: 202, Lemma: "Tesla"}},
+		{name: "syllables", in: &api.LexiconEntry{Grapheme: "Tesla", Syllables: "Tes-la"}, want: &Entry{Syllables: "tes-la"}},
+		{name: "transcription", in: &api.LexiconEntry{Grapheme: "Microsoft", Transcription: "mai4-kro-sof-tas"},
+			want: &Entry{Transcription: &transcription.Data{Word: "maikrosoftas", Sylls: "mai-kro-sof-tas", Transcription: "mai4krosoftas"}}},
+		{name: "nil", in: nil, wantErr: true},
+		{name: "no grapheme", in: &api.LexiconEntry{Accented: "T{e/}sla"}, wantErr: true},
+		{name: "mi", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla", Mi: " Np "}, want: &Entry{Accent: 202, Mi: "Np"}},
+		{name: "wrong mi", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla", Mi: "N p"}, wantErr: true},
+		{name: "several words", in: &api.LexiconEntry{Grapheme: "Tesla S", Syllables: "tes-la"}, wantErr: true},
+		{name: "no pronunciation", in: &api.LexiconEntry{Grapheme: "Tesla"}, wantErr: true},
+		{name: "wrong accented", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}slos"}, wantErr: true},
+		{name: "no accent", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "Tesla"}, wantErr: true},
+		{name: "several accents", in: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sl{a/}"}, wantErr: true},
+		{name: "wrong syllables", in: &api.LexiconEntry{Grapheme: "Tesla", Syllables: "tes--la"}, wantErr: true},
+		{name: "wrong syllables letters", in: &api.LexiconEntry{Grapheme: "Tesla", Syllables: "tes-lo"}, wantErr: true},
+		{name: "wrong transcription", in: &api.LexiconEntry{Grapheme: "Tesla", Transcription: "tes-la5"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew_Fail(t *testing.T) {
+	_, err := New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}, {Grapheme: "Tesla"}})
+	assert.ErrorContains(t, err, "entry 1")
+}
+
+func TestFind(t *testing.T) {
+	l, err := New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"},
+		{Grapheme: "kasa", Accented: "k{a/}sa", Lemma: "kasa"}, {Grapheme: "kasa", Accented: "kas{a~}", Lemma: "kasti"}})
+	require.Nil(t, err)
+	assert.Equal(t, 202, l.Find("TESLA", "", "").Accent)
+	assert.Nil(t, l.Find("Teslos", "Tesla", ""))
+	assert.Equal(t, 202, l.Find("kasa", "kasa", "").Accent)
+	assert.Equal(t, 304, l.Find("kasa", "kasti", "").Accent)
+	assert.Nil(t, l.Find("kasa", "kasyti", ""))
+}
+
+func TestFind_Mi(t *testing.T) {
+	l, err := New([]*api.LexiconEntry{{Grapheme: "kasa", Accented: "kas{a~}"},
+		{Grapheme: "kasa", Accented: "k{a/}sa", Mi: "Nc"}, {Grapheme: "kasa", Accented: "kas{a/}", Lemma: "kasa", Mi: "Ncfsnn"}})
+	require.Nil(t, err)
+	assert.Equal(t, 304, l.Find("kasa", "kasti", "Vgma3s--n--ni-").Accent)
+	assert.Equal(t, 202, l.Find("kasa", "kasa", "Ncfsin-").Accent)
+	assert.Equal(t, 204, l.Find("kasa", "kasa", "Ncfsnn-").Accent)
+	assert.Equal(t, 202, l.Find("kasa", "kasos", "Ncfsnn-").Accent)
+}
+
+func TestList_Find(t *testing.T) {
+	l1, err := New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}})
+	require.Nil(t, err)
+	l2, err := New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "Tesl{a/}"}, {Grapheme: "kasa", Accented: "k{a/}sa"}})
+	require.Nil(t, err)
+	assert.Equal(t, 202, List{l1, l2}.Find("Tesla", "", "").Accent)
+	assert.Equal(t, 205, List{l2, l1}.Find("Tesla", "", "").Accent)
+	assert.Equal(t, 202, List{l1, l2}.Find("kasa", "", "").Accent)
+	assert.Nil(t, List{l1, l2}.Find("olia", "", ""))
+	assert.Nil(t, List{}.Find("olia", "", ""))
+}
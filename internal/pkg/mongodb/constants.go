@@ -1,8 +1,19 @@
 package mongodb
 
 const (
-	textTable = "text"
+	textTable     = "text"
+	jobsTable     = "jobs"
+	jobAudioTable = "jobAudio"
+
+	lexiconsTable       = "lexicons"
+	lexiconEntriesTable = "lexiconEntries"
 )
 
 var indexData = []IndexData{
-	newIndexData(textTable, []string{"id", "type"}, false)}
+	newIndexData(textTable, []string{"id", "type"}, false),
+	newIndexData(jobsTable, []string{"id"}, true),
+	newExpireIndexData(jobsTable, "expires"),
+	newIndexData(jobAudioTable, []string{"jobid", "n"}, true),
+	newExpireIndexData(jobAudioTable, "expires"),
+	newIndexData(lexiconsTable, []string{"name"}, true),
+	newIndexData(lexiconEntriesTable, []string{"lexicon", "id"}, true)}
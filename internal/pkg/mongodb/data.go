@@ -2,6 +2,8 @@ package mongodb
 
 import (
 	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
 )
 
 // TextRecord data in mongo db
@@ -12,3 +14,34 @@ type TextRecord struct {
 	Created time.Time `json:"created,omitempty"`
 	Tags    []string  `json:"tags,omitempty"`
 }
+
+// JobRecord keeps async synthesis job in mongo db, the result audio is kept in JobAudioRecord chunks
+type JobRecord struct {
+	ID          string    `json:"id"`
+	Job         *api.Job  `json:"job"`
+	Expires     time.Time `json:"expires"`
+	AudioChunks int       `json:"audioChunks,omitempty"`
+}
+
+// JobAudioRecord keeps a chunk of the job's result audio, the job may exceed the document size limit
+type JobAudioRecord struct {
+	JobID   string    `json:"jobID"`
+	N       int       `json:"n"`
+	Data    []byte    `json:"data"`
+	Expires time.Time `json:"expires"`
+}
+
+// LexiconRecord keeps the version of the stored lexicon, the version is increased on every change
+type LexiconRecord struct {
+	Name    string    `json:"name"`
+	Version int64     `json:"version"`
+	Updated time.Time `json:"updated"`
+}
+
+// LexiconEntryRecord keeps the entry of the stored lexicon in mongo db
+type LexiconEntryRecord struct {
+	ID      string            `json:"id"`
+	Lexicon string            `json:"lexicon"`
+	Entry   *api.LexiconEntry `json:"entry"`
+	Updated time.Time         `json:"updated"`
+}
@@ -0,0 +1,146 @@
+package mongodb
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobStore keeps async synthesis jobs in mongo DB
+type JobStore struct {
+	SessionProvider *SessionProvider
+	ttl             time.Duration
+}
+
+// NewJobStore creates JobStore instance
+func NewJobStore(sessionProvider *SessionProvider, ttl time.Duration) (*JobStore, error) {
+	if sessionProvider == nil {
+		return nil, errors.New("no session provider")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("no ttl")
+	}
+	return &JobStore{SessionProvider: sessionProvider, ttl: ttl}, nil
+}
+
+// audioChunkSize keeps the audio chunk below the mongo document size limit
+const audioChunkSize = 8 * 1024 * 1024
+
+// Save inserts or replaces the job, the result audio is saved in separate chunks
+func (js *JobStore) Save(ctx context.Context, job *api.Job) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second) // results may be big
+	defer cancel()
+
+	session, err := js.SessionProvider.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+	db := session.Client().Database(textTable)
+	rec := &JobRecord{ID: job.ID, Job: job, Expires: job.Updated.Add(js.ttl)}
+	if job.Result != nil && len(job.Result.Audio) > 0 {
+		chunks := splitAudio(job.Result.Audio, audioChunkSize)
+		if err := saveJobAudio(ctx, db, job.ID, chunks, rec.Expires); err != nil {
+			return err
+		}
+		j, res := *job, *job.Result
+		res.Audio = nil
+		j.Result = &res
+		rec.Job, rec.AudioChunks = &j, len(chunks)
+	}
+	_, err = db.Collection(jobsTable).ReplaceOne(ctx, bson.M{"id": sanitize(job.ID)}, rec, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.Wrap(err, "can't save job")
+	}
+	return nil
+}
+
+// Get loads the job by ID
+func (js *JobStore) Get(ctx context.Context, ID string) (*api.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	session, err := js.SessionProvider.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(context.Background())
+	db := session.Client().Database(textTable)
+	var res JobRecord
+	err = db.Collection(jobsTable).FindOne(ctx, bson.M{"id": sanitize(ID)}).Decode(&res)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ErrNoRecord
+		}
+		return nil, errors.Wrap(err, "can't get job")
+	}
+	// mongo removes expired documents only periodically
+	if res.Job == nil || time.Now().After(res.Expires) {
+		return nil, utils.ErrNoRecord
+	}
+	if res.AudioChunks > 0 && res.Job.Result != nil {
+		res.Job.Result.Audio, err = loadJobAudio(ctx, db, ID, res.AudioChunks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res.Job, nil
+}
+
+func saveJobAudio(ctx context.Context, db *mongo.Database, ID string, chunks [][]byte, expires time.Time) error {
+	c := db.Collection(jobAudioTable)
+	if _, err := c.DeleteMany(ctx, bson.M{"jobid": sanitize(ID)}); err != nil {
+		return errors.Wrap(err, "can't delete job audio")
+	}
+	docs := make([]interface{}, 0, len(chunks))
+	for i, ch := range chunks {
+		docs = append(docs, &JobAudioRecord{JobID: ID, N: i, Data: ch, Expires: expires})
+	}
+	if _, err := c.InsertMany(ctx, docs); err != nil {
+		return errors.Wrap(err, "can't save job audio")
+	}
+	return nil
+}
+
+func loadJobAudio(ctx context.Context, db *mongo.Database, ID string, count int) ([]byte, error) {
+	cursor, err := db.Collection(jobAudioTable).Find(ctx, bson.M{"jobid": sanitize(ID)},
+		options.Find().SetSort(bson.D{{Key: "n", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get job audio")
+	}
+	defer cursor.Close(ctx)
+	var chunks [][]byte
+	for cursor.Next(ctx) {
+		var rec JobAudioRecord
+		if err = cursor.Decode(&rec); err != nil {
+			return nil, errors.Wrap(err, "can't decode job audio")
+		}
+		chunks = append(chunks, rec.Data)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, errors.Wrap(err, "cursor error")
+	}
+	if len(chunks) != count {
+		return nil, errors.Errorf("wrong job audio chunks %d, expected %d", len(chunks), count)
+	}
+	return bytes.Join(chunks, nil), nil
+}
+
+func splitAudio(data []byte, size int) [][]byte {
+	res := make([][]byte, 0, (len(data)+size-1)/size)
+	for len(data) > size {
+		res = append(res, data[:size])
+		data = data[size:]
+	}
+	if len(data) > 0 {
+		res = append(res, data)
+	}
+	return res
+}
@@ -0,0 +1,30 @@
+package mongodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJobStore(t *testing.T) {
+	tpr, _ := NewSessionProvider("mongo")
+	pr, err := NewJobStore(tpr, time.Hour)
+	assert.NotNil(t, pr)
+	assert.Nil(t, err)
+}
+
+func TestNewJobStore_Fail(t *testing.T) {
+	tpr, _ := NewSessionProvider("mongo")
+	_, err := NewJobStore(nil, time.Hour)
+	assert.NotNil(t, err)
+	_, err = NewJobStore(tpr, 0)
+	assert.NotNil(t, err)
+}
+
+func TestSplitAudio(t *testing.T) {
+	assert.Equal(t, [][]byte{}, splitAudio(nil, 2))
+	assert.Equal(t, [][]byte{[]byte("ab")}, splitAudio([]byte("ab"), 2))
+	assert.Equal(t, [][]byte{[]byte("ab"), []byte("c")}, splitAudio([]byte("abc"), 2))
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, splitAudio([]byte("abc"), 1))
+}
@@ -0,0 +1,164 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LexiconStore keeps the stored lexicons in mongo DB
+type LexiconStore struct {
+	SessionProvider *SessionProvider
+}
+
+// NewLexiconStore creates LexiconStore instance
+func NewLexiconStore(sessionProvider *SessionProvider) (*LexiconStore, error) {
+	if sessionProvider == nil {
+		return nil, errors.New("no session provider")
+	}
+	return &LexiconStore{SessionProvider: sessionProvider}, nil
+}
+
+// Entries loads all entries of the lexicon
+func (ls *LexiconStore) Entries(ctx context.Context, name string) ([]*api.LexiconEntryRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	session, err := ls.SessionProvider.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(context.Background())
+	c := session.Client().Database(textTable).Collection(lexiconEntriesTable)
+	cursor, err := c.Find(ctx, bson.M{"lexicon": sanitize(name)}, options.Find().SetSort(bson.D{{Key: "id", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't get lexicon entries")
+	}
+	defer cursor.Close(ctx)
+	res := make([]*api.LexiconEntryRecord, 0)
+	for cursor.Next(ctx) {
+		var rec LexiconEntryRecord
+		if err = cursor.Decode(&rec); err != nil {
+			return nil, errors.Wrap(err, "can't decode lexicon entry")
+		}
+		res = append(res, fromLexiconEntryRecord(&rec))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, errors.Wrap(err, "cursor error")
+	}
+	return res, nil
+}
+
+// Add inserts a new entry to the lexicon
+func (ls *LexiconStore) Add(ctx context.Context, name string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	session, err := ls.SessionProvider.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(context.Background())
+	db := session.Client().Database(textTable)
+	rec := &LexiconEntryRecord{ID: ulid.Make().String(), Lexicon: sanitize(name), Entry: entry, Updated: time.Now()}
+	if _, err = db.Collection(lexiconEntriesTable).InsertOne(ctx, rec); err != nil {
+		return nil, errors.Wrap(err, "can't insert lexicon entry")
+	}
+	if err := increaseVersion(ctx, db, rec.Lexicon); err != nil {
+		return nil, err
+	}
+	return fromLexiconEntryRecord(rec), nil
+}
+
+// Update replaces the entry, returns utils.ErrNoRecord if there is no such entry
+func (ls *LexiconStore) Update(ctx context.Context, name, ID string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	session, err := ls.SessionProvider.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.EndSession(context.Background())
+	db := session.Client().Database(textTable)
+	rec := &LexiconEntryRecord{ID: sanitize(ID), Lexicon: sanitize(name), Entry: entry, Updated: time.Now()}
+	res, err := db.Collection(lexiconEntriesTable).ReplaceOne(ctx, bson.M{"lexicon": rec.Lexicon, "id": rec.ID}, rec)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't update lexicon entry")
+	}
+	if res.MatchedCount == 0 {
+		return nil, utils.ErrNoRecord
+	}
+	if err := increaseVersion(ctx, db, rec.Lexicon); err != nil {
+		return nil, err
+	}
+	return fromLexiconEntryRecord(rec), nil
+}
+
+// Delete removes the entry, returns utils.ErrNoRecord if there is no such entry
+func (ls *LexiconStore) Delete(ctx context.Context, name, ID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	session, err := ls.SessionProvider.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+	db := session.Client().Database(textTable)
+	res, err := db.Collection(lexiconEntriesTable).DeleteOne(ctx, bson.M{"lexicon": sanitize(name), "id": sanitize(ID)})
+	if err != nil {
+		return errors.Wrap(err, "can't delete lexicon entry")
+	}
+	if res.DeletedCount == 0 {
+		return utils.ErrNoRecord
+	}
+	return increaseVersion(ctx, db, sanitize(name))
+}
+
+// Version returns the version of the lexicon, 0 if the lexicon has never been changed
+func (ls *LexiconStore) Version(ctx context.Context, name string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	session, err := ls.SessionProvider.NewSession()
+	if err != nil {
+		return 0, err
+	}
+	defer session.EndSession(context.Background())
+	c := session.Client().Database(textTable).Collection(lexiconsTable)
+	var res LexiconRecord
+	err = c.FindOne(ctx, bson.M{"name": sanitize(name)}).Decode(&res)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "can't get lexicon")
+	}
+	return res.Version, nil
+}
+
+// increaseVersion marks the lexicon as changed, so the cached copies are reloaded
+func increaseVersion(ctx context.Context, db *mongo.Database, name string) error {
+	_, err := db.Collection(lexiconsTable).UpdateOne(ctx, bson.M{"name": name},
+		bson.M{"$inc": bson.M{"version": 1}, "$set": bson.M{"updated": time.Now()}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return errors.Wrap(err, "can't update lexicon version")
+	}
+	return nil
+}
+
+func fromLexiconEntryRecord(rec *LexiconEntryRecord) *api.LexiconEntryRecord {
+	res := &api.LexiconEntryRecord{ID: rec.ID, Updated: rec.Updated}
+	if rec.Entry != nil {
+		res.LexiconEntry = *rec.Entry
+	}
+	return res
+}
@@ -0,0 +1,31 @@
+package mongodb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+)
+
+func TestNewLexiconStore(t *testing.T) {
+	tpr, _ := NewSessionProvider("mongo")
+	pr, err := NewLexiconStore(tpr)
+	assert.NotNil(t, pr)
+	assert.Nil(t, err)
+}
+
+func TestNewLexiconStore_Fail(t *testing.T) {
+	_, err := NewLexiconStore(nil)
+	assert.NotNil(t, err)
+}
+
+func TestFromLexiconEntryRecord(t *testing.T) {
+	now := time.Now()
+	res := fromLexiconEntryRecord(&LexiconEntryRecord{ID: "1", Lexicon: "l", Updated: now,
+		Entry: &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla", Mi: "Np"}})
+	assert.Equal(t, &api.LexiconEntryRecord{ID: "1", Updated: now,
+		LexiconEntry: api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla", Mi: "Np"}}, res)
+	assert.Equal(t, &api.LexiconEntryRecord{ID: "1"}, fromLexiconEntryRecord(&LexiconEntryRecord{ID: "1"}))
+}
@@ -19,6 +19,8 @@ type IndexData struct {
 	Table  string
 	Fields []string
 	Unique bool
+	// Expire removes documents at the time kept in the indexed field
+	Expire bool
 }
 
 // NewIndexData creates index data
@@ -26,6 +28,11 @@ func newIndexData(table string, fields []string, unique bool) IndexData {
 	return IndexData{Table: table, Fields: fields, Unique: unique}
 }
 
+// newExpireIndexData creates TTL index data
+func newExpireIndexData(table string, field string) IndexData {
+	return IndexData{Table: table, Fields: []string{field}, Expire: true}
+}
+
 // SessionProvider connects and provides session for mongo DB
 type SessionProvider struct {
 	client *mongo.Client
@@ -105,22 +112,26 @@ func checkIndex(s mongo.Session, indexData IndexData, database string) error {
 	for _, f := range indexData.Fields {
 		keys = keys.Append(f, bsonx.Int32(int32(1)))
 	}
+	opts := options.Index().SetUnique(indexData.Unique).SetSparse(true)
+	if indexData.Expire {
+		opts = opts.SetExpireAfterSeconds(0)
+	}
 	index := mongo.IndexModel{
 		Keys:    keys,
-		Options: options.Index().SetUnique(indexData.Unique).SetSparse(true),
+		Options: opts,
 	}
 	_, err := c.Indexes().CreateOne(context.Background(), index)
 	return err
 }
 
 // Healthy checks if mongo DB is up
-func (sp *SessionProvider) Healthy() error {
+func (sp *SessionProvider) Healthy(ctx context.Context) error {
 	session, err := sp.NewSession()
 	if err != nil {
 		return err
 	}
-	defer session.EndSession(context.Background())
-	return session.Client().Ping(context.Background(), nil)
+	defer session.EndSession(ctx)
+	return session.Client().Ping(ctx, nil)
 }
 
 func mongoContext() (context.Context, context.CancelFunc) {
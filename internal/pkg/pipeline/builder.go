@@ -0,0 +1,246 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/processor"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	stagePartRunner     = "partRunner"
+	stageSSMLPartRunner = "ssmlPartRunner"
+)
+
+// Builder makes the workers from the pipeline spec
+type Builder struct {
+	registry *Registry
+	spec     *Spec
+	cfg      *viper.Viper
+}
+
+// NewBuilder creates the pipeline builder, cfg is the global config for the stage params
+func NewBuilder(registry *Registry, spec *Spec, cfg *viper.Viper) (*Builder, error) {
+	if registry == nil {
+		return nil, errors.New("no registry")
+	}
+	if spec == nil {
+		return nil, errors.New("no spec")
+	}
+	if cfg == nil {
+		return nil, errors.New("no config")
+	}
+	return &Builder{registry: registry, spec: spec, cfg: cfg}, nil
+}
+
+// Synthesize builds the worker for /synthesize
+func (b *Builder) Synthesize() (*synthesizer.MainWorker, error) {
+	return b.worker("synthesize", b.spec.Synthesize, true)
+}
+
+// Custom builds the worker for /synthesizeCustom, SSML is not supported
+func (b *Builder) Custom() (*synthesizer.MainWorker, error) {
+	return b.worker("custom", b.spec.Custom, false)
+}
+
+// Analyze builds the worker for /analyze
+func (b *Builder) Analyze() (*synthesizer.MainWorker, error) {
+	return b.worker("analyze", b.spec.Analyze, true)
+}
+
+func (b *Builder) worker(name string, ws *WorkerSpec, ssml bool) (*synthesizer.MainWorker, error) {
+	if ws == nil || len(ws.Text) == 0 {
+		return nil, errors.Errorf("%s: no text processors", name)
+	}
+	if !ssml && len(ws.SSML) > 0 {
+		return nil, errors.Errorf("%s: SSML processors are not supported", name)
+	}
+	res := &synthesizer.MainWorker{}
+	prs, err := b.processors(name+".text", ws.Text, false, pipelineType(name, false))
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		res.Add(pr)
+	}
+	prs, err = b.processors(name+".ssml", ws.SSML, true, pipelineType(name, true))
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		res.AddSSML(pr)
+	}
+	return res, nil
+}
+
+// pipelineType is the pipeline label of the stage metrics
+func pipelineType(worker string, ssml bool) string {
+	switch {
+	case worker == "custom":
+		return processor.PipelineCustom
+	case worker == "analyze":
+		return processor.PipelineAnalyze
+	case ssml:
+		return processor.PipelineSSML
+	}
+	return processor.PipelineText
+}
+
+func (b *Builder) processors(path string, stages []*Stage, ssml bool, pipeline string) ([]synthesizer.Processor, error) {
+	var res []synthesizer.Processor
+	for i, st := range stages {
+		stPath := fmt.Sprintf("%s[%d]", path, i)
+		if st == nil || st.Type == "" {
+			return nil, errors.Errorf("%s: no type", stPath)
+		}
+		stPath += "(" + st.Type + ")"
+		enabled, err := b.enabled(st)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stPath, err)
+		}
+		if !enabled {
+			goapp.Log.Info().Str("stage", stPath).Str("when", st.When).Msg("skip stage")
+			continue
+		}
+		pr, err := b.processor(stPath, st, ssml, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		pr = processor.NewMetricsWrap(pr, st.Type, pipeline)
+		if st.Optional {
+			pr = processor.NewOptional(pr, st.Type, st.Timeout)
+		}
+		res = append(res, pr)
+	}
+	return res, nil
+}
+
+func (b *Builder) processor(path string, st *Stage, ssml bool, pipeline string) (synthesizer.Processor, error) {
+	if err := checkTimeout(st); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if st.Type != stagePartRunner && len(st.Parts) > 0 {
+		return nil, errors.Errorf("%s: parts are allowed for %s only", path, stagePartRunner)
+	}
+	if st.Type != stageSSMLPartRunner && len(st.Processors) > 0 {
+		return nil, errors.Errorf("%s: processors are allowed for %s only", path, stageSSMLPartRunner)
+	}
+	params, err := newParams(st.Params, b.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch st.Type {
+	case stagePartRunner:
+		return b.partRunner(path, st, params, pipeline)
+	case stageSSMLPartRunner:
+		if !ssml {
+			return nil, errors.Errorf("%s: allowed in SSML pipeline only", path)
+		}
+		if len(st.Processors) == 0 {
+			return nil, errors.Errorf("%s: no processors", path)
+		}
+		prs, err := b.processors(path, st.Processors, false, pipeline)
+		if err != nil {
+			return nil, err
+		}
+		return processor.NewSSMLPartRunner(prs), nil
+	}
+	f, ok := b.registry.processors[st.Type]
+	if !ok {
+		if _, ok := b.registry.partProcessors[st.Type]; ok {
+			return nil, errors.Errorf("%s: part processor is allowed in %s only", path, stagePartRunner)
+		}
+		return nil, errors.Errorf("%s: unknown processor", path)
+	}
+	res, err := f(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return res, nil
+}
+
+func (b *Builder) partRunner(path string, st *Stage, params *Params, pipeline string) (synthesizer.Processor, error) {
+	stages := st.Parts
+	if len(stages) == 0 {
+		stages = b.spec.Parts
+	}
+	if len(stages) == 0 {
+		return nil, errors.Errorf("%s: no part processors", path)
+	}
+	res := synthesizer.NewPartRunner(params.GetInt("workers", "partRunner.workers"))
+	for i, ps := range stages {
+		psPath := fmt.Sprintf("%s.parts[%d]", path, i)
+		if ps == nil || ps.Type == "" {
+			return nil, errors.Errorf("%s: no type", psPath)
+		}
+		psPath += "(" + ps.Type + ")"
+		if len(ps.Parts) > 0 || len(ps.Processors) > 0 {
+			return nil, errors.Errorf("%s: nested processors are not allowed", psPath)
+		}
+		if err := checkTimeout(ps); err != nil {
+			return nil, fmt.Errorf("%s: %w", psPath, err)
+		}
+		enabled, err := b.enabled(ps)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", psPath, err)
+		}
+		if !enabled {
+			goapp.Log.Info().Str("stage", psPath).Str("when", ps.When).Msg("skip stage")
+			continue
+		}
+		f, ok := b.registry.partProcessors[ps.Type]
+		if !ok {
+			return nil, errors.Errorf("%s: unknown part processor", psPath)
+		}
+		pp, err := newParams(ps.Params, b.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", psPath, err)
+		}
+		pr, err := f(pp)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", psPath, err)
+		}
+		pr = processor.NewPartMetricsWrap(pr, ps.Type, pipeline)
+		if ps.Optional {
+			pr = processor.NewOptionalPart(pr, ps.Type, ps.Timeout)
+		}
+		res.Add(pr)
+	}
+	return res, nil
+}
+
+func checkTimeout(st *Stage) error {
+	if st.Timeout < 0 {
+		return errors.New("wrong timeout")
+	}
+	if st.Timeout > 0 && !st.Optional {
+		return errors.New("timeout is allowed for optional stages only")
+	}
+	return nil
+}
+
+// enabled checks the 'when' config key, a string value is true if not empty
+func (b *Builder) enabled(st *Stage) (bool, error) {
+	key, negate := strings.CutPrefix(strings.TrimSpace(st.When), "!")
+	if key == "" {
+		if negate {
+			return false, errors.New("no 'when' key")
+		}
+		return true, nil
+	}
+	var res bool
+	switch v := b.cfg.Get(key).(type) {
+	case nil:
+	case bool:
+		res = v
+	case string:
+		res = v != "" && v != "false"
+	default:
+		return false, errors.Errorf("wrong 'when' value type %T", v)
+	}
+	return res != negate, nil
+}
@@ -0,0 +1,257 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/airenas/tts-line/internal/pkg/mongodb"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAcrCfg = "acronyms:\n  url: http://server\n"
+const testCliticsCfg = "clitics:\n  url: http://server\n"
+const testAccenterCfg = "accenter:\n  url: http://server\n"
+const testTransCfg = "transcriber:\n  url: http://server\n"
+const testAMCfg = "acousticModel:\n  url: http://server\n"
+const testVocCfg = "vocoder:\n  url: http://server\n"
+const testCompCfg = "comparator:\n  url: http://server\n"
+const testTaggerCfg = "tagger:\n  url: http://server\n"
+const testWordTaggerCfg = "wordTagger:\n  url: http://server\n"
+const testURLReaderCfg = "urlReader:\n  url: http://server\n"
+const testValidatorCfg = "validator:\n  maxChars: 100\n"
+const testConvCfg = "audioConvert:\n  url: http://server\n"
+const testObsceneCfg = "obscene:\n  url: http://server\n"
+const testCleanCfg = "clean:\n  url: http://cl.su\n"
+const testTransliteratorCfg = "transliterator:\n  url: http://transl.su\n"
+const testNormalizeCfg = "normalize:\n  url: http://norm.su\n"
+const testNumberReplaceCfg = "numberReplace:\n  url: http://nr.su\n"
+const testSuffixLoaderCfg = "suffixLoader:\n  path: ./\n"
+
+var testAllCfg = testCompCfg +
+	testAccenterCfg + testTransCfg + testAMCfg + testVocCfg + testTaggerCfg + testWordTaggerCfg +
+	testValidatorCfg +
+	testConvCfg + testAcrCfg + testCliticsCfg + testObsceneCfg + testCleanCfg +
+	testNumberReplaceCfg + testSuffixLoaderCfg + testNormalizeCfg + testTransliteratorCfg + testURLReaderCfg
+
+func newTestBuilder(t *testing.T, cfg *viper.Viper) *Builder {
+	t.Helper()
+	spec, err := LoadSpec(cfg)
+	require.Nil(t, err)
+	res, err := NewBuilder(NewRegistry(&mongodb.SessionProvider{}), spec, cfg)
+	require.Nil(t, err)
+	return res
+}
+
+func TestNewBuilder(t *testing.T) {
+	cfg := test.NewConfig(t, "")
+	_, err := NewBuilder(NewRegistry(nil), &Spec{}, cfg)
+	assert.Nil(t, err)
+	_, err = NewBuilder(nil, &Spec{}, cfg)
+	assert.NotNil(t, err)
+	_, err = NewBuilder(NewRegistry(nil), nil, cfg)
+	assert.NotNil(t, err)
+	_, err = NewBuilder(NewRegistry(nil), &Spec{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestBuilder_Fail(t *testing.T) {
+	tests := []struct {
+		name    string
+		trimCfg string
+	}{
+		{name: "Obscene fail", trimCfg: testObsceneCfg},
+		{name: "AM fail", trimCfg: testAMCfg},
+		{name: "Acr fail", trimCfg: testAcrCfg},
+		{name: "Clitics fail", trimCfg: testCliticsCfg},
+		{name: "Trans fail", trimCfg: testTransCfg},
+		{name: "Voc fail", trimCfg: testVocCfg},
+		{name: "Validator fail", trimCfg: testValidatorCfg},
+		{name: "Converter fail", trimCfg: testConvCfg},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuilder(t, test.NewConfig(t, trim(testAllCfg, tt.trimCfg)))
+			_, err := b.Synthesize()
+			assert.NotNil(t, err)
+			_, err = b.Custom()
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestBuilder_CustomFail(t *testing.T) {
+	b := newTestBuilder(t, test.NewConfig(t, trim(testAllCfg, testCompCfg)))
+	_, err := b.Synthesize()
+	assert.Nil(t, err)
+	_, err = b.Custom()
+	assert.NotNil(t, err)
+}
+
+func TestBuilder_SynthesizeFail(t *testing.T) {
+	b := newTestBuilder(t, test.NewConfig(t, trim(testAllCfg, testCleanCfg)))
+	_, err := b.Synthesize()
+	assert.NotNil(t, err)
+	_, err = b.Custom()
+	assert.Nil(t, err)
+}
+
+func TestBuilder_Synthesize(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, testAllCfg)).Synthesize()
+	require.Nil(t, err)
+	assertInfo(t, mw.GetProcessorsInfo(), []string{"addMetrics",
+		"saver(original)",
+		"cleaner(HTTPBackoff(HTTPWrap(http://cl.su, tm: 10s)))",
+		"normalizer(HTTPBackoff(HTTPWrap(http://norm.su, tm: 10s)))",
+		"saver(cleaned)",
+		"numberReplace(HTTPBackoff(HTTPWrap(http://nr.su, tm: 20s)))",
+		"tagger(",
+		"urlReplacer(",
+		"transliterator(",
+		"saver(normalized)",
+		"partRunner(",
+		"obscene(", "acronyms(", "accentuator(", "clitics(", "transcriber(", "acousticModel(", "vocoder(",
+		"joinAudio(audioLoader(./))",
+		"audioConverter", "addMetrics"})
+	info := mw.GetSSMLProcessorsInfo()
+	assertInfo(t, info, []string{"addMetrics",
+		"SSMLValidator(100)",
+		"saver(originalSSML)",
+		"SSMLPartRunner",
+		"cleaner(HTTPBackoff(HTTPWrap(http://cl.su, tm: 10s)))",
+		"normalizer(HTTPBackoff(HTTPWrap(http://norm.su, tm: 10s)))",
+		"numberReplace(HTTPBackoff(HTTPWrap(http://nr.su, tm: 20s)))",
+		"SSMLTagger(", "joinSSMLAudio(audioLoader(./))",
+		"audioConverter", "addMetrics"})
+	assert.NotContains(t, info, "filer(")
+	assert.NotContains(t, info, "calcLoudness")
+}
+
+func TestBuilder_Synthesize_When(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, trim(testAllCfg, testAMCfg)+
+		"filer:\n  dir: /tmp\nloudness:\n  adjust: true\nacousticModel:\n  url: http://server\n  hasVocoder: true\n")).Synthesize()
+	require.Nil(t, err)
+	info := mw.GetProcessorsInfo()
+	assertInfo(t, info, []string{"calcLoudness", "joinAudio", "filer(/tmp)"})
+	assert.NotContains(t, info, "vocoder(")
+	assertInfo(t, mw.GetSSMLProcessorsInfo(), []string{"calcLoudnessSSML", "joinSSMLAudio", "filer(/tmp)"})
+}
+
+func TestBuilder_Custom(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, testAllCfg)).Custom()
+	require.Nil(t, err)
+	assertInfo(t, mw.GetProcessorsInfo(), []string{"addMetrics", "validator(100)", "loader", "comparator(",
+		"saver(user)", "taggerAccents(", "transliterator(", "partRunner(", "vocoder(", "joinAudio(", "audioConverter"})
+	assert.Equal(t, "", mw.GetSSMLProcessorsInfo())
+}
+
+func TestBuilder_Analyze(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, trim(trim(testAllCfg, testAMCfg), testVocCfg))).Analyze()
+	require.Nil(t, err)
+	info := mw.GetProcessorsInfo()
+	assertInfo(t, info, []string{"validator(100)", "cleaner(", "tagger(", "partRunner(", "transcriber("})
+	assert.NotContains(t, info, "saver(")
+	assert.NotContains(t, info, "acousticModel(")
+	assertInfo(t, mw.GetSSMLProcessorsInfo(), []string{"SSMLValidator(100)", "SSMLPartRunner", "SSMLTagger(", "transcriber("})
+}
+
+func TestBuilder_Config(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, testAllCfg+`
+pipeline:
+  parts:
+    - type: accentuator
+      params: {url: http://acc.su}
+  synthesize:
+    text:
+      - type: validator
+        params: {maxChars: 200}
+      - type: urlReplacer
+      - type: partRunner
+        params: {workers: 3}
+    ssml:
+      - type: ssmlValidator
+`)).Synthesize()
+	require.Nil(t, err)
+	assertInfo(t, mw.GetProcessorsInfo(), []string{"validator(200)", "urlReplacer(", "partRunner(3)", "accentuator(", "acc.su"})
+	assert.Equal(t, "SSMLValidator(100)", mw.GetSSMLProcessorsInfo())
+}
+
+func TestBuilder_SpecFail(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "No worker", spec: "  parts:\n    - type: accentuator\n"},
+		{name: "No type", spec: "  synthesize:\n    text:\n      - params: {maxChars: 10}\n"},
+		{name: "Unknown", spec: "  synthesize:\n    text:\n      - type: olia\n"},
+		{name: "Part in main", spec: "  synthesize:\n    text:\n      - type: accentuator\n"},
+		{name: "No parts", spec: "  synthesize:\n    text:\n      - type: partRunner\n"},
+		{name: "Unknown part", spec: "  synthesize:\n    text:\n      - type: partRunner\n        parts:\n          - type: olia\n"},
+		{name: "Main in parts", spec: "  synthesize:\n    text:\n      - type: partRunner\n        parts:\n          - type: cleaner\n"},
+		{name: "Parts not allowed", spec: "  synthesize:\n    text:\n      - type: cleaner\n        parts:\n          - type: accentuator\n"},
+		{name: "SSML runner in text", spec: "  synthesize:\n    text:\n      - type: ssmlPartRunner\n        processors:\n          - type: cleaner\n"},
+		{name: "SSML runner empty", spec: "  synthesize:\n    text:\n      - type: cleaner\n    ssml:\n      - type: ssmlPartRunner\n"},
+		{name: "Bad param", spec: "  synthesize:\n    text:\n      - type: addMetrics\n        params: {metric: olia, path: /s}\n"},
+		{name: "Bad when", spec: "  synthesize:\n    text:\n      - type: cleaner\n        when: '!'\n"},
+		{name: "Bad when value", spec: "  synthesize:\n    text:\n      - type: cleaner\n        when: validator.maxChars\n"},
+		{name: "Timeout not optional", spec: "  synthesize:\n    text:\n      - type: cleaner\n        timeout: 1s\n"},
+		{name: "Wrong timeout", spec: "  synthesize:\n    text:\n      - type: cleaner\n        optional: true\n        timeout: -1s\n"},
+		{name: "Part timeout not optional", spec: "  synthesize:\n    text:\n      - type: partRunner\n        parts:\n          - type: accentuator\n            timeout: 1s\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestBuilder(t, test.NewConfig(t, testAllCfg+"pipeline:\n"+tt.spec)).Synthesize()
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestBuilder_Optional(t *testing.T) {
+	mw, err := newTestBuilder(t, test.NewConfig(t, testAllCfg+"pipeline:\n  synthesize:\n    text:\n"+
+		"      - type: cleaner\n        optional: true\n        timeout: 1s\n"+
+		"      - type: partRunner\n        parts:\n          - type: obscene\n            optional: true\n")).Synthesize()
+	require.Nil(t, err)
+	assertInfo(t, mw.GetProcessorsInfo(), []string{"cleaner(", "partRunner(3)", "obscene("})
+}
+
+func TestBuilder_SpecFail_Path(t *testing.T) {
+	_, err := newTestBuilder(t, test.NewConfig(t, testAllCfg+
+		"pipeline:\n  synthesize:\n    text:\n      - type: cleaner\n      - type: olia\n")).Synthesize()
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "synthesize.text[1](olia)")
+}
+
+func TestBuilder_CustomSSMLFail(t *testing.T) {
+	_, err := newTestBuilder(t, test.NewConfig(t, testAllCfg+
+		"pipeline:\n  custom:\n    text:\n      - type: cleaner\n    ssml:\n      - type: cleaner\n")).Custom()
+	assert.NotNil(t, err)
+}
+
+func assertInfo(t *testing.T, info string, req []string) {
+	t.Helper()
+	infos := strings.Split(info, "\n")
+	pos := 0
+	for _, rs := range req {
+		was := false
+		for ; pos < len(infos); pos++ {
+			if strings.Contains(infos[pos], rs) {
+				was = true
+				break
+			}
+		}
+		require.True(t, was, "no `%s` in [%s]", rs, strings.Join(infos, ">"))
+	}
+}
+
+func trim(all, what string) string {
+	return strings.Replace(all, what, "", -1)
+}
+
+func TestPipelineType(t *testing.T) {
+	assert.Equal(t, "text", pipelineType("synthesize", false))
+	assert.Equal(t, "ssml", pipelineType("synthesize", true))
+	assert.Equal(t, "custom", pipelineType("custom", false))
+	assert.Equal(t, "analyze", pipelineType("analyze", true))
+}
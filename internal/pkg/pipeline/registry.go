@@ -0,0 +1,278 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/file"
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
+	"github.com/airenas/tts-line/internal/pkg/mongodb"
+	"github.com/airenas/tts-line/internal/pkg/processor"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+type (
+	// ProcessorFactory creates a processor from the stage params
+	ProcessorFactory func(*Params) (synthesizer.Processor, error)
+	// PartProcessorFactory creates a part processor from the stage params
+	PartProcessorFactory func(*Params) (synthesizer.PartProcessor, error)
+)
+
+// Params are the stage parameters, a missing parameter is taken from the global config
+type Params struct {
+	stage *viper.Viper
+	cfg   *viper.Viper
+}
+
+func newParams(stage map[string]interface{}, cfg *viper.Viper) (*Params, error) {
+	res := &Params{stage: viper.New(), cfg: cfg}
+	if err := res.stage.MergeConfigMap(stage); err != nil {
+		return nil, fmt.Errorf("read params: %w", err)
+	}
+	return res, nil
+}
+
+// GetString returns the stage param or the global config value
+func (p *Params) GetString(key, global string) string {
+	if p.stage.IsSet(key) {
+		return p.stage.GetString(key)
+	}
+	return p.cfg.GetString(global)
+}
+
+// GetInt returns the stage param or the global config value
+func (p *Params) GetInt(key, global string) int {
+	if p.stage.IsSet(key) {
+		return p.stage.GetInt(key)
+	}
+	return p.cfg.GetInt(global)
+}
+
+// GetBool returns the stage param or the global config value
+func (p *Params) GetBool(key, global string) bool {
+	if p.stage.IsSet(key) {
+		return p.stage.GetBool(key)
+	}
+	return p.cfg.GetBool(global)
+}
+
+// GetDuration returns the stage param or the global config value
+func (p *Params) GetDuration(key, global string) time.Duration {
+	if p.stage.IsSet(key) {
+		return p.stage.GetDuration(key)
+	}
+	return p.cfg.GetDuration(global)
+}
+
+// Sub returns the global config section overridden by the stage params, nil if both are empty
+func (p *Params) Sub(global string) *viper.Viper {
+	sub := p.cfg.Sub(global)
+	if sub == nil && len(p.stage.AllKeys()) == 0 {
+		return nil
+	}
+	res := viper.New()
+	if sub != nil {
+		_ = res.MergeConfigMap(sub.AllSettings())
+	}
+	_ = res.MergeConfigMap(p.stage.AllSettings())
+	return res
+}
+
+// Registry keeps the processor factories by name
+type Registry struct {
+	processors     map[string]ProcessorFactory
+	partProcessors map[string]PartProcessorFactory
+}
+
+// NewRegistry creates registry with all known processors
+func NewRegistry(sp *mongodb.SessionProvider) *Registry {
+	res := &Registry{processors: map[string]ProcessorFactory{}, partProcessors: map[string]PartProcessorFactory{}}
+	res.Add("addMetrics", newAddMetrics)
+	res.Add("validator", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewValidator(p.GetInt("maxChars", "validator.maxChars"))
+	})
+	res.Add("ssmlValidator", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewSSMLValidator(p.GetInt("maxChars", "validator.maxChars"))
+	})
+	res.Add("ssmlAudioLoader", func(p *Params) (synthesizer.Processor, error) {
+		path := p.GetString("path", "ssmlAudio.path")
+		if path == "" {
+			return processor.NewSSMLAudioLoader(nil), nil
+		}
+		l, err := file.NewLoader(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't init ssml audio Loader")
+		}
+		return processor.NewSSMLAudioLoader(l), nil
+	})
+	res.Add("saver", func(p *Params) (synthesizer.Processor, error) {
+		rt, err := parseRequestType(p.GetString("request", ""))
+		if err != nil {
+			return nil, err
+		}
+		ts, err := mongodb.NewTextSaver(sp)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't init text to DB saver")
+		}
+		return processor.NewSaver(ts, rt)
+	})
+	res.Add("loader", func(p *Params) (synthesizer.Processor, error) {
+		ts, err := mongodb.NewTextSaver(sp)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't init text to DB saver")
+		}
+		return processor.NewLoader(ts)
+	})
+	res.Add("comparator", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewComparator(p.GetString("url", "comparator.url"))
+	})
+	res.Add("cleaner", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewCleaner(p.GetString("url", "clean.url"))
+	})
+	res.Add("normalizer", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewNormalizer(p.GetString("url", "normalize.url"))
+	})
+	res.Add("numberReplace", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewNumberReplace(p.GetString("url", "numberReplace.url"))
+	})
+	res.Add("tagger", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewTagger(p.GetString("url", "tagger.url"))
+	})
+	res.Add("ssmlTagger", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewSSMLTagger(p.GetString("url", "tagger.url"))
+	})
+	res.Add("taggerAccents", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewTaggerAccents(p.GetString("url", "tagger.url"))
+	})
+	res.Add("urlReplacer", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewURLReplacer(p.GetString("url", "urlReader.url"), p.GetString("wordTaggerURL", "wordTagger.url"))
+	})
+	res.Add("transliterator", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewTransliterator(p.GetString("url", "transliterator.url"))
+	})
+	res.Add("lexicon", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewLexicon()
+	})
+	res.Add("ner", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewNER()
+	})
+	res.Add("readSymbols", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewReadSymbols()
+	})
+	res.Add("splitter", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewSplitter(p.GetInt("maxChars", "splitter.maxChars")), nil
+	})
+	res.Add("streamAudio", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewStreamAudio(p.GetBool("adjustLoudness", "loudness.adjust")), nil
+	})
+	res.Add("streamSSMLAudio", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewStreamSSMLAudio(p.GetBool("adjustLoudness", "loudness.adjust")), nil
+	})
+	res.Add("calcLoudness", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewCalcLoudness(p.GetInt("workers", "loudness.workers")), nil
+	})
+	res.Add("calcLoudnessSSML", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewCalcLoudnessSSML(p.GetInt("workers", "loudness.workers")), nil
+	})
+	res.Add("joinAudio", func(p *Params) (synthesizer.Processor, error) {
+		l, err := file.NewLoader(p.GetString("suffixPath", "suffixLoader.path"))
+		if err != nil {
+			return nil, errors.Wrap(err, "can't init suffix Loader")
+		}
+		return processor.NewJoinAudio(l), nil
+	})
+	res.Add("joinSSMLAudio", func(p *Params) (synthesizer.Processor, error) {
+		l, err := file.NewLoader(p.GetString("suffixPath", "suffixLoader.path"))
+		if err != nil {
+			return nil, errors.Wrap(err, "can't init suffix Loader")
+		}
+		return processor.NewJoinSSMLAudio(l), nil
+	})
+	res.Add("audioConverter", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewConverter(p.GetString("url", "audioConvert.url"))
+	})
+	res.Add("filer", func(p *Params) (synthesizer.Processor, error) {
+		return processor.NewFiler(p.GetString("dir", "filer.dir"))
+	})
+
+	res.AddPart("obscene", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewObsceneFilter(p.GetString("url", "obscene.url"))
+	})
+	res.AddPart("acronyms", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewAcronyms(p.GetString("url", "acronyms.url"))
+	})
+	var lexicons *lexicon.Cache // shared by all pipelines, so every lexicon is cached once
+	res.AddPart("lexicons", func(p *Params) (synthesizer.PartProcessor, error) {
+		if lexicons == nil {
+			ls, err := mongodb.NewLexiconStore(sp)
+			if err != nil {
+				return nil, errors.Wrap(err, "can't init lexicon store")
+			}
+			checkInterval := p.GetDuration("checkInterval", "lexicons.checkInterval")
+			if checkInterval <= 0 {
+				checkInterval = 10 * time.Second
+			}
+			maxItems := p.GetInt("maxItems", "lexicons.maxItems")
+			if maxItems <= 0 {
+				maxItems = 100
+			}
+			if lexicons, err = lexicon.NewCache(ls, checkInterval, maxItems); err != nil {
+				return nil, err
+			}
+		}
+		return processor.NewStoredLexicons(lexicons)
+	})
+	res.AddPart("accentuator", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewAccentuator(p.GetString("url", "accenter.url"))
+	})
+	res.AddPart("clitics", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewClitics(p.GetString("url", "clitics.url"))
+	})
+	res.AddPart("transcriber", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewTranscriber(p.GetString("url", "transcriber.url"))
+	})
+	res.AddPart("acousticModel", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewAcousticModel(p.Sub("acousticModel"))
+	})
+	res.AddPart("vocoder", func(p *Params) (synthesizer.PartProcessor, error) {
+		return processor.NewVocoder(p.GetString("url", "vocoder.url"))
+	})
+	return res
+}
+
+// Add registers the processor factory
+func (r *Registry) Add(name string, f ProcessorFactory) {
+	r.processors[name] = f
+}
+
+// AddPart registers the part processor factory
+func (r *Registry) AddPart(name string, f PartProcessorFactory) {
+	r.partProcessors[name] = f
+}
+
+func newAddMetrics(p *Params) (synthesizer.Processor, error) {
+	path := p.GetString("path", "")
+	if path == "" {
+		return nil, errors.New("no metrics path")
+	}
+	switch m := p.GetString("metric", ""); m {
+	case "chars":
+		return processor.NewAddMetrics(processor.NewMetricsCharsFunc(path))
+	case "waveLen":
+		return processor.NewAddMetrics(processor.NewMetricsWaveLenFunc(path))
+	default:
+		return nil, errors.Errorf("unknown metric '%s'", m)
+	}
+}
+
+func parseRequestType(s string) (utils.RequestTypeEnum, error) {
+	for rt := utils.RequestOriginal; rt <= utils.RequestOriginalSSML; rt++ {
+		if rt.String() == s {
+			return rt, nil
+		}
+	}
+	return 0, errors.Errorf("unknown request type '%s'", s)
+}
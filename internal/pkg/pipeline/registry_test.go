@@ -0,0 +1,94 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	p, err := newParams(map[string]interface{}{"url": "http://local", "workers": 2, "adjust": true},
+		test.NewConfig(t, "clean:\n  url: http://cl.su\n  workers: 5\nloudness:\n  adjust: false\n  workers: 3\n"))
+	require.Nil(t, err)
+	assert.Equal(t, "http://local", p.GetString("url", "clean.url"))
+	assert.Equal(t, "http://cl.su", p.GetString("url1", "clean.url"))
+	assert.Equal(t, "", p.GetString("url1", "clean.url1"))
+	assert.Equal(t, 2, p.GetInt("workers", "loudness.workers"))
+	assert.Equal(t, 3, p.GetInt("workers1", "loudness.workers"))
+	assert.True(t, p.GetBool("adjust", "loudness.adjust"))
+	assert.False(t, p.GetBool("adjust1", "loudness.adjust"))
+}
+
+func TestParams_GetDuration(t *testing.T) {
+	p, err := newParams(map[string]interface{}{"checkInterval": "5s"},
+		test.NewConfig(t, "lexicons:\n  checkInterval: 1m\n"))
+	require.Nil(t, err)
+	assert.Equal(t, 5*time.Second, p.GetDuration("checkInterval", "lexicons.checkInterval"))
+	assert.Equal(t, time.Minute, p.GetDuration("checkInterval1", "lexicons.checkInterval"))
+	assert.Equal(t, time.Duration(0), p.GetDuration("checkInterval1", "lexicons.checkInterval1"))
+}
+
+func TestParams_Sub(t *testing.T) {
+	p, _ := newParams(map[string]interface{}{"url": "http://local"},
+		test.NewConfig(t, "acousticModel:\n  url: http://am.su\n  hasVocoder: true\n"))
+	sub := p.Sub("acousticModel")
+	require.NotNil(t, sub)
+	assert.Equal(t, "http://local", sub.GetString("url"))
+	assert.True(t, sub.GetBool("hasVocoder"))
+
+	p, _ = newParams(nil, test.NewConfig(t, ""))
+	assert.Nil(t, p.Sub("acousticModel"))
+	p, _ = newParams(map[string]interface{}{"url": "http://local"}, test.NewConfig(t, ""))
+	sub = p.Sub("acousticModel")
+	require.NotNil(t, sub)
+	assert.Equal(t, "http://local", sub.GetString("url"))
+}
+
+func TestNewAddMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "Chars", params: map[string]interface{}{"metric": "chars", "path": "/synthesize"}, wantErr: false},
+		{name: "WaveLen", params: map[string]interface{}{"metric": "waveLen", "path": "/synthesize"}, wantErr: false},
+		{name: "No path", params: map[string]interface{}{"metric": "chars"}, wantErr: true},
+		{name: "Unknown", params: map[string]interface{}{"metric": "olia", "path": "/synthesize"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newParams(tt.params, test.NewConfig(t, ""))
+			_, err := newAddMetrics(p)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestParseRequestType(t *testing.T) {
+	tests := []struct {
+		v       string
+		want    utils.RequestTypeEnum
+		wantErr bool
+	}{
+		{v: "original", want: utils.RequestOriginal},
+		{v: "cleaned", want: utils.RequestCleaned},
+		{v: "normalized", want: utils.RequestNormalized},
+		{v: "user", want: utils.RequestUser},
+		{v: "originalSSML", want: utils.RequestOriginalSSML},
+		{v: "", wantErr: true},
+		{v: "olia", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			got, err := parseRequestType(tt.v)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
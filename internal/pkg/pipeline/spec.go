@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+//go:embed default.yml
+var defaultSpec []byte
+
+// Spec is the pipeline definition
+type Spec struct {
+	// Parts is the default part processors chain for the partRunner stages
+	Parts      []*Stage    `mapstructure:"parts"`
+	Synthesize *WorkerSpec `mapstructure:"synthesize"`
+	Custom     *WorkerSpec `mapstructure:"custom"`
+	Analyze    *WorkerSpec `mapstructure:"analyze"`
+}
+
+// WorkerSpec defines the processors of one synthesizer.MainWorker
+type WorkerSpec struct {
+	Text []*Stage `mapstructure:"text"`
+	SSML []*Stage `mapstructure:"ssml"`
+}
+
+// Stage is one processor in the pipeline
+type Stage struct {
+	Type   string                 `mapstructure:"type"`
+	Params map[string]interface{} `mapstructure:"params"`
+	// When is the config key enabling the stage, '!' negates the value
+	When string `mapstructure:"when"`
+	// Parts overrides the default part processors for the partRunner
+	Parts []*Stage `mapstructure:"parts"`
+	// Processors are the stages of the ssmlPartRunner
+	Processors []*Stage `mapstructure:"processors"`
+	// Optional stage is skipped on failure, the response is marked as degraded
+	Optional bool `mapstructure:"optional"`
+	// Timeout limits the optional stage if > 0
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// LoadSpec reads the spec from the 'pipeline' config section or returns the default one
+func LoadSpec(cfg *viper.Viper) (*Spec, error) {
+	if cfg.IsSet("pipeline") {
+		return unmarshalSpec(cfg)
+	}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(defaultSpec)); err != nil {
+		return nil, fmt.Errorf("read default pipeline: %w", err)
+	}
+	return unmarshalSpec(v)
+}
+
+func unmarshalSpec(cfg *viper.Viper) (*Spec, error) {
+	res := &Spec{}
+	if err := cfg.UnmarshalKey("pipeline", res); err != nil {
+		return nil, fmt.Errorf("unmarshal pipeline: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSpec_Default(t *testing.T) {
+	spec, err := LoadSpec(test.NewConfig(t, "clean:\n  url: http://cl.su\n"))
+	require.Nil(t, err)
+	require.NotNil(t, spec.Synthesize)
+	require.NotNil(t, spec.Custom)
+	require.NotNil(t, spec.Analyze)
+	assert.Equal(t, 8, len(spec.Parts))
+	assert.True(t, spec.Parts[0].Optional)
+	assert.False(t, spec.Parts[3].Optional)
+	assert.Equal(t, "lexicons", spec.Parts[2].Type)
+	assert.Equal(t, "lexicons.enabled", spec.Parts[2].When)
+	assert.Equal(t, "vocoder", spec.Parts[7].Type)
+	assert.Equal(t, "!acousticModel.hasVocoder", spec.Parts[7].When)
+	assert.Equal(t, "addMetrics", spec.Synthesize.Text[0].Type)
+	assert.Equal(t, "chars", spec.Synthesize.Text[0].Params["metric"])
+	assert.Equal(t, "ssmlAudioLoader", spec.Synthesize.SSML[2].Type)
+	assert.Equal(t, "ssmlPartRunner", spec.Synthesize.SSML[5].Type)
+	assert.Equal(t, 11, len(spec.Synthesize.SSML[5].Processors))
+	assert.Equal(t, 0, len(spec.Custom.SSML))
+	assert.Equal(t, 6, len(spec.Analyze.Text[11].Parts))
+}
+
+func TestLoadSpec_Config(t *testing.T) {
+	spec, err := LoadSpec(test.NewConfig(t, `
+pipeline:
+  parts:
+    - type: acousticModel
+      params: {url: http://am.su}
+  synthesize:
+    text:
+      - type: validator
+        params: {maxChars: 10}
+      - type: partRunner
+      - type: cleaner
+        optional: true
+        timeout: 5s
+`))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(spec.Parts))
+	assert.Equal(t, "http://am.su", spec.Parts[0].Params["url"])
+	require.NotNil(t, spec.Synthesize)
+	assert.Equal(t, 3, len(spec.Synthesize.Text))
+	assert.True(t, spec.Synthesize.Text[2].Optional)
+	assert.Equal(t, 5*time.Second, spec.Synthesize.Text[2].Timeout)
+	// viper lowercases the keys, Params reads them case-insensitively
+	assert.Equal(t, 10, spec.Synthesize.Text[0].Params["maxchars"])
+	assert.Nil(t, spec.Custom)
+	assert.Nil(t, spec.Analyze)
+}
+
+func TestLoadSpec_Fail(t *testing.T) {
+	_, err := LoadSpec(test.NewConfig(t, "pipeline:\n  parts: olia\n"))
+	assert.NotNil(t, err)
+}
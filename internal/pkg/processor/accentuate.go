@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
@@ -58,6 +59,11 @@ func (p *accentuator) Process(ctx context.Context, data *synthesizer.TTSDataPart
 	return nil
 }
 
+// Info return info about processor
+func (p *accentuator) Info() string {
+	return fmt.Sprintf("accentuator(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 func fixWordsForAccent(data *synthesizer.TTSDataPart) ([]*synthesizer.ProcessedWord, error) {
 	res := make([]*synthesizer.ProcessedWord, 0, len(data.Words))
 	for _, w := range data.Words {
@@ -147,9 +153,18 @@ func setAccent(w *synthesizer.ProcessedWord, out accentOutputElement) error {
 		return utils.NewErrBadSymbols(w.Tagged.Word, out.Word)
 	}
 	w.AccentVariant = findBestAccentVariant(out.Accent, w.Tagged.Mi, w.Tagged.Lemma)
+	w.AccentVariants = collectAccentVariants(out.Accent)
 	return nil
 }
 
+func collectAccentVariants(acc []accentInfo) []synthesizer.AccentVariant {
+	var res []synthesizer.AccentVariant
+	for _, a := range acc {
+		res = append(res, a.Variants...)
+	}
+	return res
+}
+
 func findBestAccentVariant(acc []accentInfo, mi string, lema string) *synthesizer.AccentVariant {
 	find := func(fa func(a *accentInfo) bool, fv func(v *synthesizer.AccentVariant) bool) *synthesizer.AccentVariant {
 		for _, a := range acc {
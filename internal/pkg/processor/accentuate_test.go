@@ -132,6 +132,8 @@ func TestMapAccOutput_FindBest(t *testing.T) {
 	err := mapAccentOutput(d, output)
 	assert.Nil(t, err)
 	assert.Equal(t, 102, d.Words[2].AccentVariant.Accent)
+	assert.Equal(t, []synthesizer.AccentVariant{{Accent: 101, Syll: "v-1"}, {Accent: 102, Syll: "v-1"}},
+		d.Words[2].AccentVariants)
 }
 
 func TestMapAccOutput_Error(t *testing.T) {
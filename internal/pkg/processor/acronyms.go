@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -59,6 +60,11 @@ func (p *acronyms) Process(ctx context.Context, data *synthesizer.TTSDataPart) e
 	return nil
 }
 
+// Info return info about processor
+func (p *acronyms) Info() string {
+	return fmt.Sprintf("acronyms(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 type acrWordOutput struct {
 	ID    string          `json:"id,omitempty"`
 	Words []acrResultWord `json:"words,omitempty"`
@@ -122,7 +128,8 @@ func needAbbrProcessing(w *synthesizer.ProcessedWord) bool {
 		return true
 	}
 	if isAccented(w) || // do not do acronyms change if user has provided accent
-		hasUserTranscriptions(w) {
+		hasUserTranscriptions(w) ||
+		isSayAsNumber(w.TextPart) { // already spelled out by <say-as>
 		return false
 	}
 
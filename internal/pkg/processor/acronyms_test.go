@@ -237,6 +237,8 @@ func Test_needAbbrProcessing(t *testing.T) {
 		{name: "greek", w: &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "o"}, NERType: synthesizer.NERGreekLetters}, want: true},
 		{name: "Accented", w: &synthesizer.ProcessedWord{UserAccent: 301, Tagged: synthesizer.TaggedWord{Word: "o"}, NERType: synthesizer.NERSingleLetter}, want: false},
 		{name: "Obscene", w: &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "o"}, NERType: synthesizer.NERRegular, Obscene: true}, want: true},
+		{name: "say-as date", w: &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "o"}, NERType: synthesizer.NERSingleLetter,
+			TextPart: &synthesizer.TTSTextPart{InterpretAs: ssml.InterpretAsTypeDate}}, want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"strings"
 	"time"
@@ -118,6 +119,11 @@ func (p *amodel) Process(ctx context.Context, data *synthesizer.TTSDataPart) err
 	return nil
 }
 
+// Info return info about processor
+func (p *amodel) Info() string {
+	return fmt.Sprintf("acousticModel(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 func mapAMOutputDurations(ctx context.Context, data *synthesizer.TTSDataPart, durations []int, indRes []*synthesizer.SynthesizedPos, volChanges []float64) error {
 	sums := make([]int, len(durations)+1)
 	for i := 0; i < len(durations); i++ {
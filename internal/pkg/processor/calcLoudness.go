@@ -18,7 +18,10 @@ type calcLoudness struct {
 	parallelWorkers int
 }
 
-// NewCalcLoudness creates loudness calculator processor
+// NewCalcLoudness creates loudness calculator processor.
+// It skips the streamed audio: the parts are written before all of them are synthesized,
+// so streamAudio adjusts the loudness itself while the parts arrive.
+// Both adjust the parts to the first part with normal loudness
 func NewCalcLoudness(parallelWorkers int) synthesizer.Processor {
 	if parallelWorkers < 1 {
 		parallelWorkers = 3
@@ -34,6 +37,10 @@ func (p *calcLoudness) Process(ctx context.Context, data *synthesizer.TTSData) e
 	if data.Input.OutputFormat == api.AudioNone {
 		return nil
 	}
+	if streamed(data) {
+		log.Ctx(ctx).Debug().Msg("Skip loudness calculation of the streamed audio")
+		return nil
+	}
 
 	return addLoudness(ctx, data.Parts, p.parallelWorkers)
 }
@@ -135,7 +142,7 @@ type calcLoudnessSSML struct {
 	parallelWorkers int
 }
 
-// NewCalcLoudnessSSML creates loudness calculator processor
+// NewCalcLoudnessSSML creates loudness calculator processor, it skips the streamed audio as NewCalcLoudness
 func NewCalcLoudnessSSML(parallelWorkers int) synthesizer.Processor {
 	if parallelWorkers < 1 {
 		parallelWorkers = 3
@@ -151,6 +158,10 @@ func (p *calcLoudnessSSML) Process(ctx context.Context, data *synthesizer.TTSDat
 	if data.Input.OutputFormat == api.AudioNone {
 		return nil
 	}
+	if streamed(data) {
+		log.Ctx(ctx).Debug().Msg("Skip loudness calculation of the streamed audio")
+		return nil
+	}
 
 	return addLoudness(ctx, collectPartsFromSSML(data.SSMLParts), p.parallelWorkers)
 }
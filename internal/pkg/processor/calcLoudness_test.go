@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
@@ -31,6 +32,18 @@ func TestCalcLoudness_Skip(t *testing.T) {
 	assert.InDelta(t, 0.0, d.Parts[0].Loudness, 0.001)
 	assert.InDelta(t, 0.0, d.Parts[0].LoudnessGain, 0.001)
 }
+func TestCalcLoudness_SkipStreamed(t *testing.T) {
+	pr := NewCalcLoudness(2)
+	d := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, AudioStream: &bytes.Buffer{}}}
+	strA := getWaveDataWithName(t, "sine_1s.wav")
+	d.Parts = []*synthesizer.TTSDataPart{{Audio: strA}, {Audio: strA}}
+	d.PartListener = newAudioStreamer(&d, true)
+	err := pr.Process(context.TODO(), &d)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.0, d.Parts[0].Loudness, 0.001)
+	assert.InDelta(t, 0.0, d.Parts[0].LoudnessGain, 0.001)
+}
+
 func TestCalcLoudness_SkipOne(t *testing.T) {
 	pr := NewCalcLoudness(2)
 	d := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioMP3}}
@@ -52,7 +52,7 @@ func (p *cleaner) Process(ctx context.Context, data *synthesizer.TTSData) error
 		return utils.ErrNoInput
 	}
 	if len(data.CleanedText) == 1 && len(data.OriginalTextParts) == 0 { // fix for simple case
-		data.OriginalTextParts = []*synthesizer.TTSTextPart{{Text: data.CleanedText[0]}}
+		data.OriginalTextParts = []*synthesizer.TTSTextPart{{Text: data.CleanedText[0], Source: data.OriginalText}}
 	}
 	utils.LogData(ctx, "Output", strings.Join(data.CleanedText, " "), nil)
 	return nil
@@ -43,6 +43,7 @@ func TestCleanProcess(t *testing.T) {
 	cp1 := httpJSONMock.Calls[0].Arguments[0]
 	assert.Equal(t, &normData{Text: " a a"}, cp1)
 	assert.Equal(t, []string{"clean text"}, d.CleanedText)
+	assert.Equal(t, []*synthesizer.TTSTextPart{{Text: "clean text", Source: " a a"}}, d.OriginalTextParts)
 }
 
 func TestCleanProcess_Fail(t *testing.T) {
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/airenas/go-app/pkg/goapp"
@@ -45,6 +46,11 @@ func (p *comparator) Process(ctx context.Context, data *synthesizer.TTSData) err
 	return nil
 }
 
+// Info return info about processor
+func (p *comparator) Info() string {
+	return fmt.Sprintf("comparator(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 type compIn struct {
 	Original string `json:"original"`
 	Modified string `json:"modified"`
@@ -2,11 +2,13 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/clitics/service/api"
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -51,6 +53,11 @@ func (p *cliticDetector) Process(ctx context.Context, data *synthesizer.TTSDataP
 	return nil
 }
 
+// Info return info about processor
+func (p *cliticDetector) Info() string {
+	return fmt.Sprintf("clitics(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 func mapCliticsInput(data *synthesizer.TTSDataPart) ([]*api.CliticsInput, error) {
 	res := []*api.CliticsInput{}
 	for i, w := range data.Words {
@@ -77,10 +84,12 @@ func toType(t *synthesizer.TaggedWord) string {
 }
 
 func mapCliticsOutput(data *synthesizer.TTSDataPart, out []api.CliticsOutput) error {
-	for _, co := range out {
-		if co.ID >= len(data.Words) {
+	for _, co := range out { // validate before changing the data
+		if co.ID < 0 || co.ID >= len(data.Words) {
 			return errors.Errorf("wrong clitics output ID = '%d'. Max %d", co.ID, len(data.Words))
 		}
+	}
+	for _, co := range out {
 		w := data.Words[co.ID]
 		if co.AccentType == api.TypeStatic {
 			w.Clitic.Type = synthesizer.CliticsCustom
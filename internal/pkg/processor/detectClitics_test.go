@@ -98,6 +98,19 @@ func TestMapCliticsOutput(t *testing.T) {
 	assert.Equal(t, synthesizer.CliticsNone, d.Words[1].Clitic.Type)
 }
 
+func TestMapCliticsOutput_FailLeavesData(t *testing.T) {
+	d := newTestTTSDataPart()
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "olia", Lemma: "lemma", Mi: "mi"}})
+
+	output := []api.CliticsOutput{{ID: 0, Type: "CLITIC", Accent: 103, AccentType: api.TypeStatic},
+		{ID: 1, Type: "PHRASE", AccentType: "NONE"}}
+
+	err := mapCliticsOutput(d, output)
+	assert.NotNil(t, err)
+	assert.Equal(t, synthesizer.CliticsUnused, d.Words[0].Clitic.Type)
+	assert.Equal(t, 0, d.Words[0].Clitic.Accent)
+}
+
 func TestToType(t *testing.T) {
 	assert.Equal(t, "OTHER", toType(&synthesizer.TaggedWord{SentenceEnd: true}))
 	assert.Equal(t, "SPACE", toType(&synthesizer.TaggedWord{Space: true}))
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -35,6 +36,11 @@ func (p *filer) Process(ctx context.Context, data *synthesizer.TTSData) error {
 	return p.save(ctx, data.AudioMP3)
 }
 
+// Info return info about processor
+func (p *filer) Info() string {
+	return fmt.Sprintf("filer(%s)", p.dir)
+}
+
 func (p *filer) save(ctx context.Context, data []byte) error {
 	fn := path.Join(p.dir, "out.mp3")
 	log.Ctx(ctx).Debug().Msg("Saving " + fn)
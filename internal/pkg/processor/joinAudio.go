@@ -46,6 +46,14 @@ func (p *joinAudio) Process(ctx context.Context, data *synthesizer.TTSData) erro
 			return errors.Wrapf(err, "can't take suffix %s", data.AudioSuffix)
 		}
 	}
+	if st, ok := data.PartListener.(*audioStreamer); ok {
+		data.Audio, err = st.finish(ctx, suffix)
+		if err != nil {
+			return errors.Wrap(err, "can't finish audio stream")
+		}
+		utils.LogData(ctx, "Output", fmt.Sprintf("audio len %d", len(data.Audio.Data)), nil)
+		return nil
+	}
 	for _, p := range data.Parts {
 		p.TranscribedSymbols = strings.Split(p.TranscribedText, " ")
 	}
@@ -165,7 +173,10 @@ func join(ctx context.Context, parts []*synthesizer.TTSDataPart, suffix []byte,
 	if err != nil {
 		return nil, fmt.Errorf("change volume: %w", err)
 	}
+	return toAudioData(res, resBytes), nil
+}
 
+func toAudioData(res *wavWriter, resBytes []byte) *synthesizer.AudioData {
 	var bufRes bytes.Buffer
 	_, _ = bufRes.Write(res.header)
 
@@ -177,8 +188,7 @@ func join(ctx context.Context, parts []*synthesizer.TTSDataPart, suffix []byte,
 		SampleRate:    res.sampleRate(),
 		BitsPerSample: res.bitsPerSample(),
 		Duration:      time.Duration(len(resBytes)) * time.Second / time.Duration(res.sampleRate()*uint32(res.bitsPerSample()/8)),
-	}, nil
-
+	}
 }
 
 func getStartSilSize(phones []string, durations []int) int {
@@ -279,16 +289,23 @@ func (p *joinSSMLAudio) Process(ctx context.Context, data *synthesizer.TTSData)
 			return errors.Wrapf(err, "can't take suffix %s", data.AudioSuffix)
 		}
 	}
-	for _, dp := range data.SSMLParts {
-		if dp.Cfg.Type == synthesizer.SSMLText {
-			for _, p := range dp.Parts {
-				p.TranscribedSymbols = strings.Split(p.TranscribedText, " ")
+	if st, ok := data.PartListener.(*audioStreamer); ok {
+		data.Audio, err = st.finish(ctx, suffix)
+		if err != nil {
+			return errors.Wrap(err, "can't finish audio stream")
+		}
+	} else {
+		for _, dp := range data.SSMLParts {
+			if dp.Cfg.Type == synthesizer.SSMLText {
+				for _, p := range dp.Parts {
+					p.TranscribedSymbols = strings.Split(p.TranscribedText, " ")
+				}
 			}
 		}
-	}
-	data.Audio, err = joinSSML(ctx, data, suffix, data.Input.MaxEdgeSilenceMillis)
-	if err != nil {
-		return errors.Wrap(err, "can't join audio")
+		data.Audio, err = joinSSML(ctx, data, suffix, data.Input.MaxEdgeSilenceMillis)
+		if err != nil {
+			return errors.Wrap(err, "can't join audio")
+		}
 	}
 	for _, dp := range data.SSMLParts {
 		dp.Audio = data.Audio
@@ -537,12 +554,27 @@ func joinSSML(ctx context.Context, data *synthesizer.TTSData, suffix []byte, max
 	var volChanges []*audio.VolChange
 	//prealocate data
 
+	// the synthesized audio defines the format, <audio> clips are converted to it
+	if part := firstSynthesizedPart(data.SSMLParts); part != nil {
+		ar, err := initAudioReader(ctx, part)
+		if err != nil {
+			return nil, err
+		}
+		res.header, res.bitsPerSampleV, res.sampleRateV = ar.audio.header, ar.audio.bitsPerSample, ar.audio.sampleRate
+	}
+
 	// add words
 	wwd, wwdNext := &wordWriteData{}, &wordWriteData{}
 	for _, dp := range data.SSMLParts {
 		switch dp.Cfg.Type {
 		case synthesizer.SSMLPause:
 			wwd.silence = wwd.silence + dp.Cfg.PauseDuration
+		case synthesizer.SSMLAudio:
+			var err error
+			wwd, err = writeClip(ctx, res, wwd, dp)
+			if err != nil {
+				return nil, err
+			}
 		case synthesizer.SSMLText:
 			for _, part := range dp.Parts {
 				ar, err := initAudioReader(ctx, part)
@@ -575,6 +607,11 @@ func joinSSML(ctx context.Context, data *synthesizer.TTSData, suffix []byte, max
 	if err != nil {
 		return nil, err
 	}
+	if wwd.word == nil && wwd.silence > 0 && res.buf.Len() > 0 { // a pause after the last <audio>
+		if err := appendPause(ctx, res, wwd.silence); err != nil {
+			return nil, err
+		}
+	}
 
 	if res.buf.Len() == 0 {
 		return nil, errors.New("no audio")
@@ -589,18 +626,7 @@ func joinSSML(ctx context.Context, data *synthesizer.TTSData, suffix []byte, max
 	if err != nil {
 		return nil, fmt.Errorf("change volume: %w", err)
 	}
-	var bufRes bytes.Buffer
-	_, _ = bufRes.Write(res.header)
-	_, _ = bufRes.Write([]byte("data"))
-	_, _ = bufRes.Write(wav.SizeBytes(uint32(len(resBytes))))
-	_, _ = bufRes.Write(resBytes)
-
-	return &synthesizer.AudioData{
-		Data:          bufRes.Bytes(),
-		SampleRate:    res.sampleRate(),
-		BitsPerSample: res.bitsPerSample(),
-		Duration:      time.Duration(len(resBytes)) * time.Second / time.Duration(res.sampleRate()*uint32(res.bitsPerSample()/8)),
-	}, nil
+	return toAudioData(res, resBytes), nil
 }
 
 func appendAudioBytes(ctx context.Context, res *wavWriter, audioReader *audioReader, toStep int) error {
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/rs/zerolog/log"
+)
+
+type lexiconApplier struct {
+}
+
+// NewLexicon creates new processor that applies the request's lexicon to the words
+func NewLexicon() (synthesizer.Processor, error) {
+	return &lexiconApplier{}, nil
+}
+
+func (p *lexiconApplier) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	ctx, span := utils.StartSpan(ctx, "lexicon.Process")
+	defer span.End()
+
+	if p.skip(data) {
+		log.Ctx(ctx).Info().Msg("Skip lexicon")
+		return nil
+	}
+	lex, err := lexicon.New(data.Cfg.Input.Lexicon)
+	if err != nil {
+		return err
+	}
+	c := applyLexicon(data.Words, lex)
+	log.Ctx(ctx).Debug().Int("words", c).Msg("lexicon applied")
+	return nil
+}
+
+type lexiconFinder interface {
+	Find(word, lemma, mi string) *lexicon.Entry
+}
+
+func applyLexicon(words []*synthesizer.ProcessedWord, lex lexiconFinder) int {
+	res := 0
+	for _, w := range words {
+		if !w.Tagged.IsWord() || hasUserPronunciation(w) {
+			continue
+		}
+		e := lex.Find(w.Tagged.Word, w.Tagged.Lemma, w.Tagged.Mi)
+		if e == nil {
+			continue
+		}
+		if e.Transcription != nil {
+			w.UserTranscription = e.Transcription.Transcription
+			w.UserSyllables = e.Transcription.Sylls
+			w.TranscriptionWord = e.Transcription.Word
+		} else {
+			w.UserAccent = e.Accent
+			w.UserSyllables = e.Syllables
+		}
+		res++
+	}
+	return res
+}
+
+// hasUserPronunciation checks if the word's pronunciation is set by SSML or by the previous processors
+func hasUserPronunciation(w *synthesizer.ProcessedWord) bool {
+	if w.TextPart != nil && (w.TextPart.Accented != "" || w.TextPart.Transcription != "") {
+		return true
+	}
+	return w.UserTranscription != "" || w.UserAccent != 0
+}
+
+func (p *lexiconApplier) skip(data *synthesizer.TTSData) bool {
+	return data.Cfg.JustAM || len(data.Cfg.Input.Lexicon) == 0
+}
+
+// Info return info about processor
+func (p *lexiconApplier) Info() string {
+	return "lexicon"
+}
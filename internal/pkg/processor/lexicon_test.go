@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+)
+
+func TestLexicon_Process(t *testing.T) {
+	pr, _ := NewLexicon()
+	lex := []*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}, {Grapheme: "kasa", Syllables: "ka-sa", Lemma: "kasa"},
+		{Grapheme: "Microsoft", Transcription: "mai4-kro-sof-tas"}}
+	d := &synthesizer.TTSData{Cfg: synthesizer.TTSConfig{Input: &api.TTSRequestConfig{Lexicon: lex}},
+		Words: []*synthesizer.ProcessedWord{
+			{Tagged: synthesizer.TaggedWord{Word: "tesla"}},
+			{Tagged: synthesizer.TaggedWord{Space: true}},
+			{Tagged: synthesizer.TaggedWord{Word: "kasa", Lemma: "kasti"}},
+			{Tagged: synthesizer.TaggedWord{Word: "kasa", Lemma: "kasa"}},
+			{Tagged: synthesizer.TaggedWord{Word: "Microsoft"}},
+			{Tagged: synthesizer.TaggedWord{Word: "Tesla"}, TextPart: &synthesizer.TTSTextPart{Accented: "T{e~}sla"}},
+			{Tagged: synthesizer.TaggedWord{Separator: "."}},
+		}}
+	err := pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 202, d.Words[0].UserAccent)
+	assert.Equal(t, "", d.Words[2].UserSyllables)
+	assert.Equal(t, "ka-sa", d.Words[3].UserSyllables)
+	assert.Equal(t, 0, d.Words[3].UserAccent)
+	assert.Equal(t, "mai4krosoftas", d.Words[4].UserTranscription)
+	assert.Equal(t, "mai-kro-sof-tas", d.Words[4].UserSyllables)
+	assert.Equal(t, "maikrosoftas", d.Words[4].TranscriptionWord)
+	assert.Equal(t, "Microsoft", d.Words[4].Tagged.Word)
+	assert.Equal(t, 0, d.Words[5].UserAccent)
+}
+
+func TestLexicon_Skip(t *testing.T) {
+	pr, _ := NewLexicon()
+	lex := []*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}}
+	d := &synthesizer.TTSData{Cfg: synthesizer.TTSConfig{JustAM: true, Input: &api.TTSRequestConfig{Lexicon: lex}},
+		Words: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "Tesla"}}}}
+	err := pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d.Words[0].UserAccent)
+
+	d.Cfg = synthesizer.TTSConfig{Input: &api.TTSRequestConfig{}}
+	err = pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, d.Words[0].UserAccent)
+}
+
+func TestLexicon_Fail(t *testing.T) {
+	pr, _ := NewLexicon()
+	d := &synthesizer.TTSData{Cfg: synthesizer.TTSConfig{Input: &api.TTSRequestConfig{
+		Lexicon: []*api.LexiconEntry{{Grapheme: "Tesla"}}}},
+		Words: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "Tesla"}}}}
+	err := pr.Process(context.TODO(), d)
+	assert.NotNil(t, err)
+}
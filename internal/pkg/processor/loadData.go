@@ -37,3 +37,8 @@ func (p *loader) Process(ctx context.Context, data *synthesizer.TTSData) error {
 	}
 	return nil
 }
+
+// Info return info about processor
+func (p *loader) Info() string {
+	return "loader"
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/accent"
+	"github.com/airenas/tts-line/internal/pkg/sayas"
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
 	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/airenas/tts-line/internal/pkg/utils/dtw"
@@ -86,7 +87,11 @@ func (p *numberReplace) Process(ctx context.Context, data *synthesizer.TTSData)
 		return nil
 	}
 
-	noURLS, err := removeURLs(ctx, p.urlFinder, data.NormalizedText)
+	text, err := sayAs(data.NormalizedText, data.OriginalTextParts)
+	if err != nil {
+		return err
+	}
+	noURLS, err := removeURLs(ctx, p.urlFinder, text)
 	if err != nil {
 		return fmt.Errorf("remove URLs: %w", err)
 	}
@@ -163,15 +168,40 @@ func (p *ssmlNumberReplace) Process(ctx context.Context, data *synthesizer.TTSDa
 		log.Ctx(ctx).Info().Msg("Skip numberReplace")
 		return nil
 	}
+	text, err := sayAs(data.CleanedText, data.OriginalTextParts)
+	if err != nil {
+		return err
+	}
 	res := ""
-	err := p.httpWrap.InvokeText(ctx, accent.ClearAccents(strings.Join(data.CleanedText, " ")), &res)
+	err = p.httpWrap.InvokeText(ctx, accent.ClearAccents(strings.Join(text, " ")), &res)
 	if err != nil {
 		return err
 	}
-	data.TextWithNumbers, err = mapAccentsBack(ctx, res, data.CleanedText)
+	data.TextWithNumbers, err = mapAccentsBack(ctx, res, text)
 	return err
 }
 
+// sayAs replaces the text of <say-as> cardinal, ordinal, digits, telephone, date and time parts with words,
+// so the number replacer does not change them
+func sayAs(text []string, textParts []*synthesizer.TTSTextPart) ([]string, error) {
+	res := append([]string{}, text...)
+	for i, tp := range textParts {
+		if i >= len(res) || !isSayAsNumber(tp) {
+			continue
+		}
+		s, err := sayas.Say(tp.InterpretAs.String(), tp.InterpretAsFormat, tp.Text)
+		if err != nil {
+			return nil, fmt.Errorf("say-as: %w", err)
+		}
+		res[i] = s
+	}
+	return res, nil
+}
+
+func isSayAsNumber(tp *synthesizer.TTSTextPart) bool {
+	return tp != nil && sayas.IsSupported(tp.InterpretAs.String())
+}
+
 func mapAccentsBack(ctx context.Context, new string, origArr []string) ([]string, error) {
 	ctx, span := utils.StartSpan(ctx, "numberReplace.mapAccentsBack")
 	defer span.End()
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/pkg/ssml"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -48,6 +49,25 @@ func TestInvokeNumberReplace(t *testing.T) {
 	assert.Equal(t, []string{"trys"}, d.TextWithNumbers)
 }
 
+func TestInvokeNumberReplace_SayAs(t *testing.T) {
+	initTest(t)
+	pr, _ := NewNumberReplace("http://server")
+	assert.NotNil(t, pr)
+	pr.(*numberReplace).httpWrap = httpInvokerMock
+	d := synthesizer.TTSData{NormalizedText: []string{"Skambinkite", "8-612-0456"},
+		OriginalTextParts: []*synthesizer.TTSTextPart{{Text: "Skambinkite"}, {Text: "8 612 0456", InterpretAs: ssml.InterpretAsTypeTelephone}}}
+	var got string
+	httpInvokerMock.On("InvokeText", mock.Anything, mock.Anything).Run(
+		func(params mock.Arguments) {
+			got = params[0].(string)
+			*params[1].(*string) = got
+		}).Return(nil)
+	err := pr.Process(context.TODO(), &d)
+	assert.Nil(t, err)
+	assert.Equal(t, "Skambinkite aštuoni, šeši šimtai dvylika, nulis keturi, penkiasdešimt šeši", got)
+	assert.Equal(t, []string{"Skambinkite", "aštuoni, šeši šimtai dvylika, nulis keturi, penkiasdešimt šeši"}, d.TextWithNumbers)
+}
+
 func TestInvokeNumberReplace_Fail(t *testing.T) {
 	initTest(t)
 	pr, _ := NewNumberReplace("http://server")
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
@@ -52,6 +53,11 @@ func (p *obscene) Process(ctx context.Context, data *synthesizer.TTSDataPart) er
 	return nil
 }
 
+// Info return info about processor
+func (p *obscene) Info() string {
+	return fmt.Sprintf("obscene(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 type obsceneToken struct {
 	Token string `json:"token"`
 }
@@ -73,8 +79,9 @@ func mapObsceneInput(data *synthesizer.TTSDataPart) []*obsceneToken {
 }
 
 func mapObsceneOutput(data *synthesizer.TTSDataPart, out []obsceneResultToken) error {
+	res := make([]bool, len(data.Words))
 	i := 0
-	for _, w := range data.Words {
+	for j, w := range data.Words {
 		tgw := w.Tagged
 		if tgw.IsWord() && w.UserTranscription == "" {
 			if len(out) <= i {
@@ -84,10 +91,16 @@ func mapObsceneOutput(data *synthesizer.TTSDataPart, out []obsceneResultToken) e
 				return errors.Errorf("wrong obscene filter result. Index %d, wanted %s, got %s",
 					i, w.Tagged.Word, out[i].Token)
 			}
-			w.Obscene = out[i].Obscene == 1
+			res[j] = out[i].Obscene == 1
 			i++
 		}
 	}
+	// apply only a fully validated result, the data stays untouched on error
+	for j, w := range data.Words {
+		if w.Tagged.IsWord() && w.UserTranscription == "" {
+			w.Obscene = res[j]
+		}
+	}
 	return nil
 }
 
@@ -67,6 +67,22 @@ func TestInvokeObscene_FailOutput(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestInvokeObscene_FailOutputLeavesData(t *testing.T) {
+	initTestJSON(t)
+	pr, _ := NewObsceneFilter("http://server")
+	pr.(*obscene).httpWrap = httpJSONMock
+	d := newTestTTSDataPart()
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "word"}})
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "word1"}})
+	httpJSONMock.On("InvokeJSON", mock.Anything, mock.Anything).Run(
+		func(params mock.Arguments) {
+			*params[1].(*[]obsceneResultToken) = []obsceneResultToken{{Token: "word", Obscene: 1}}
+		}).Return(nil)
+	err := pr.Process(context.TODO(), d)
+	assert.NotNil(t, err)
+	assert.False(t, d.Words[0].Obscene)
+}
+
 func TestInvokeObscene_Skip(t *testing.T) {
 	initTestJSON(t)
 	pr, _ := NewObsceneFilter("http://server")
@@ -153,7 +169,7 @@ func Test_mapObsceneOutput(t *testing.T) {
 		{name: "Fail", args: args{wrds: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "word"}},
 			{Tagged: synthesizer.TaggedWord{Word: "word2"}}},
 			out: []obsceneResultToken{{Token: "word", Obscene: 1}}},
-			want: []bool{true, false}, wantErr: true},
+			want: []bool{false, false}, wantErr: true},
 		{name: "Fail word", args: args{wrds: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "word"}}},
 			out: []obsceneResultToken{{Token: "wordx", Obscene: 1}}},
 			want: []bool{false}, wantErr: true},
@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+)
+
+var stageSkippedMetrics = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tts_stage_skipped_total",
+		Help: "The total number of the failed optional stages skipped",
+	},
+	[]string{"stage"},
+)
+
+func init() {
+	prometheus.MustRegister(stageSkippedMetrics)
+}
+
+// optionalStage skips the stage on infrastructure failures or timeout and records it to the context's utils.SkippedStages.
+// Input errors are returned as is
+type optionalStage struct {
+	name    string
+	timeout time.Duration
+}
+
+func (o *optionalStage) run(ctx context.Context, f func(context.Context) error) error {
+	sCtx := ctx
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		sCtx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	err := f(sCtx)
+	if err == nil || ctx.Err() != nil { // the request itself is cancelled
+		return err
+	}
+	if errorClass(err) == errClassInput {
+		return err
+	}
+	log.Ctx(ctx).Warn().Err(err).Str("stage", o.name).Msg("skip failed optional stage")
+	utils.SkippedStagesFromContext(ctx).Add(o.name)
+	stageSkippedMetrics.WithLabelValues(o.name).Inc()
+	return nil
+}
+
+type optional struct {
+	pr synthesizer.Processor
+	optionalStage
+}
+
+// NewOptional wraps the processor to skip it on failure, timeout limits the processor if > 0
+func NewOptional(pr synthesizer.Processor, name string, timeout time.Duration) synthesizer.Processor {
+	return &optional{pr: pr, optionalStage: optionalStage{name: name, timeout: timeout}}
+}
+
+// Process main processor method
+func (p *optional) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	return p.run(ctx, func(ctx context.Context) error { return p.pr.Process(ctx, data) })
+}
+
+// Info return info about processor
+func (p *optional) Info() string {
+	return utils.RetrieveInfo(p.pr)
+}
+
+type optionalPart struct {
+	pr synthesizer.PartProcessor
+	optionalStage
+}
+
+// NewOptionalPart wraps the part processor to skip it on failure, timeout limits the processor if > 0
+func NewOptionalPart(pr synthesizer.PartProcessor, name string, timeout time.Duration) synthesizer.PartProcessor {
+	return &optionalPart{pr: pr, optionalStage: optionalStage{name: name, timeout: timeout}}
+}
+
+// Process main processor method
+func (p *optionalPart) Process(ctx context.Context, data *synthesizer.TTSDataPart) error {
+	return p.run(ctx, func(ctx context.Context) error { return p.pr.Process(ctx, data) })
+}
+
+// Info return info about processor
+func (p *optionalPart) Info() string {
+	return utils.RetrieveInfo(p.pr)
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+)
+
+func TestOptional(t *testing.T) {
+	called := false
+	pr := NewOptional(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		called = true
+		return nil
+	}}, "opt", 0)
+	s := &utils.SkippedStages{}
+	err := pr.Process(utils.WithSkippedStages(context.TODO(), s), &synthesizer.TTSData{})
+	assert.Nil(t, err)
+	assert.True(t, called)
+	assert.Nil(t, s.Names())
+}
+
+func TestOptional_Info(t *testing.T) {
+	assert.Equal(t, "splitter(100)", utils.RetrieveInfo(NewOptional(NewSplitter(100), "splitter", 0)))
+}
+
+func TestOptional_Skips(t *testing.T) {
+	pr := NewOptional(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		return errors.New("olia")
+	}}, "optFail", 0)
+	s := &utils.SkippedStages{}
+	err := pr.Process(utils.WithSkippedStages(context.TODO(), s), &synthesizer.TTSData{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"optFail"}, s.Names())
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageSkippedMetrics.WithLabelValues("optFail")), 0.000001)
+}
+
+func TestOptional_InputError(t *testing.T) {
+	pr := NewOptional(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		return errors.Wrap(utils.NewErrWordTooLong("olia"), "olia")
+	}}, "optInput", 0)
+	s := &utils.SkippedStages{}
+	err := pr.Process(utils.WithSkippedStages(context.TODO(), s), &synthesizer.TTSData{})
+	var errWTL *utils.ErrWordTooLong
+	assert.ErrorAs(t, err, &errWTL)
+	assert.Nil(t, s.Names())
+}
+
+func TestOptional_Timeout(t *testing.T) {
+	pr := NewOptional(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}, "optTimeout", 10*time.Millisecond)
+	s := &utils.SkippedStages{}
+	err := pr.Process(utils.WithSkippedStages(context.TODO(), s), &synthesizer.TTSData{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"optTimeout"}, s.Names())
+}
+
+func TestOptional_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	pr := NewOptional(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		cancel()
+		return ctx.Err()
+	}}, "optCancel", time.Second)
+	s := &utils.SkippedStages{}
+	err := pr.Process(utils.WithSkippedStages(ctx, s), &synthesizer.TTSData{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, s.Names())
+}
+
+func TestOptionalPart_Skips(t *testing.T) {
+	pr := NewOptionalPart(&partProcMock{f: func(ctx context.Context, res *synthesizer.TTSDataPart) error {
+		return errors.New("olia")
+	}}, "optPart", 0)
+	s := &utils.SkippedStages{}
+	ctx := utils.WithSkippedStages(context.TODO(), s)
+	assert.Nil(t, pr.Process(ctx, &synthesizer.TTSDataPart{}))
+	assert.Nil(t, pr.Process(ctx, &synthesizer.TTSDataPart{}))
+	assert.Equal(t, []string{"optPart"}, s.Names())
+	assert.Nil(t, pr.Process(context.TODO(), &synthesizer.TTSDataPart{}))
+}
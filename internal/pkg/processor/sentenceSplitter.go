@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// SentenceSplitter splits text to sentences by the tagger's sentence ends
+type SentenceSplitter struct {
+	httpWrap HTTPInvoker
+}
+
+// NewSentenceSplitter creates new sentence splitter
+func NewSentenceSplitter(urlStr string) (*SentenceSplitter, error) {
+	res := &SentenceSplitter{}
+	var err error
+	res.httpWrap, err = newHTTPWrapBackoff(urlStr, time.Second*20)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't init http client")
+	}
+	return res, nil
+}
+
+// Split returns the text split to sentences, the concatenation of the result is the same text.
+// The last item has no sentence end if the text ends in the middle of a sentence
+func (p *SentenceSplitter) Split(ctx context.Context, text string) ([]string, error) {
+	ctx, span := utils.StartSpan(ctx, "SentenceSplitter.Split")
+	defer span.End()
+
+	if strings.TrimSpace(text) == "" {
+		return []string{text}, nil
+	}
+	var output []*TaggedWord
+	if err := p.httpWrap.InvokeText(ctx, text, &output); err != nil {
+		return nil, err
+	}
+	return splitSentences(text, output)
+}
+
+func splitSentences(text string, tags []*TaggedWord) ([]string, error) {
+	var res []string
+	from, pos := 0, 0
+	for _, t := range tags {
+		if t.Type == "SENTENCE_END" {
+			if pos > from {
+				res = append(res, text[from:pos])
+				from = pos
+			}
+			continue
+		}
+		if t.String == "" {
+			continue
+		}
+		i := strings.Index(text[pos:], t.String)
+		if i < 0 {
+			return nil, errors.Errorf("can't find '%s' at %d", t.String, pos)
+		}
+		pos += i + len(t.String)
+	}
+	if from < len(text) {
+		res = append(res, text[from:])
+	}
+	return res, nil
+}
+
+// Info return info about splitter
+func (p *SentenceSplitter) Info() string {
+	return fmt.Sprintf("sentenceSplitter(%s)", utils.RetrieveInfo(p.httpWrap))
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSentenceSplitter(t *testing.T) {
+	pr, err := NewSentenceSplitter("http://server")
+	assert.Nil(t, err)
+	assert.NotNil(t, pr)
+	_, err = NewSentenceSplitter("")
+	assert.NotNil(t, err)
+}
+
+func TestSentenceSplitter_Split(t *testing.T) {
+	initTest(t)
+	pr, _ := NewSentenceSplitter("http://server")
+	pr.httpWrap = httpInvokerMock
+	httpInvokerMock.On("InvokeText", "Labas. Kaip", mock.Anything).Run(
+		func(params mock.Arguments) {
+			*params[1].(*[]*TaggedWord) = []*TaggedWord{{Type: "WORD", String: "Labas"},
+				{Type: "SEPARATOR", String: "."}, {Type: "SENTENCE_END"}, {Type: "SPACE", String: " "},
+				{Type: "WORD", String: "Kaip"}, {Type: "SENTENCE_END"}}
+		}).Return(nil)
+	res, err := pr.Split(context.TODO(), "Labas. Kaip")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Labas.", " Kaip"}, res)
+}
+
+func TestSentenceSplitter_Split_Empty(t *testing.T) {
+	initTest(t)
+	pr, _ := NewSentenceSplitter("http://server")
+	pr.httpWrap = httpInvokerMock
+	res, err := pr.Split(context.TODO(), "  ")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"  "}, res)
+	httpInvokerMock.AssertNotCalled(t, "InvokeText", mock.Anything, mock.Anything)
+}
+
+func TestSentenceSplitter_Split_Fail(t *testing.T) {
+	initTest(t)
+	pr, _ := NewSentenceSplitter("http://server")
+	pr.httpWrap = httpInvokerMock
+	httpInvokerMock.On("InvokeText", mock.Anything, mock.Anything).Return(errors.New("olia"))
+	_, err := pr.Split(context.TODO(), "Labas")
+	assert.NotNil(t, err)
+}
+
+func Test_splitSentences(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		tags    []*TaggedWord
+		want    []string
+		wantErr bool
+	}{
+		{name: "one", text: "Labas", tags: []*TaggedWord{{Type: "WORD", String: "Labas"}, {Type: "SENTENCE_END"}},
+			want: []string{"Labas"}},
+		{name: "no end", text: "Labas ", tags: []*TaggedWord{{Type: "WORD", String: "Labas"}},
+			want: []string{"Labas "}},
+		{name: "several", text: "A. B! C",
+			tags: []*TaggedWord{{Type: "WORD", String: "A"}, {Type: "SEPARATOR", String: "."}, {Type: "SENTENCE_END"},
+				{Type: "SPACE", String: " "}, {Type: "WORD", String: "B"}, {Type: "SEPARATOR", String: "!"}, {Type: "SENTENCE_END"},
+				{Type: "SPACE", String: " "}, {Type: "WORD", String: "C"}},
+			want: []string{"A.", " B!", " C"}},
+		{name: "skips double end", text: "A.", tags: []*TaggedWord{{Type: "WORD", String: "A"},
+			{Type: "SEPARATOR", String: "."}, {Type: "SENTENCE_END"}, {Type: "SENTENCE_END"}},
+			want: []string{"A."}},
+		{name: "wrong", text: "A", tags: []*TaggedWord{{Type: "WORD", String: "B"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitSentences(tt.text, tt.tags)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
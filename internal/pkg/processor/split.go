@@ -44,6 +44,11 @@ func (p *splitter) Process(ctx context.Context, data *synthesizer.TTSData) error
 	return nil
 }
 
+// Info return info about processor
+func (p *splitter) Info() string {
+	return fmt.Sprintf("splitter(%d)", p.maxChars)
+}
+
 func split(data []*synthesizer.ProcessedWord, max int) ([]*synthesizer.TTSDataPart, error) {
 	res := []*synthesizer.TTSDataPart{}
 	from := 0
@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/airenas/tts-line/internal/pkg/audio"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/airenas/tts-line/internal/pkg/wav"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type ssmlAudioLoader struct {
+	clipProvider AudioLoader
+}
+
+// NewSSMLAudioLoader creates processor that loads the clips of SSML <audio> elements.
+// clipProvider may be nil if no clips are configured, then any <audio> fails
+func NewSSMLAudioLoader(clipProvider AudioLoader) synthesizer.Processor {
+	return &ssmlAudioLoader{clipProvider: clipProvider}
+}
+
+func (p *ssmlAudioLoader) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	ctx, span := utils.StartSpan(ctx, "ssmlAudioLoader.Process")
+	defer span.End()
+
+	if data.Input.OutputFormat == api.AudioNone {
+		log.Ctx(ctx).Info().Msg("Skip audio loader")
+		return nil
+	}
+	for _, dp := range data.SSMLParts {
+		if dp.Cfg.Type != synthesizer.SSMLAudio {
+			continue
+		}
+		clip, err := p.load(dp.Cfg.AudioSrc)
+		if err != nil {
+			return err
+		}
+		dp.AudioClip = clip
+	}
+	return nil
+}
+
+func (p *ssmlAudioLoader) load(key string) ([]byte, error) {
+	if p.clipProvider == nil {
+		return nil, utils.NewErrBadAudio(key, "audio clips are not configured")
+	}
+	res, err := p.clipProvider.TakeWav(clipFileName(key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, utils.NewErrBadAudio(key, "not found")
+		}
+		return nil, fmt.Errorf("can't load audio %s: %w", key, err)
+	}
+	if !wav.IsValid(res) {
+		return nil, utils.NewErrBadAudio(key, "no valid audio wave data")
+	}
+	header := wav.TakeHeader(res)
+	if c := wav.GetChannels(header); c != 1 {
+		return nil, utils.NewErrBadAudio(key, fmt.Sprintf("expected mono, got %d channels", c))
+	}
+	return res, nil
+}
+
+// clipFileName adds the .wav extension if the key has no extension
+func clipFileName(key string) string {
+	if filepath.Ext(key) == "" {
+		return key + ".wav"
+	}
+	return key
+}
+
+// Info return info about processor
+func (p *ssmlAudioLoader) Info() string {
+	return fmt.Sprintf("ssmlAudioLoader(%s)", utils.RetrieveInfo(p.clipProvider))
+}
+
+// writeClip writes the <audio> clip in place of the part.
+// The pending word and pause are written before the clip, the clip is resampled to the sample rate of the result.
+// Returns the new word data as the next text starts like after a pause
+func writeClip(ctx context.Context, res *wavWriter, wwd *wordWriteData, dp *synthesizer.TTSData) (*wordWriteData, error) {
+	if dp.AudioClip == nil {
+		return nil, utils.NewErrBadAudio(dp.Cfg.AudioSrc, "not loaded")
+	}
+	res.init(dp.AudioClip)
+	if wwd.word != nil {
+		if err := writeWordAudio(ctx, res, wwd, &wordWriteData{}); err != nil {
+			return nil, err
+		}
+	} else if wwd.silence > 0 {
+		if err := appendPause(ctx, res, wwd.silence); err != nil {
+			return nil, err
+		}
+	}
+	header := wav.TakeHeader(dp.AudioClip)
+	if bits := wav.GetBitsPerSample(header); bits != res.bitsPerSample() {
+		return nil, utils.NewErrBadAudio(dp.Cfg.AudioSrc, fmt.Sprintf("expected %d bits per sample, got %d", res.bitsPerSample(), bits))
+	}
+	data, err := audio.Resample(wav.TakeData(dp.AudioClip), wav.GetSampleRate(header), res.sampleRate(), int(res.bytesPerSample()))
+	if err != nil {
+		return nil, fmt.Errorf("resample %s: %w", dp.Cfg.AudioSrc, err)
+	}
+	if sr := wav.GetSampleRate(header); sr != res.sampleRate() {
+		log.Ctx(ctx).Debug().Str("audio", dp.Cfg.AudioSrc).Uint32("from", sr).Uint32("to", res.sampleRate()).Msg("Resampled")
+	}
+	if _, err := res.buf.Write(data); err != nil {
+		return nil, err
+	}
+	return &wordWriteData{}, nil
+}
+
+// firstSynthesizedPart returns the part that defines the format of the SSML result
+func firstSynthesizedPart(parts []*synthesizer.TTSData) *synthesizer.TTSDataPart {
+	for _, dp := range parts {
+		if dp.Cfg.Type == synthesizer.SSMLText && len(dp.Parts) > 0 {
+			return dp.Parts[0]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSSMLAudioLoader(t *testing.T) {
+	initTestJoiner(t)
+	pr := NewSSMLAudioLoader(loaderMock)
+	assert.NotNil(t, pr)
+}
+
+func TestSSMLAudioLoader_Process(t *testing.T) {
+	clip := newTestClip(t, 22050, 16, 1, time.Second)
+	tests := []struct {
+		name    string
+		noLoad  bool
+		key     string
+		wantKey string
+		wav     []byte
+		err     error
+		wantErr bool
+		wantBad bool
+	}{
+		{name: "loads", key: "jingle", wantKey: "jingle.wav", wav: clip},
+		{name: "with extension", key: "jingle.wav", wantKey: "jingle.wav", wav: clip},
+		{name: "not configured", noLoad: true, key: "jingle", wantErr: true, wantBad: true},
+		{name: "not found", key: "jingle", wantKey: "jingle.wav", err: os.ErrNotExist, wantErr: true, wantBad: true},
+		{name: "fails", key: "jingle", wantKey: "jingle.wav", err: os.ErrPermission, wantErr: true},
+		{name: "not wav", key: "jingle", wantKey: "jingle.wav", wav: []byte("olia"), wantErr: true, wantBad: true},
+		{name: "stereo", key: "jingle", wantKey: "jingle.wav", wav: newTestClip(t, 22050, 16, 2, time.Second),
+			wantErr: true, wantBad: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTestJoiner(t)
+			pr := NewSSMLAudioLoader(loaderMock)
+			if tt.noLoad {
+				pr = NewSSMLAudioLoader(nil)
+			} else {
+				loaderMock.On("TakeWav", tt.wantKey).Return(tt.wav, tt.err)
+			}
+			dp := &synthesizer.TTSData{}
+			dp.Cfg.Type = synthesizer.SSMLAudio
+			dp.Cfg.AudioSrc = tt.key
+			d := &synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioMP3},
+				SSMLParts: []*synthesizer.TTSData{{}, dp}}
+			err := pr.Process(context.TODO(), d)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			var errBad *utils.ErrBadAudio
+			assert.Equal(t, tt.wantBad, errors.As(err, &errBad))
+			if !tt.wantErr {
+				assert.Equal(t, tt.wav, dp.AudioClip)
+			}
+		})
+	}
+}
+
+func TestSSMLAudioLoader_Skip(t *testing.T) {
+	pr := NewSSMLAudioLoader(nil)
+	dp := &synthesizer.TTSData{}
+	dp.Cfg.Type = synthesizer.SSMLAudio
+	d := &synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioNone},
+		SSMLParts: []*synthesizer.TTSData{dp}}
+	err := pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+}
+
+func TestJoinSSMLAudio_Clip(t *testing.T) {
+	pr := NewJoinSSMLAudio(nil)
+	input := &api.TTSRequestConfig{OutputFormat: api.AudioMP3, MaxEdgeSilenceMillis: -1}
+	d := &synthesizer.TTSData{Input: input}
+	d.Parts = []*synthesizer.TTSDataPart{{Audio: getTestEncAudio(t),
+		Words: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "olia"},
+			SynthesizedPos: &synthesizer.SynthesizedPos{From: 10, StartIndex: 1, To: 40}}},
+		Durations:       []int{10, 10, 10, 10, 10, 10, 10, 10},
+		TranscribedText: "sil o l i a sp sil",
+		Step:            256,
+		DefaultSilence:  18,
+	}}
+	d.Cfg.Type = synthesizer.SSMLText
+
+	clipPart := func(clip []byte) *synthesizer.TTSData {
+		res := &synthesizer.TTSData{AudioClip: clip}
+		res.Cfg.Type = synthesizer.SSMLAudio
+		res.Cfg.AudioSrc = "jingle"
+		return res
+	}
+	clip := clipPart(newTestClip(t, 44100, 16, 1, time.Second))
+	clipResample := clipPart(newTestClip(t, 22050, 16, 1, time.Second))
+	clip8 := clipPart(newTestClip(t, 44100, 8, 1, time.Second))
+	dp := &synthesizer.TTSData{}
+	dp.Cfg.Type = synthesizer.SSMLPause
+	dp.Cfg.PauseDuration = time.Second * 2
+
+	al := 0.33668
+	startl := 9.0 * 256 * 2 / (44100 * 2)
+
+	tests := []struct {
+		name    string
+		args    []*synthesizer.TTSData
+		wantLen float64
+		wantErr bool
+	}{
+		{name: "after text", args: []*synthesizer.TTSData{d, clip}, wantLen: al + 1},
+		{name: "before text", args: []*synthesizer.TTSData{clip, d}, wantLen: al + 1},
+		{name: "resampled", args: []*synthesizer.TTSData{d, clipResample}, wantLen: al + 1},
+		{name: "pause before", args: []*synthesizer.TTSData{d, dp, clip}, wantLen: al - startl + 2 + 1},
+		{name: "pause after", args: []*synthesizer.TTSData{clip, dp}, wantLen: 1 + 2},
+		{name: "just clip", args: []*synthesizer.TTSData{clipResample}, wantLen: 1},
+		{name: "wrong bits", args: []*synthesizer.TTSData{d, clip8}, wantErr: true},
+		{name: "not loaded", args: []*synthesizer.TTSData{d, clipPart(nil)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			da := &synthesizer.TTSData{Input: input, SSMLParts: tt.args}
+			err := pr.Process(context.TODO(), da)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			if !tt.wantErr {
+				assert.InDelta(t, tt.wantLen, da.Audio.Seconds(), 0.001)
+			}
+		})
+	}
+}
+
+func newTestClip(t *testing.T, sampleRate uint32, bitsPerSample, channels uint16, dur time.Duration) []byte {
+	t.Helper()
+	size := uint32(dur.Seconds() * float64(sampleRate*uint32(bitsPerSample/8*channels)))
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	write := func(v any) { require.Nil(t, binary.Write(buf, binary.LittleEndian, v)) }
+	write(size + 36)
+	buf.WriteString("WAVEfmt ")
+	write(uint32(16))
+	write(uint16(1))
+	write(channels)
+	write(sampleRate)
+	write(sampleRate * uint32(bitsPerSample/8*channels))
+	write(bitsPerSample / 8 * channels)
+	write(bitsPerSample)
+	buf.WriteString("data")
+	write(size)
+	buf.Write(make([]byte, size))
+	return buf.Bytes()
+}
@@ -0,0 +1,151 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+)
+
+// Pipeline types of the stage metrics
+const (
+	PipelineText    = "text"
+	PipelineSSML    = "ssml"
+	PipelineCustom  = "custom"
+	PipelineAnalyze = "analyze"
+)
+
+var stageDurationMetrics = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "tts_stage_duration_seconds",
+		Help:    "The duration of the pipeline stage",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	},
+	[]string{"stage", "pipeline"},
+)
+
+var stageCallMetrics = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tts_stage_calls_total",
+		Help: "The total number of the pipeline stage calls",
+	},
+	[]string{"stage", "pipeline", "voice"},
+)
+
+var stageErrorMetrics = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tts_stage_errors_total",
+		Help: "The total number of the pipeline stage errors",
+	},
+	[]string{"stage", "pipeline", "class"},
+)
+
+func init() {
+	prometheus.MustRegister(stageDurationMetrics, stageCallMetrics, stageErrorMetrics)
+}
+
+// error classes of the stage metrics
+const (
+	errClassCanceled = "canceled"
+	errClassTimeout  = "timeout"
+	errClassInput    = "input"
+	errClassInternal = "internal"
+)
+
+type stageMetrics struct {
+	stage, pipeline string
+}
+
+func newStageMetrics(pr interface{}, def, pipeline string) stageMetrics {
+	res := stageMetrics{stage: def, pipeline: pipeline}
+	if s, _, _ := strings.Cut(utils.RetrieveInfo(pr), "("); strings.TrimSpace(s) != "" {
+		res.stage = strings.TrimSpace(s)
+	}
+	return res
+}
+
+func (m stageMetrics) observe(voice string, start time.Time, err error) {
+	stageDurationMetrics.WithLabelValues(m.stage, m.pipeline).Observe(time.Since(start).Seconds())
+	stageCallMetrics.WithLabelValues(m.stage, m.pipeline, voice).Inc()
+	if err != nil {
+		stageErrorMetrics.WithLabelValues(m.stage, m.pipeline, errorClass(err)).Inc()
+	}
+}
+
+type metricsWrap struct {
+	pr      synthesizer.Processor
+	metrics stageMetrics
+}
+
+// NewMetricsWrap wraps the processor to collect the duration, calls and errors by the Info() name,
+// def is used if the processor has no Info()
+func NewMetricsWrap(pr synthesizer.Processor, def, pipeline string) synthesizer.Processor {
+	return &metricsWrap{pr: pr, metrics: newStageMetrics(pr, def, pipeline)}
+}
+
+// Process main processor method
+func (p *metricsWrap) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	start := time.Now()
+	err := p.pr.Process(ctx, data)
+	p.metrics.observe(data.Cfg.Voice, start, err)
+	return err
+}
+
+// Info return info about processor
+func (p *metricsWrap) Info() string {
+	return utils.RetrieveInfo(p.pr)
+}
+
+type partMetricsWrap struct {
+	pr      synthesizer.PartProcessor
+	metrics stageMetrics
+}
+
+// NewPartMetricsWrap wraps the part processor to collect the duration, calls and errors by the Info() name,
+// def is used if the processor has no Info()
+func NewPartMetricsWrap(pr synthesizer.PartProcessor, def, pipeline string) synthesizer.PartProcessor {
+	return &partMetricsWrap{pr: pr, metrics: newStageMetrics(pr, def, pipeline)}
+}
+
+// Process main processor method
+func (p *partMetricsWrap) Process(ctx context.Context, data *synthesizer.TTSDataPart) error {
+	start := time.Now()
+	err := p.pr.Process(ctx, data)
+	voice := ""
+	if data.Cfg != nil {
+		voice = data.Cfg.Voice
+	}
+	p.metrics.observe(voice, start, err)
+	return err
+}
+
+// Info return info about processor
+func (p *partMetricsWrap) Info() string {
+	return utils.RetrieveInfo(p.pr)
+}
+
+// errorClass groups the errors for the metrics: canceled, timeout, input or internal
+func errorClass(err error) string {
+	var errTTL *utils.ErrTextTooLong
+	var errBA *utils.ErrBadAccent
+	var errWTL *utils.ErrWordTooLong
+	var errBS *utils.ErrBadSymbols
+	var errBAu *utils.ErrBadAudio
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errClassTimeout
+	case errors.Is(err, utils.ErrNoInput), errors.Is(err, utils.ErrNoRecord), errors.Is(err, utils.ErrTextDoesNotMatch),
+		errors.As(err, &errTTL), errors.As(err, &errBA), errors.As(err, &errWTL), errors.As(err, &errBS),
+		errors.As(err, &errBAu):
+		return errClassInput
+	default:
+		return errClassInternal
+	}
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+)
+
+type partProcMock struct {
+	f func(ctx context.Context, res *synthesizer.TTSDataPart) error
+}
+
+func (pr *partProcMock) Process(ctx context.Context, d *synthesizer.TTSDataPart) error {
+	return pr.f(ctx, d)
+}
+
+func TestMetricsWrap(t *testing.T) {
+	w := NewMetricsWrap(NewSplitter(100), "split", PipelineCustom)
+	assert.Equal(t, "splitter(100)", utils.RetrieveInfo(w))
+	d := &synthesizer.TTSData{Cfg: synthesizer.TTSConfig{Voice: "v1"}}
+	err := w.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageCallMetrics.WithLabelValues("splitter", "custom", "v1")), 0.000001)
+	assert.Positive(t, testutil.CollectAndCount(stageDurationMetrics, "tts_stage_duration_seconds"))
+}
+
+func TestMetricsWrap_Fail(t *testing.T) {
+	w := NewMetricsWrap(&procMock{f: func(ctx context.Context, res *synthesizer.TTSData) error {
+		return utils.ErrNoInput
+	}}, "mockFail", PipelineText)
+	assert.Equal(t, "", utils.RetrieveInfo(w))
+	err := w.Process(context.TODO(), &synthesizer.TTSData{Cfg: synthesizer.TTSConfig{Voice: "v1"}})
+	assert.Equal(t, utils.ErrNoInput, err)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageCallMetrics.WithLabelValues("mockFail", "text", "v1")), 0.000001)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageErrorMetrics.WithLabelValues("mockFail", "text", "input")), 0.000001)
+}
+
+func TestPartMetricsWrap(t *testing.T) {
+	w := NewPartMetricsWrap(&partProcMock{f: func(ctx context.Context, res *synthesizer.TTSDataPart) error {
+		return errors.New("olia")
+	}}, "mockPart", PipelineSSML)
+	err := w.Process(context.TODO(), &synthesizer.TTSDataPart{Cfg: &synthesizer.TTSConfig{Voice: "v2"}})
+	assert.NotNil(t, err)
+	err = w.Process(context.TODO(), &synthesizer.TTSDataPart{})
+	assert.NotNil(t, err)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageCallMetrics.WithLabelValues("mockPart", "ssml", "v2")), 0.000001)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(stageCallMetrics.WithLabelValues("mockPart", "ssml", "")), 0.000001)
+	assert.InDelta(t, 2.0, testutil.ToFloat64(stageErrorMetrics.WithLabelValues("mockPart", "ssml", "internal")), 0.000001)
+}
+
+func TestNewStageMetrics(t *testing.T) {
+	pr, _ := NewObsceneFilter("http://obscene.su")
+	assert.Equal(t, stageMetrics{stage: "obscene", pipeline: "text"}, newStageMetrics(pr, "obs", PipelineText))
+	assert.Equal(t, stageMetrics{stage: "lexicon", pipeline: "text"}, newStageMetrics(&lexiconApplier{}, "lex", PipelineText))
+	assert.Equal(t, stageMetrics{stage: "def", pipeline: "text"}, newStageMetrics(&procMock{}, "def", PipelineText))
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "canceled", err: errors.Wrap(context.Canceled, "olia"), want: "canceled"},
+		{name: "timeout", err: context.DeadlineExceeded, want: "timeout"},
+		{name: "no input", err: utils.ErrNoInput, want: "input"},
+		{name: "too long", err: errors.Wrap(utils.NewErrTextTooLong(10, 5), "olia"), want: "input"},
+		{name: "bad accent", err: utils.NewErrBadAccent([]string{"a"}), want: "input"},
+		{name: "word too long", err: utils.NewErrWordTooLong("a"), want: "input"},
+		{name: "bad symbols", err: utils.NewErrBadSymbols("a", "b"), want: "input"},
+		{name: "bad audio", err: utils.NewErrBadAudio("a", "b"), want: "input"},
+		{name: "internal", err: errors.New("olia"), want: "internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errorClass(tt.err))
+		})
+	}
+}
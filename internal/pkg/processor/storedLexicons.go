@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// LexiconProvider returns the stored lexicons by names
+type LexiconProvider interface {
+	Get(ctx context.Context, names []string) (lexicon.List, error)
+}
+
+type storedLexicons struct {
+	provider LexiconProvider
+}
+
+// NewStoredLexicons creates new processor that applies the stored lexicons selected by the request
+func NewStoredLexicons(provider LexiconProvider) (synthesizer.PartProcessor, error) {
+	if provider == nil {
+		return nil, errors.New("no lexicon provider")
+	}
+	return &storedLexicons{provider: provider}, nil
+}
+
+func (p *storedLexicons) Process(ctx context.Context, data *synthesizer.TTSDataPart) error {
+	ctx, span := utils.StartSpan(ctx, "storedLexicons.Process")
+	defer span.End()
+
+	if p.skip(data) {
+		log.Ctx(ctx).Info().Msg("Skip stored lexicons")
+		return nil
+	}
+	lex, err := p.provider.Get(ctx, data.Cfg.Input.Lexicons)
+	if err != nil {
+		return err
+	}
+	c := applyLexicon(data.Words, lex)
+	log.Ctx(ctx).Debug().Int("words", c).Msg("stored lexicons applied")
+	return nil
+}
+
+func (p *storedLexicons) skip(data *synthesizer.TTSDataPart) bool {
+	return data.Cfg.JustAM || data.Cfg.Input == nil || len(data.Cfg.Input.Lexicons) == 0
+}
+
+// Info return info about processor
+func (p *storedLexicons) Info() string {
+	return "lexicons"
+}
@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+)
+
+type mockLexiconProvider struct{ mock.Mock }
+
+func (m *mockLexiconProvider) Get(ctx context.Context, names []string) (lexicon.List, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(lexicon.List), args.Error(1)
+}
+
+func TestNewStoredLexicons(t *testing.T) {
+	pr, err := NewStoredLexicons(&mockLexiconProvider{})
+	assert.NotNil(t, pr)
+	assert.Nil(t, err)
+	_, err = NewStoredLexicons(nil)
+	assert.NotNil(t, err)
+}
+
+func TestStoredLexicons_Process(t *testing.T) {
+	pm := &mockLexiconProvider{}
+	pr, _ := NewStoredLexicons(pm)
+	l1, err := lexicon.New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}})
+	require.Nil(t, err)
+	l2, err := lexicon.New([]*api.LexiconEntry{{Grapheme: "Tesla", Accented: "Tesl{a/}"},
+		{Grapheme: "kasa", Accented: "k{a/}sa", Mi: "Nc"}})
+	require.Nil(t, err)
+	pm.On("Get", mock.Anything, []string{"l1", "l2"}).Return(lexicon.List{l1, l2}, nil)
+	d := &synthesizer.TTSDataPart{Cfg: &synthesizer.TTSConfig{Input: &api.TTSRequestConfig{Lexicons: []string{"l1", "l2"}}},
+		Words: []*synthesizer.ProcessedWord{
+			{Tagged: synthesizer.TaggedWord{Word: "Tesla"}},
+			{Tagged: synthesizer.TaggedWord{Word: "kasa", Mi: "Vgma3s--n--ni-"}},
+			{Tagged: synthesizer.TaggedWord{Word: "kasa", Mi: "Ncfsnn-"}},
+			{Tagged: synthesizer.TaggedWord{Word: "Tesla"}, UserAccent: 103},
+		}}
+	err = pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 202, d.Words[0].UserAccent)
+	assert.Equal(t, 0, d.Words[1].UserAccent)
+	assert.Equal(t, 202, d.Words[2].UserAccent)
+	assert.Equal(t, 103, d.Words[3].UserAccent)
+}
+
+func TestStoredLexicons_Skip(t *testing.T) {
+	pm := &mockLexiconProvider{}
+	pr, _ := NewStoredLexicons(pm)
+	d := &synthesizer.TTSDataPart{Cfg: &synthesizer.TTSConfig{JustAM: true, Input: &api.TTSRequestConfig{Lexicons: []string{"l1"}}},
+		Words: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "Tesla"}}}}
+	err := pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	d.Cfg = &synthesizer.TTSConfig{Input: &api.TTSRequestConfig{}}
+	err = pr.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	pm.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestStoredLexicons_Fail(t *testing.T) {
+	pm := &mockLexiconProvider{}
+	pr, _ := NewStoredLexicons(pm)
+	pm.On("Get", mock.Anything, mock.Anything).Return(nil, errors.New("olia"))
+	d := &synthesizer.TTSDataPart{Cfg: &synthesizer.TTSConfig{Input: &api.TTSRequestConfig{Lexicons: []string{"l1"}}},
+		Words: []*synthesizer.ProcessedWord{{Tagged: synthesizer.TaggedWord{Word: "Tesla"}}}}
+	err := pr.Process(context.TODO(), d)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,358 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/airenas/tts-line/internal/pkg/audio"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/airenas/tts-line/internal/pkg/wav"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type streamAudio struct {
+	adjustLoudness bool
+}
+
+// NewStreamAudio creates processor that prepares audio streaming of the finished parts,
+// it must be added before the part runner, the stream is finished by joinAudio
+func NewStreamAudio(adjustLoudness bool) synthesizer.Processor {
+	return &streamAudio{adjustLoudness: adjustLoudness}
+}
+
+func (p *streamAudio) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	if skipStream(data) {
+		return nil
+	}
+	data.PartListener = newAudioStreamer(data, p.adjustLoudness)
+	log.Ctx(ctx).Debug().Msg("Audio streaming enabled")
+	return nil
+}
+
+// Info return info about processor
+func (p *streamAudio) Info() string {
+	return fmt.Sprintf("streamAudio(%t)", p.adjustLoudness)
+}
+
+type streamSSMLAudio struct {
+	adjustLoudness bool
+}
+
+// NewStreamSSMLAudio creates processor that prepares audio streaming of the finished SSML parts,
+// it must be added before the SSML part runner, the stream is finished by joinSSMLAudio
+func NewStreamSSMLAudio(adjustLoudness bool) synthesizer.Processor {
+	return &streamSSMLAudio{adjustLoudness: adjustLoudness}
+}
+
+func (p *streamSSMLAudio) Process(ctx context.Context, data *synthesizer.TTSData) error {
+	if skipStream(data) {
+		return nil
+	}
+	st := newAudioStreamer(data, p.adjustLoudness)
+	data.PartListener = st
+	for _, dp := range data.SSMLParts {
+		if dp.Cfg.Type == synthesizer.SSMLText {
+			dp.PartListener = st
+		}
+	}
+	log.Ctx(ctx).Debug().Msg("SSML audio streaming enabled")
+	return nil
+}
+
+// Info return info about processor
+func (p *streamSSMLAudio) Info() string {
+	return fmt.Sprintf("streamSSMLAudio(%t)", p.adjustLoudness)
+}
+
+func skipStream(data *synthesizer.TTSData) bool {
+	return data.Input.AudioStream == nil || data.Input.OutputFormat == api.AudioNone
+}
+
+// streamed checks if the audio is written by the audioStreamer
+func streamed(data *synthesizer.TTSData) bool {
+	_, ok := data.PartListener.(*audioStreamer)
+	return ok
+}
+
+// audioStreamer writes parts to the stream in order as soon as they are synthesized.
+// It walks the same way as join/joinSSML, so the result audio is the same
+type audioStreamer struct {
+	lock sync.Mutex
+	// writeLock keeps the order of the chunks written outside of lock, it is taken before lock is released
+	writeLock sync.Mutex
+	writeErr  error // the first failed write, guarded by writeLock
+
+	data           *synthesizer.TTSData
+	out            io.Writer
+	adjustLoudness bool
+	loudnessTarget float64
+
+	res          *wavWriter
+	wwd, wwdNext *wordWriteData
+	volChanges   []*audio.VolChange // not applied yet
+	sent         int                // bytes of res.buf
+	headerSent   bool
+
+	done           map[*synthesizer.TTSDataPart]bool
+	ssmlAt, partAt int
+}
+
+func newAudioStreamer(data *synthesizer.TTSData, adjustLoudness bool) *audioStreamer {
+	return &audioStreamer{data: data, out: data.Input.AudioStream, adjustLoudness: adjustLoudness,
+		res: &wavWriter{maxEdgeSilenceMillis: data.Input.MaxEdgeSilenceMillis},
+		wwd: &wordWriteData{}, wwdNext: &wordWriteData{},
+		done: make(map[*synthesizer.TTSDataPart]bool)}
+}
+
+// PartDone writes all ready parts to the stream
+func (s *audioStreamer) PartDone(ctx context.Context, part *synthesizer.TTSDataPart) error {
+	ctx, span := utils.StartSpan(ctx, "audioStreamer.PartDone")
+	defer span.End()
+
+	s.lock.Lock()
+	s.done[part] = true
+	if err := s.writeParts(ctx, false); err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	chunks, err := s.flush(ctx, false)
+	if err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	return s.unlockAndWrite(chunks)
+}
+
+func (s *audioStreamer) finish(ctx context.Context, suffix []byte) (*synthesizer.AudioData, error) {
+	ctx, span := utils.StartSpan(ctx, "audioStreamer.finish")
+	defer span.End()
+
+	s.lock.Lock()
+	chunks, err := s.writeRest(ctx, suffix)
+	if err != nil {
+		s.lock.Unlock()
+		return nil, err
+	}
+	res := toAudioData(s.res, s.res.buf.Bytes())
+	if err := s.unlockAndWrite(chunks); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// writeRest writes all the parts and the suffix, returns the chunks to send
+func (s *audioStreamer) writeRest(ctx context.Context, suffix []byte) ([][]byte, error) {
+	if err := s.writeParts(ctx, true); err != nil {
+		return nil, err
+	}
+	if err := writeWordAudio(ctx, s.res, s.wwd, s.wwdNext); err != nil {
+		return nil, err
+	}
+	if s.wwd.word == nil && s.wwd.silence > 0 && s.res.buf.Len() > 0 { // a pause after the last <audio>
+		if err := appendPause(ctx, s.res, s.wwd.silence); err != nil {
+			return nil, err
+		}
+	}
+	if s.res.buf.Len() == 0 {
+		return nil, errors.New("no audio")
+	}
+	if suffix != nil {
+		if err := appendWav(ctx, s.res, suffix); err != nil {
+			return nil, errors.Wrapf(err, "can't append suffix")
+		}
+	}
+	return s.flush(ctx, true)
+}
+
+// writeParts writes the parts in order while they are done, or all of them if all is set
+func (s *audioStreamer) writeParts(ctx context.Context, all bool) error {
+	if s.data.Cfg.Type != synthesizer.SSMLMain {
+		for ; s.partAt < len(s.data.Parts) && (all || s.done[s.data.Parts[s.partAt]]); s.partAt++ {
+			if err := s.writePart(ctx, s.data.Parts[s.partAt], true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for s.ssmlAt < len(s.data.SSMLParts) {
+		dp := s.data.SSMLParts[s.ssmlAt]
+		switch dp.Cfg.Type {
+		case synthesizer.SSMLPause:
+			s.wwd.silence = s.wwd.silence + dp.Cfg.PauseDuration
+		case synthesizer.SSMLAudio:
+			if !s.initClipFormat(all) {
+				return nil
+			}
+			var err error
+			if s.wwd, err = writeClip(ctx, s.res, s.wwd, dp); err != nil {
+				return err
+			}
+		case synthesizer.SSMLText:
+			if s.partAt < len(dp.Parts) {
+				if !all && !s.done[dp.Parts[s.partAt]] {
+					return nil
+				}
+				if err := s.writePart(ctx, dp.Parts[s.partAt], false); err != nil {
+					return err
+				}
+				s.partAt++
+				continue
+			}
+			if !all && len(dp.Parts) == 0 { // not split yet
+				return nil
+			}
+		}
+		s.ssmlAt++
+		s.partAt = 0
+	}
+	return nil
+}
+
+// initClipFormat sets the format of the result before writing an <audio> clip.
+// The format is taken from the first synthesized part, returns false if the part is not ready yet
+func (s *audioStreamer) initClipFormat(all bool) bool {
+	if s.res.header != nil {
+		return true
+	}
+	for _, dp := range s.data.SSMLParts {
+		if dp.Cfg.Type != synthesizer.SSMLText {
+			continue
+		}
+		if len(dp.Parts) == 0 {
+			if all {
+				continue
+			}
+			return false
+		}
+		if !all && !s.done[dp.Parts[0]] {
+			return false
+		}
+		if wav.IsValid(dp.Parts[0].Audio) {
+			s.res.init(dp.Parts[0].Audio)
+		}
+		return true
+	}
+	return true // no synthesized text, the clip defines the format
+}
+
+func (s *audioStreamer) writePart(ctx context.Context, part *synthesizer.TTSDataPart, allowNoWords bool) error {
+	part.TranscribedSymbols = strings.Split(part.TranscribedText, " ")
+	if s.adjustLoudness {
+		if err := s.addLoudnessGain(ctx, part); err != nil {
+			return err
+		}
+	}
+	ar, err := initAudioReader(ctx, part)
+	if err != nil {
+		return err
+	}
+	res := s.res
+	if res.header == nil {
+		res.header = ar.audio.header
+		res.bitsPerSampleV = ar.audio.bitsPerSample
+		res.sampleRateV = ar.audio.sampleRate
+	}
+	lenBefore := res.buf.Len()
+	if len(part.Words) == 0 && allowNoWords {
+		_, err := res.buf.Write(ar.audio.data)
+		return err
+	}
+	for _, w := range part.Words {
+		s.wwdNext.audioReader = ar
+		s.wwdNext.word = w
+		if err := writeWordAudio(ctx, res, s.wwd, s.wwdNext); err != nil {
+			return err
+		}
+		s.wwd, s.wwdNext = s.wwdNext, &wordWriteData{}
+	}
+	s.volChanges = append(s.volChanges, makeVolumeChanges(ctx, part, lenBefore, res.buf.Len(), res.bytesPerSample())...)
+	return nil
+}
+
+// addLoudnessGain does the same as calcLoudness but in order of parts
+func (s *audioStreamer) addLoudnessGain(ctx context.Context, part *synthesizer.TTSDataPart) error {
+	loudness, err := calculateLoudness(ctx, part.Audio)
+	if err != nil {
+		return fmt.Errorf("calculate loudness: %w", err)
+	}
+	part.Loudness = loudness
+	if !hasNormalPAFSValue(loudness) {
+		return nil
+	}
+	if !hasNormalPAFSValue(s.loudnessTarget) {
+		s.loudnessTarget = loudness
+	}
+	part.LoudnessGain = s.loudnessTarget - loudness
+	return nil
+}
+
+// flush returns the copy of the audio that can't be changed anymore, it is sent by unlockAndWrite
+func (s *audioStreamer) flush(ctx context.Context, final bool) ([][]byte, error) {
+	if s.res.header == nil {
+		return nil, nil
+	}
+	limit := s.res.buf.Len()
+	if !final {
+		// volume may be changed for the audio that is not written yet
+		for _, vc := range s.volChanges {
+			if vc.To > s.res.buf.Len() {
+				limit = min(limit, vc.From)
+			}
+		}
+	}
+	var ready, pending []*audio.VolChange
+	for _, vc := range s.volChanges {
+		if vc.To <= limit {
+			ready = append(ready, vc)
+		} else {
+			pending = append(pending, vc)
+		}
+	}
+	s.volChanges = pending
+	if _, err := audio.ChangeVolume(ctx, s.res.buf.Bytes(), ready, int(s.res.bytesPerSample())); err != nil {
+		return nil, fmt.Errorf("change volume: %w", err)
+	}
+	var res [][]byte
+	if !s.headerSent {
+		header := bytes.Clone(s.res.header)
+		copy(header[4:8], wav.SizeBytes(streamDataSize)) // RIFF chunk size
+		res = append(res, header, []byte("data"), wav.SizeBytes(streamDataSize))
+		s.headerSent = true
+	}
+	if limit <= s.sent {
+		return res, nil
+	}
+	res = append(res, bytes.Clone(s.res.buf.Bytes()[s.sent:limit]))
+	log.Ctx(ctx).Debug().Int("from", s.sent).Int("to", limit).Msg("Streamed audio")
+	s.sent = limit
+	return res, nil
+}
+
+// streamDataSize is used as the size of the RIFF and data chunks as the real size is unknown
+const streamDataSize = 0xFFFFFFFF
+
+// unlockAndWrite releases lock and writes the chunks, so the synthesized parts are not blocked by a slow client.
+// writeLock is taken before the release, so the chunks are written in the order they were flushed
+func (s *audioStreamer) unlockAndWrite(chunks [][]byte) error {
+	s.writeLock.Lock()
+	s.lock.Unlock()
+	defer s.writeLock.Unlock()
+
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	for _, d := range chunks {
+		if _, err := s.out.Write(d); err != nil {
+			s.writeErr = fmt.Errorf("write stream: %w", err)
+			return s.writeErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/synthesizer"
+	"github.com/airenas/tts-line/internal/pkg/wav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAudio_Skip(t *testing.T) {
+	pr := NewStreamAudio(false)
+	d := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV}}
+	require.Nil(t, pr.Process(context.TODO(), &d))
+	assert.Nil(t, d.PartListener)
+
+	d = synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioNone, AudioStream: &bytes.Buffer{}}}
+	require.Nil(t, pr.Process(context.TODO(), &d))
+	assert.Nil(t, d.PartListener)
+}
+
+func TestStreamAudio_SameAsJoin(t *testing.T) {
+	initTestJoiner(t)
+	d := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1}}
+	d.Parts = newTestStreamParts(t)
+	require.Nil(t, NewJoinAudio(loaderMock).Process(context.TODO(), &d))
+
+	var buf bytes.Buffer
+	ds := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1, AudioStream: &buf}}
+	ds.Parts = newTestStreamParts(t)
+	require.Nil(t, NewStreamAudio(false).Process(context.TODO(), &ds))
+	require.NotNil(t, ds.PartListener)
+
+	require.Nil(t, ds.PartListener.PartDone(context.TODO(), ds.Parts[1]))
+	assert.Equal(t, 0, buf.Len())
+	require.Nil(t, ds.PartListener.PartDone(context.TODO(), ds.Parts[0]))
+	assert.Greater(t, buf.Len(), 0)
+	l := buf.Len()
+	require.Nil(t, NewJoinAudio(loaderMock).Process(context.TODO(), &ds))
+	assert.Greater(t, buf.Len(), l)
+
+	assert.Equal(t, d.Audio.Data, ds.Audio.Data)
+	assert.Equal(t, wav.TakeData(d.Audio.Data), buf.Bytes()[len(wav.TakeHeader(d.Audio.Data))+8:])
+	assert.Equal(t, wav.SizeBytes(streamDataSize), buf.Bytes()[len(wav.TakeHeader(d.Audio.Data))+4:len(wav.TakeHeader(d.Audio.Data))+8])
+}
+
+func TestStreamAudio_HeaderSizes(t *testing.T) {
+	initTestJoiner(t)
+	var buf bytes.Buffer
+	d := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1, AudioStream: &buf}}
+	d.Parts = newTestStreamParts(t)
+	require.Nil(t, NewStreamAudio(false).Process(context.TODO(), &d))
+	require.Nil(t, d.PartListener.PartDone(context.TODO(), d.Parts[0]))
+	require.Nil(t, d.PartListener.PartDone(context.TODO(), d.Parts[1]))
+	require.Nil(t, NewJoinAudio(loaderMock).Process(context.TODO(), &d))
+
+	header := wav.TakeHeader(d.Audio.Data)
+	got := buf.Bytes()
+	assert.Equal(t, []byte("RIFF"), got[:4])
+	assert.Equal(t, wav.SizeBytes(streamDataSize), got[4:8])
+	assert.Equal(t, header[8:], got[8:len(header)])
+	assert.Equal(t, wav.SizeBytes(streamDataSize), got[len(header)+4:len(header)+8])
+	assert.NotEqual(t, wav.SizeBytes(streamDataSize), d.Audio.Data[4:8], "joined audio keeps the real size")
+}
+
+func TestStreamSSMLAudio_SameAsJoin(t *testing.T) {
+	initTestJoiner(t)
+	newData := func(w *bytes.Buffer) *synthesizer.TTSData {
+		res := &synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1}}
+		if w != nil {
+			res.Input.AudioStream = w
+		}
+		res.Cfg.Type = synthesizer.SSMLMain
+		parts := newTestStreamParts(t)
+		res.SSMLParts = []*synthesizer.TTSData{
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLText}, Parts: parts[:1]},
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLPause, PauseDuration: time.Second}},
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLText}, Parts: parts[1:]},
+		}
+		return res
+	}
+	d := newData(nil)
+	require.Nil(t, NewJoinSSMLAudio(loaderMock).Process(context.TODO(), d))
+
+	var buf bytes.Buffer
+	ds := newData(&buf)
+	require.Nil(t, NewStreamSSMLAudio(false).Process(context.TODO(), ds))
+	require.NotNil(t, ds.SSMLParts[0].PartListener)
+	assert.Nil(t, ds.SSMLParts[1].PartListener)
+	require.Nil(t, ds.SSMLParts[0].PartListener.PartDone(context.TODO(), ds.SSMLParts[0].Parts[0]))
+	l := buf.Len()
+	assert.Greater(t, l, 0)
+	require.Nil(t, ds.SSMLParts[2].PartListener.PartDone(context.TODO(), ds.SSMLParts[2].Parts[0]))
+	assert.Greater(t, buf.Len(), l)
+	require.Nil(t, NewJoinSSMLAudio(loaderMock).Process(context.TODO(), ds))
+
+	assert.Equal(t, d.Audio.Data, ds.Audio.Data)
+	assert.Equal(t, wav.TakeData(d.Audio.Data), buf.Bytes()[len(wav.TakeHeader(d.Audio.Data))+8:])
+}
+
+func TestStreamSSMLAudio_Clip(t *testing.T) {
+	initTestJoiner(t)
+	clip := newTestClip(t, 22050, 16, 1, time.Second)
+	newData := func(w *bytes.Buffer) *synthesizer.TTSData {
+		res := &synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1}}
+		if w != nil {
+			res.Input.AudioStream = w
+		}
+		res.Cfg.Type = synthesizer.SSMLMain
+		parts := newTestStreamParts(t)
+		res.SSMLParts = []*synthesizer.TTSData{
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLAudio, AudioSrc: "jingle"}, AudioClip: clip},
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLText}, Parts: parts[:1]},
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLAudio, AudioSrc: "jingle"}, AudioClip: clip},
+			{Cfg: synthesizer.TTSConfig{Type: synthesizer.SSMLText}, Parts: parts[1:]},
+		}
+		return res
+	}
+	d := newData(nil)
+	require.Nil(t, NewJoinSSMLAudio(loaderMock).Process(context.TODO(), d))
+
+	var buf bytes.Buffer
+	ds := newData(&buf)
+	require.Nil(t, NewStreamSSMLAudio(false).Process(context.TODO(), ds))
+	require.Nil(t, ds.SSMLParts[3].PartListener.PartDone(context.TODO(), ds.SSMLParts[3].Parts[0]))
+	assert.Equal(t, 0, buf.Len())
+	require.Nil(t, ds.SSMLParts[1].PartListener.PartDone(context.TODO(), ds.SSMLParts[1].Parts[0]))
+	assert.Greater(t, buf.Len(), 0)
+	require.Nil(t, NewJoinSSMLAudio(loaderMock).Process(context.TODO(), ds))
+
+	assert.Equal(t, d.Audio.Data, ds.Audio.Data)
+	assert.Equal(t, wav.TakeData(d.Audio.Data), buf.Bytes()[len(wav.TakeHeader(d.Audio.Data))+8:])
+}
+
+func TestStreamAudio_WriteFail(t *testing.T) {
+	ds := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1,
+		AudioStream: &failWriter{}}}
+	ds.Parts = newTestStreamParts(t)
+	require.Nil(t, NewStreamAudio(false).Process(context.TODO(), &ds))
+	assert.NotNil(t, ds.PartListener.PartDone(context.TODO(), ds.Parts[0]))
+	assert.NotNil(t, ds.PartListener.PartDone(context.TODO(), ds.Parts[1]))
+}
+
+func TestStreamAudio_WriteOutsideLock(t *testing.T) {
+	w := &blockWriter{started: make(chan bool, 1), release: make(chan bool)}
+	ds := synthesizer.TTSData{Input: &api.TTSRequestConfig{OutputFormat: api.AudioWAV, MaxEdgeSilenceMillis: -1, AudioStream: w}}
+	ds.Parts = newTestStreamParts(t)
+	require.Nil(t, NewStreamAudio(false).Process(context.TODO(), &ds))
+	st := ds.PartListener.(*audioStreamer)
+
+	done := make(chan error, 1)
+	go func() { done <- st.PartDone(context.TODO(), ds.Parts[0]) }()
+	<-w.started
+	require.True(t, st.lock.TryLock(), "lock is held while writing")
+	st.lock.Unlock()
+	close(w.release)
+	assert.Nil(t, <-done)
+	assert.Greater(t, w.buf.Len(), 0)
+}
+
+func newTestStreamParts(t *testing.T) []*synthesizer.TTSDataPart {
+	t.Helper()
+	newPart := func() *synthesizer.TTSDataPart {
+		return &synthesizer.TTSDataPart{Audio: getTestEncAudio(t),
+			Words: []*synthesizer.ProcessedWord{
+				{Tagged: synthesizer.TaggedWord{Word: "olia"}, SynthesizedPos: &synthesizer.SynthesizedPos{From: 10, StartIndex: 1, To: 20}},
+				{Tagged: synthesizer.TaggedWord{Word: "opa"}, SynthesizedPos: &synthesizer.SynthesizedPos{From: 20, StartIndex: 3, To: 40}},
+			},
+			Durations:       []int{10, 10, 10, 10, 10, 10, 10, 10},
+			TranscribedText: "sil o l i a sp sil",
+			Step:            256,
+			DefaultSilence:  18,
+		}
+	}
+	return []*synthesizer.TTSDataPart{newPart(), newPart()}
+}
+
+type failWriter struct{}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("olia")
+}
+
+// blockWriter blocks the first write until release is closed
+type blockWriter struct {
+	buf     bytes.Buffer
+	started chan bool
+	release chan bool
+}
+
+func (w *blockWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- true:
+	default:
+	}
+	<-w.release
+	return w.buf.Write(p)
+}
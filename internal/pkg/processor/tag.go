@@ -3,11 +3,11 @@ package processor
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/accent"
-	accentI "github.com/airenas/tts-line/internal/pkg/accent"
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
 	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/airenas/tts-line/pkg/ssml"
@@ -166,7 +166,7 @@ func mapTagAccentResult(tags []*TaggedWord, text []string, textParts []*synthesi
 			}
 		}
 	}
-	// group words that are required to be spelled out, but where split by tagger
+	// group words that are required to be spelled out or have the <phoneme> transcription, but where split by tagger
 	finalRes := make([]*synthesizer.ProcessedWord, 0)
 	var lastTP *synthesizer.TTSTextPart
 	for i, w := range res {
@@ -184,12 +184,43 @@ func mapTagAccentResult(tags []*TaggedWord, text []string, textParts []*synthesi
 			lastTP = w.TextPart
 			w.Tagged.Word = w.TextPart.Text
 			w.Tagged.Separator = ""
+		} else if w.TextPart != nil && w.TextPart.Transcription != "" {
+			lastTP = w.TextPart
+			w.Tagged.Word = w.TextPart.Text
+			w.Tagged.Separator = ""
+			w.UserTranscription = w.TextPart.Transcription
 		} else {
 			lastTP = nil
 		}
 	}
 
-	return finalRes, nil
+	return addSentenceEnds(finalRes), nil
+}
+
+// addSentenceEnds adds the sentence end after the last word of a part closed by </s>
+// if the tagger has not found the end there
+func addSentenceEnds(words []*synthesizer.ProcessedWord) []*synthesizer.ProcessedWord {
+	res := make([]*synthesizer.ProcessedWord, 0, len(words))
+	insertAt, ended := -1, false
+	for i, w := range words {
+		tp := w.TextPart
+		if tp != nil && tp.SentenceEnd {
+			if w.Tagged.SentenceEnd {
+				ended = true
+			} else if !w.Tagged.Space {
+				insertAt, ended = len(res)+1, false
+			}
+		}
+		res = append(res, w)
+		if tp != nil && tp.SentenceEnd && (i == len(words)-1 || words[i+1].TextPart != tp) {
+			if insertAt >= 0 && !ended {
+				res = slices.Insert(res, insertAt, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{SentenceEnd: true},
+					TextPart: tp})
+			}
+			insertAt, ended = -1, false
+		}
+	}
+	return res
 }
 
 func moveText(rns []rune, pos int, tag *TaggedWord) (int, int, error) {
@@ -201,33 +232,21 @@ func moveText(rns []rune, pos int, tag *TaggedWord) (int, int, error) {
 	}
 	tr := []rune(tag.String)
 	if tag.Type == "WORD" {
-		i := pos
-		acc := 0
-		for _, r := range tr {
-			if i >= len(rns) {
-				return 0, 0, errors.Errorf("wrong word at '%s', wanted '%s'", string(rns[pos:min(pos+20, len(rns))]), tag.String)
-			}
-			if r == rns[i] {
-				i++
+		// each accent adds three runes to the word: {a\}
+		for k := 0; k <= len(tr) && pos+len(tr)+3*k <= len(rns); k++ {
+			w := string(rns[pos : pos+len(tr)+3*k])
+			if accent.ClearAccents(w) != tag.String {
 				continue
 			}
-			if rns[i] == '{' && i < len(rns)-3 {
-				at := accentI.Value(rns[i+2])
-				if r != rns[i+1] || at == 0 || rns[i+3] != '}' {
-					return 0, 0, errors.Errorf("wrong word at '%s'", string(rns[pos:min(pos+20, len(rns))]))
-				}
-				if acc != 0 {
-					return 0, 0, errors.Wrapf(
-						utils.NewErrBadAccent([]string{string(rns[pos:min(pos+20, len(rns))])}),
-						"only one accent is allowed")
-				}
-				acc = at*100 + i - pos + 1
-				i = i + 4
-			} else {
-				return 0, 0, errors.Errorf("wrong word at '%s'", string(rns[pos:min(pos+20, len(rns))]))
+			_, acc, err := accent.FromAccentString(w)
+			if err != nil {
+				return 0, 0, errors.Wrapf(
+					utils.NewErrBadAccent([]string{string(rns[pos:min(pos+20, len(rns))])}),
+					"only one accent is allowed")
 			}
+			return acc, len(tr) + 3*k, nil
 		}
-		return acc, i - pos, nil
+		return 0, 0, errors.Errorf("wrong word at '%s', wanted '%s'", string(rns[pos:min(pos+20, len(rns))]), tag.String)
 	}
 	return 0, len(tr), nil
 }
@@ -215,6 +215,42 @@ func TestMapAccent(t *testing.T) {
 	assert.Equal(t, "mama", p[1].TextPart.Accented)
 }
 
+func TestMapAccent_Phoneme(t *testing.T) {
+	tps := []*synthesizer.TTSTextPart{{Text: "Ką"}, {Text: "New York", Transcription: "n j u: - j \"o r k"}}
+	p, err := mapTagAccentResult([]*TaggedWord{{Type: "WORD", String: "Ką"}, {Type: "WORD", String: "New"},
+		{Type: "SPACE", String: " "}, {Type: "WORD", String: "York"}}, []string{"Ką", "New York"}, tps)
+	assert.Nil(t, err)
+	require.Equal(t, 2, len(p))
+	assert.Equal(t, "Ką", p[0].Tagged.Word)
+	assert.Equal(t, "", p[0].UserTranscription)
+	assert.Equal(t, "New York", p[1].Tagged.Word)
+	assert.Equal(t, "n j u: - j \"o r k", p[1].UserTranscription)
+	assert.Equal(t, tps[1], p[1].TextPart)
+}
+
+func TestMapAccent_SentenceEnd(t *testing.T) {
+	tps := []*synthesizer.TTSTextPart{{Text: "Dėkoju prof. ", SentenceEnd: true}, {Text: "Jonas. ", SentenceEnd: true}, {Text: "Labas"}}
+	p, err := mapTagAccentResult([]*TaggedWord{{Type: "WORD", String: "Dėkoju"}, {Type: "SPACE", String: " "},
+		{Type: "WORD", String: "prof"}, {Type: "SEPARATOR", String: "."}, {Type: "SPACE", String: " "},
+		{Type: "WORD", String: "Jonas"}, {Type: "SEPARATOR", String: "."}, {Type: "SENTENCE_END"}, {Type: "SPACE", String: " "},
+		{Type: "WORD", String: "Labas"}},
+		[]string{"Dėkoju prof. ", "Jonas. ", "Labas"}, tps)
+	require.Nil(t, err)
+	var got []string
+	for _, w := range p {
+		switch {
+		case w.Tagged.SentenceEnd:
+			got = append(got, "<end>")
+		case w.Tagged.Space:
+			got = append(got, "_")
+		default:
+			got = append(got, w.Tagged.Word+w.Tagged.Separator)
+		}
+	}
+	assert.Equal(t, []string{"Dėkoju", "_", "prof", ".", "<end>", "_", "Jonas", ".", "<end>", "_", "Labas"}, got)
+	assert.Equal(t, tps[0], p[4].TextPart)
+}
+
 func TestMapAccent_Fail(t *testing.T) {
 	_, err := mapTagAccentResult([]*TaggedWord{{Type: "WORD", String: "mama"}}, []string{" mam{a~}"}, nil)
 	assert.NotNil(t, err)
@@ -233,6 +269,41 @@ func TestMapAccent_ErrorType(t *testing.T) {
 	assert.False(t, errors.As(err, &errBA))
 }
 
+func Test_moveText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		pos       int
+		tag       TaggedWord
+		wantAcc   int
+		wantLen   int
+		wantErr   bool
+		wantBadAc bool
+	}{
+		{name: "plain", text: "mama olia", tag: TaggedWord{Type: "WORD", String: "mama"}, wantAcc: 0, wantLen: 4},
+		{name: "accented", text: "m{a~}ma olia", tag: TaggedWord{Type: "WORD", String: "mama"}, wantAcc: 302, wantLen: 7},
+		{name: "accented last", text: " mam{a/}", pos: 1, tag: TaggedWord{Type: "WORD", String: "mama"}, wantAcc: 204, wantLen: 7},
+		{name: "next word accent", text: "mama {o/}lia", tag: TaggedWord{Type: "WORD", String: "mama"}, wantAcc: 0, wantLen: 4},
+		{name: "separator", text: ",mama", tag: TaggedWord{Type: "SEPARATOR", String: ","}, wantAcc: 0, wantLen: 1},
+		{name: "sentence end", text: "mama", tag: TaggedWord{Type: "SENTENCE_END"}, wantAcc: 0, wantLen: 0},
+		{name: "mismatch", text: ",mam{a~}", tag: TaggedWord{Type: "WORD", String: "mama"}, wantErr: true},
+		{name: "mismatch accented", text: "t{e~}t{e~}", tag: TaggedWord{Type: "WORD", String: "mama"}, wantErr: true},
+		{name: "mismatch short", text: "ma", tag: TaggedWord{Type: "WORD", String: "mama"}, wantErr: true},
+		{name: "several accents", text: "m{a~}m{a~}", tag: TaggedWord{Type: "WORD", String: "mama"}, wantErr: true, wantBadAc: true},
+		{name: "wrong position", text: "mama", pos: 4, tag: TaggedWord{Type: "WORD", String: "mama"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc, l, err := moveText([]rune(tt.text), tt.pos, &tt.tag)
+			assert.Equal(t, tt.wantErr, err != nil, "err %v", err)
+			var errBA *utils.ErrBadAccent
+			assert.Equal(t, tt.wantBadAc, errors.As(err, &errBA))
+			assert.Equal(t, tt.wantAcc, acc)
+			assert.Equal(t, tt.wantLen, l)
+		})
+	}
+}
+
 func TestMapTag(t *testing.T) {
 	tests := []struct {
 		v TaggedWord
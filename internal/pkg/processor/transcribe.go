@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 	"unicode"
@@ -40,6 +41,7 @@ func (p *transcriber) Process(ctx context.Context, data *synthesizer.TTSDataPart
 
 	markLastEmphasisWord(data.Words)
 	markPauses(data.Words)
+	setUserTranscriptions(data.Words)
 
 	inData, err := mapTransInput(data)
 	if err != nil {
@@ -61,6 +63,11 @@ func (p *transcriber) Process(ctx context.Context, data *synthesizer.TTSDataPart
 	return nil
 }
 
+// Info return info about processor
+func (p *transcriber) Info() string {
+	return fmt.Sprintf("transcriber(%s)", utils.RetrieveInfo(p.httpWrap))
+}
+
 func markLastEmphasisWord(processedWord []*synthesizer.ProcessedWord) {
 	emphasisMap := make(map[int]*synthesizer.ProcessedWord)
 	for _, pw := range processedWord {
@@ -121,6 +128,8 @@ func mapTransInput(data *synthesizer.TTSDataPart) ([]*transInput, error) {
 			if !tgw.Space {
 				pr = nil
 			}
+		} else if isTranscribedByUser(w) {
+			pr = nil
 		} else {
 			ti := &transInput{}
 			tword := transWord(w)
@@ -160,6 +169,9 @@ func getSyllables(w *synthesizer.ProcessedWord) string {
 	if w.TextPart != nil && w.TextPart.Accented != "" && w.TextPart.Syllables != "" { // provided by user
 		return w.TextPart.Syllables
 	}
+	if w.UserSyllables != "" { // from the lexicon
+		return w.UserSyllables
+	}
 	if w.AccentVariant != nil {
 		return w.AccentVariant.Syll
 	}
@@ -207,7 +219,7 @@ func mapTransOutput(data *synthesizer.TTSDataPart, out []transOutput) error {
 	i := 0
 	for _, w := range data.Words {
 		tgw := w.Tagged
-		if tgw.IsWord() {
+		if tgw.IsWord() && !isTranscribedByUser(w) {
 			if len(out) <= i {
 				return errors.New("wrong transcribe result")
 			}
@@ -221,6 +233,19 @@ func mapTransOutput(data *synthesizer.TTSDataPart, out []transOutput) error {
 	return nil
 }
 
+// isTranscribedByUser checks if the word has the <phoneme> transcription, such a word is not sent to the transcriber
+func isTranscribedByUser(w *synthesizer.ProcessedWord) bool {
+	return w.TextPart != nil && w.TextPart.Transcription != ""
+}
+
+func setUserTranscriptions(words []*synthesizer.ProcessedWord) {
+	for _, w := range words {
+		if w.Tagged.IsWord() && isTranscribedByUser(w) {
+			w.Transcription = w.UserTranscription
+		}
+	}
+}
+
 func setTrans(w *synthesizer.ProcessedWord, out transOutput) error {
 	if out.Error != "" {
 		return errors.Errorf("transcriber error for '%s'('%s'): %s", transWord(w), out.Word, out.Error)
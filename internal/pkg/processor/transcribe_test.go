@@ -214,6 +214,32 @@ func TestMapTransInput_Space(t *testing.T) {
 	}
 }
 
+func TestMapTransInput_Phoneme(t *testing.T) {
+	d := newTestTTSDataPart()
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "word"},
+		AccentVariant: &synthesizer.AccentVariant{Accent: 103}})
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Space: true}})
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "Paris"}, UserTranscription: "p a r \"i s",
+		TextPart: &synthesizer.TTSTextPart{Text: "Paris", Transcription: "p a r \"i s"}})
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Space: true}})
+	d.Words = append(d.Words, &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "word1"},
+		AccentVariant: &synthesizer.AccentVariant{Accent: 103}})
+	inp, err := mapTransInput(d)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(inp))
+	assert.Equal(t, "word", inp[0].Word)
+	assert.Equal(t, "", inp[0].Rc)
+	assert.Equal(t, "word1", inp[1].Word)
+
+	setUserTranscriptions(d.Words)
+	err = mapTransOutput(d, []transOutput{{Word: "word", Transcription: []trans{{Transcription: "w o r d"}}},
+		{Word: "word1", Transcription: []trans{{Transcription: "w o r d 1"}}}})
+	require.Nil(t, err)
+	assert.Equal(t, "w o r d", d.Words[0].Transcription)
+	assert.Equal(t, "p a r \"i s", d.Words[2].Transcription)
+	assert.Equal(t, "w o r d 1", d.Words[4].Transcription)
+}
+
 func TestMapTransOutput(t *testing.T) {
 	d := newTestTTSDataPart()
 	d.Words = append(d.Words, &synthesizer.ProcessedWord{TranscriptionWord: "olia", Tagged: synthesizer.TaggedWord{Word: "v1"}})
@@ -354,6 +380,8 @@ func Test_getSyllables(t *testing.T) {
 			TextPart: &synthesizer.TTSTextPart{Accented: "aa"}, AccentVariant: &synthesizer.AccentVariant{Syll: "a-b"}}}, want: "a-b"},
 		{name: "prefers user's", args: args{w: &synthesizer.ProcessedWord{UserAccent: 302,
 			TextPart: &synthesizer.TTSTextPart{Accented: "aa", Syllables: "a-a"}, AccentVariant: &synthesizer.AccentVariant{Syll: "a-b"}}}, want: "a-a"},
+		{name: "from lexicon", args: args{w: &synthesizer.ProcessedWord{UserSyllables: "aa-b",
+			AccentVariant: &synthesizer.AccentVariant{Syll: "a-ab"}}}, want: "aa-b"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
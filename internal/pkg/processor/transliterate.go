@@ -135,10 +135,11 @@ func mapTransliteratorRes(output []*transliteratorOutput, s []*synthesizer.Proce
 				if change.Type == transliteratorTypeWord || (change.Type == "" && len(o.Changes) == 1) {
 					d := transcription.Parse(change.User)
 					nw := &synthesizer.ProcessedWord{
-						TextPart:          w.TextPart,
-						UserTranscription: d.Transcription,
-						UserSyllables:     d.Sylls,
-						TranscriptionWord: d.Word,
+						TextPart:           w.TextPart,
+						UserTranscription:  d.Transcription,
+						UserSyllables:      d.Sylls,
+						TranscriptionWord:  d.Word,
+						TransliteratedFrom: tw.Word,
 					}
 					nw.Tagged.Word = d.Word
 					nw.Tagged.Mi = tw.Mi
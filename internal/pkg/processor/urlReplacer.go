@@ -61,11 +61,11 @@ func (p *urlReplacer) Process(ctx context.Context, data *synthesizer.TTSData) er
 		return nil
 	}
 	defer goapp.Estimate("URL replace")()
-	var err error
-	data.Words, err = p.replaceURLs(ctx, data.Words)
+	words, err := p.replaceURLs(ctx, data.Words)
 	if err != nil {
 		return fmt.Errorf("replace URLs: %w", err)
 	}
+	data.Words = words
 	return nil
 }
 
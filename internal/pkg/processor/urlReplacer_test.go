@@ -7,6 +7,8 @@ import (
 
 	"github.com/airenas/tts-line/internal/pkg/synthesizer"
 	"github.com/airenas/tts-line/internal/pkg/test/mocks"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -87,6 +89,33 @@ func TestReplacerProcess(t *testing.T) {
 	}, d.Words)
 }
 
+func TestReplacerProcess_OptionalFail(t *testing.T) {
+	initTestJSON(t)
+	pr, err := NewURLReplacer("http://replacer.lt", "http://tagger.lt")
+	require.Nil(t, err)
+	urlReaderHTTPJSONMock = &mocks.HTTPInvokerJSON{}
+	pr.urlReaderHTTPWrap = urlReaderHTTPJSONMock
+	urlReaderHTTPJSONMock.On("InvokeJSON", mock.Anything, mock.Anything).Return(errors.New("olia"))
+
+	words := []*synthesizer.ProcessedWord{
+		{Tagged: synthesizer.TaggedWord{Word: "olia", Mi: "X-"}},
+		{Tagged: synthesizer.TaggedWord{Word: "www.delfi.lt", Mi: miLink}},
+	}
+	d := &synthesizer.TTSData{Words: words}
+	var got []*synthesizer.ProcessedWord
+	next := &procMock{f: func(ctx context.Context, d *synthesizer.TTSData) error {
+		got = d.Words
+		return nil
+	}}
+	s := &utils.SkippedStages{}
+	ctx := utils.WithSkippedStages(context.TODO(), s)
+	for _, p := range []synthesizer.Processor{NewOptional(pr, "urlReplacer", 0), next} {
+		require.Nil(t, p.Process(ctx, d))
+	}
+	assert.Equal(t, words, got)
+	assert.Equal(t, []string{"urlReplacer"}, s.Names())
+}
+
 func TestReplacerProcess_email(t *testing.T) {
 
 	lw := &synthesizer.ProcessedWord{Tagged: synthesizer.TaggedWord{Word: "a@elfi.lt", Mi: miEmail}}
@@ -35,6 +35,11 @@ func (p *validator) Process(ctx context.Context, data *synthesizer.TTSData) erro
 	return validate(getLen(data.Input.Text), getMaxLen(p.defaultMax, data.Input.AllowedMaxLen))
 }
 
+// Info return info about processor
+func (p *validator) Info() string {
+	return fmt.Sprintf("validator(%d)", p.defaultMax)
+}
+
 func getLen(s string) int {
 	return utf8.RuneCountInString(strings.TrimSpace(s))
 }
@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/service/api"
@@ -51,3 +52,8 @@ func (p *vocoder) Process(ctx context.Context, data *synthesizer.TTSDataPart) er
 	data.Audio = output.Data
 	return nil
 }
+
+// Info return info about processor
+func (p *vocoder) Info() string {
+	return fmt.Sprintf("vocoder(%s)", utils.RetrieveInfo(p.httpWrap))
+}
@@ -0,0 +1,174 @@
+package sayas
+
+import (
+	"fmt"
+	"strings"
+)
+
+var ones = []string{"nulis", "vienas", "du", "trys", "keturi", "penki", "šeši", "septyni", "aštuoni", "devyni"}
+
+var onesFeminine = []string{"nulis", "viena", "dvi", "trys", "keturios", "penkios", "šešios", "septynios", "aštuonios", "devynios"}
+
+var teens = []string{"dešimt", "vienuolika", "dvylika", "trylika", "keturiolika", "penkiolika", "šešiolika",
+	"septyniolika", "aštuoniolika", "devyniolika"}
+
+var tens = []string{"", "", "dvidešimt", "trisdešimt", "keturiasdešimt", "penkiasdešimt", "šešiasdešimt",
+	"septyniasdešimt", "aštuoniasdešimt", "devyniasdešimt"}
+
+// ordinal stems, the ending is added by ordinalForm
+var onesOrdinal = []string{"nult", "pirm", "antr", "treči", "ketvirt", "penkt", "šešt", "septint", "aštunt", "devint"}
+
+var teensOrdinal = []string{"dešimt", "vienuolikt", "dvylikt", "trylikt", "keturiolikt", "penkiolikt", "šešiolikt",
+	"septyniolikt", "aštuoniolikt", "devyniolikt"}
+
+// prefixes of the compound ordinals like 'dvišimtasis', 'dvitūkstantieji'
+var ordinalPrefixes = []string{"", "", "dvi", "tri", "keturi", "penki", "šeši", "septyni", "aštuoni", "devyni"}
+
+type scale struct {
+	value                int64
+	one, several, plural string
+	ordinal              string
+}
+
+var scales = []scale{
+	{value: 1_000_000_000, one: "milijardas", several: "milijardai", plural: "milijardų", ordinal: "milijard"},
+	{value: 1_000_000, one: "milijonas", several: "milijonai", plural: "milijonų", ordinal: "milijon"},
+	{value: 1_000, one: "tūkstantis", several: "tūkstančiai", plural: "tūkstančių", ordinal: "tūkstant"},
+}
+
+const maxNumber = 1_000_000_000_000 - 1
+
+// ordinalForm is the gender, case and definiteness of an ordinal
+type ordinalForm int
+
+const (
+	ordinalNomMasculine ordinalForm = iota
+	ordinalNomFeminineDef
+	ordinalNomPluralDef
+	ordinalGenPluralDef
+)
+
+var ordinalEndings = map[ordinalForm]string{ordinalNomMasculine: "as", ordinalNomFeminineDef: "oji",
+	ordinalNomPluralDef: "ieji", ordinalGenPluralDef: "ųjų"}
+
+func addEnding(stem string, form ordinalForm) string {
+	if form == ordinalNomPluralDef && strings.HasSuffix(stem, "či") {
+		return strings.TrimSuffix(stem, "či") + "t" + ordinalEndings[form] // trečias -> tretieji
+	}
+	return stem + ordinalEndings[form]
+}
+
+// cardinal returns the words of the number in the masculine nominative
+func cardinal(n int64) ([]string, error) {
+	return cardinalWords(n, ones)
+}
+
+// cardinalFeminine returns the words of the number in the feminine nominative
+func cardinalFeminine(n int64) ([]string, error) {
+	return cardinalWords(n, onesFeminine)
+}
+
+func cardinalWords(n int64, onesWords []string) ([]string, error) {
+	if n < 0 || n > maxNumber {
+		return nil, fmt.Errorf("number %d out of range", n)
+	}
+	if n == 0 {
+		return []string{onesWords[0]}, nil
+	}
+	var res []string
+	for _, s := range scales {
+		c := n / s.value
+		n %= s.value
+		if c == 0 {
+			continue
+		}
+		if c > 1 {
+			res = append(res, below1000(c, ones)...)
+		}
+		res = append(res, scaleWord(c, s))
+	}
+	return append(res, below1000(n, onesWords)...), nil
+}
+
+func scaleWord(c int64, s scale) string {
+	return unitWord(c, s.one, s.several, s.plural)
+}
+
+func below1000(n int64, onesWords []string) []string {
+	var res []string
+	if h := n / 100; h > 0 {
+		if h > 1 {
+			res = append(res, ones[h], "šimtai")
+		} else {
+			res = append(res, "šimtas")
+		}
+	}
+	n %= 100
+	switch {
+	case n >= 20:
+		res = append(res, tens[n/10])
+		if n%10 > 0 {
+			res = append(res, onesWords[n%10])
+		}
+	case n >= 10:
+		res = append(res, teens[n-10])
+	case n > 0:
+		res = append(res, onesWords[n])
+	}
+	return res
+}
+
+// ordinal returns the words of the ordinal number, only the last word is ordinal
+func ordinal(n int64, form ordinalForm) ([]string, error) {
+	if n < 0 || n > maxNumber {
+		return nil, fmt.Errorf("number %d out of range", n)
+	}
+	if n == 0 {
+		return []string{addEnding(onesOrdinal[0], form)}, nil
+	}
+	// the cardinal part before the last non zero group
+	var res []string
+	for _, s := range scales {
+		c := n / s.value
+		rest := n % s.value
+		if c == 0 {
+			continue
+		}
+		if rest == 0 {
+			return append(res, scaleOrdinal(c, s.ordinal, form)...), nil
+		}
+		if c > 1 {
+			res = append(res, below1000(c, ones)...)
+		}
+		res = append(res, scaleWord(c, s))
+		n = rest
+	}
+	if h := n / 100; h > 0 && n%100 == 0 {
+		return append(res, scaleOrdinal(h, "šimt", form)...), nil
+	} else if h > 0 {
+		res = append(res, below1000(h*100, ones)...)
+	}
+	n %= 100
+	switch {
+	case n >= 20 && n%10 == 0:
+		res = append(res, addEnding(tens[n/10], form))
+	case n >= 20:
+		res = append(res, tens[n/10], addEnding(onesOrdinal[n%10], form))
+	case n >= 10:
+		res = append(res, addEnding(teensOrdinal[n-10], form))
+	default:
+		res = append(res, addEnding(onesOrdinal[n], form))
+	}
+	return res, nil
+}
+
+// scaleOrdinal makes ordinals like 'šimtasis', 'dvišimtasis', 'dvidešimt tūkstantasis'
+func scaleOrdinal(c int64, stem string, form ordinalForm) []string {
+	if c == 1 {
+		return []string{addEnding(stem, form)}
+	}
+	if c < 10 {
+		return []string{ordinalPrefixes[c] + addEnding(stem, form)}
+	}
+	return append(below1000(c, ones), addEnding(stem, form))
+}
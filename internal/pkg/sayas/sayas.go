@@ -0,0 +1,322 @@
+package sayas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Supported interpret-as values of <say-as>
+const (
+	Cardinal  = "cardinal"
+	Ordinal   = "ordinal"
+	Digits    = "digits"
+	Telephone = "telephone"
+	Date      = "date"
+	Time      = "time"
+)
+
+// DefaultDateFormat is used if <say-as>:format is not set for a date
+const DefaultDateFormat = "ymd"
+
+var monthsNominative = []string{"sausis", "vasaris", "kovas", "balandis", "gegužė", "birželis", "liepa",
+	"rugpjūtis", "rugsėjis", "spalis", "lapkritis", "gruodis"}
+
+var monthsGenitive = []string{"sausio", "vasario", "kovo", "balandžio", "gegužės", "birželio", "liepos",
+	"rugpjūčio", "rugsėjo", "spalio", "lapkričio", "gruodžio"}
+
+// dateFormats are the allowed values of <say-as interpret-as="date" format="...">
+var dateFormats = map[string]bool{"ymd": true, "dmy": true, "mdy": true, "ydm": true, "ym": true, "my": true,
+	"md": true, "dm": true, "y": true, "m": true, "d": true}
+
+// IsSupported checks if the interpret-as value is handled by Say
+func IsSupported(interpretAs string) bool {
+	switch interpretAs {
+	case Cardinal, Ordinal, Digits, Telephone, Date, Time:
+		return true
+	}
+	return false
+}
+
+// CheckFormat validates the format attribute for the interpret-as value
+func CheckFormat(interpretAs, format string) error {
+	if format == "" {
+		return nil
+	}
+	if interpretAs != Date {
+		return fmt.Errorf("format is not supported for '%s'", interpretAs)
+	}
+	if !dateFormats[format] {
+		return fmt.Errorf("wrong date format '%s'", format)
+	}
+	return nil
+}
+
+// Say returns the Lithuanian words to be pronounced for the text
+func Say(interpretAs, format, text string) (string, error) {
+	var res []string
+	var err error
+	switch interpretAs {
+	case Cardinal:
+		res, err = sayCardinal(text)
+	case Ordinal:
+		res, err = sayOrdinal(text)
+	case Digits:
+		res, err = sayDigits(text)
+	case Telephone:
+		res, err = sayTelephone(text)
+	case Date:
+		if format == "" {
+			format = DefaultDateFormat
+		}
+		res, err = sayDate(text, format)
+	case Time:
+		res, err = sayTime(text)
+	default:
+		return "", fmt.Errorf("unsupported interpret-as '%s'", interpretAs)
+	}
+	if err != nil {
+		return "", fmt.Errorf("wrong %s '%s': %w", interpretAs, text, err)
+	}
+	return strings.Join(res, " "), nil
+}
+
+// sayCardinal reads an integer or a decimal number, the sign and the thousands separated by spaces are allowed
+func sayCardinal(text string) ([]string, error) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+	var res []string
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "−"):
+		res = append(res, "minus")
+		s = strings.TrimLeft(s, "-−")
+	case strings.HasPrefix(s, "+"):
+		res = append(res, "plius")
+		s = s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(strings.ReplaceAll(s, ".", ","), ",")
+	n, err := parseNumber(intPart)
+	if err != nil {
+		return nil, err
+	}
+	w, err := cardinal(n)
+	if err != nil {
+		return nil, err
+	}
+	res = append(res, w...)
+	if hasFrac {
+		if fracPart == "" || !allDigits(fracPart) {
+			return nil, fmt.Errorf("wrong fraction")
+		}
+		res = append(res, "kablelis")
+		res = append(res, readGroup(fracPart)...)
+	}
+	return res, nil
+}
+
+func sayOrdinal(text string) ([]string, error) {
+	// allow '5.', '5-as', '5-oji'
+	s, _, _ := strings.Cut(strings.TrimSpace(text), "-")
+	n, err := parseNumber(strings.TrimSuffix(s, "."))
+	if err != nil {
+		return nil, err
+	}
+	return ordinal(n, ordinalNomMasculine)
+}
+
+func sayDigits(text string) ([]string, error) {
+	var res []string
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			res = append(res, ones[r-'0'])
+		} else if !unicode.IsSpace(r) && !unicode.IsPunct(r) {
+			return nil, fmt.Errorf("not a digit '%c'", r)
+		}
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no digits")
+	}
+	return res, nil
+}
+
+// sayTelephone reads the groups of the number separated by commas.
+// Long groups are split into the groups of three or two digits, the groups starting with zero are read by digits
+func sayTelephone(text string) ([]string, error) {
+	s := strings.TrimSpace(text)
+	var res []string
+	if strings.HasPrefix(s, "+") {
+		res = append(res, "plius")
+		s = s[1:]
+	}
+	groups := strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '.' || r == '/' || r == '(' || r == ')'
+	})
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no digits")
+	}
+	var parts []string
+	for _, g := range groups {
+		if !allDigits(g) {
+			return nil, fmt.Errorf("not a digit in '%s'", g)
+		}
+		parts = append(parts, splitGroup(g)...)
+	}
+	for i, p := range parts {
+		w := readGroup(p)
+		if i < len(parts)-1 {
+			w[len(w)-1] += ","
+		}
+		res = append(res, w...)
+	}
+	return res, nil
+}
+
+func splitGroup(g string) []string {
+	var res []string
+	for len(g) > 4 {
+		res = append(res, g[:3])
+		g = g[3:]
+	}
+	if len(g) == 4 {
+		return append(res, g[:2], g[2:])
+	}
+	return append(res, g)
+}
+
+// readGroup reads the number or its digits if it starts with zero
+func readGroup(g string) []string {
+	if len(g) > 1 && g[0] == '0' {
+		res, _ := sayDigits(g)
+		return res
+	}
+	n, _ := strconv.ParseInt(g, 10, 64)
+	res, _ := cardinal(n)
+	return res
+}
+
+// sayDate reads the date as '<year> metų <month> <day>': the year is a genitive ordinal, the month is in genitive
+// and the day is a feminine ordinal (diena), e.g. 'du tūkstančiai dvidešimt ketvirtųjų metų kovo penkioliktoji'
+func sayDate(text, format string) ([]string, error) {
+	if !dateFormats[format] {
+		return nil, fmt.Errorf("wrong format '%s'", format)
+	}
+	values := strings.FieldsFunc(text, func(r rune) bool { return r < '0' || r > '9' })
+	if len(values) != len(format) {
+		return nil, fmt.Errorf("expected %d values for format '%s'", len(format), format)
+	}
+	var y, m, d int64 = -1, -1, -1
+	for i, f := range format {
+		n, err := parseNumber(values[i])
+		if err != nil {
+			return nil, err
+		}
+		switch f {
+		case 'y':
+			y = n
+		case 'm':
+			m = n
+		case 'd':
+			d = n
+		}
+	}
+	if m != -1 && (m < 1 || m > 12) {
+		return nil, fmt.Errorf("wrong month %d", m)
+	}
+	if d != -1 && (d < 1 || d > 31) {
+		return nil, fmt.Errorf("wrong day %d", d)
+	}
+	var res []string
+	if y != -1 {
+		if m == -1 && d == -1 {
+			w, err := ordinal(y, ordinalNomPluralDef)
+			if err != nil {
+				return nil, err
+			}
+			return append(w, "metai"), nil
+		}
+		w, err := ordinal(y, ordinalGenPluralDef)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, w...)
+		res = append(res, "metų")
+	}
+	if m != -1 {
+		if d == -1 {
+			res = append(res, monthsNominative[m-1])
+		} else {
+			res = append(res, monthsGenitive[m-1])
+		}
+	}
+	if d != -1 {
+		w, _ := ordinal(d, ordinalNomFeminineDef)
+		res = append(res, w...)
+	}
+	return res, nil
+}
+
+var timeUnits = []struct{ one, several, plural string }{
+	{one: "valanda", several: "valandos", plural: "valandų"},
+	{one: "minutė", several: "minutės", plural: "minučių"},
+	{one: "sekundė", several: "sekundės", plural: "sekundžių"},
+}
+
+// sayTime reads 'hh:mm[:ss]' as 'keturiolika valandų trisdešimt minučių', the zero minutes and seconds are skipped
+func sayTime(text string) ([]string, error) {
+	values := strings.FieldsFunc(strings.TrimSpace(text), func(r rune) bool { return r == ':' || r == '.' })
+	if len(values) < 2 || len(values) > 3 {
+		return nil, fmt.Errorf("expected hh:mm or hh:mm:ss")
+	}
+	var res []string
+	for i, v := range values {
+		n, err := parseNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		if (i == 0 && n > 24) || (i > 0 && n > 59) {
+			return nil, fmt.Errorf("wrong value %d", n)
+		}
+		if i > 0 && n == 0 {
+			continue
+		}
+		w, _ := cardinalFeminine(n)
+		res = append(res, w...)
+		res = append(res, unitWord(n, timeUnits[i].one, timeUnits[i].several, timeUnits[i].plural))
+	}
+	return res, nil
+}
+
+func unitWord(n int64, one, several, plural string) string {
+	switch {
+	case n%100 >= 10 && n%100 < 20, n%10 == 0:
+		return plural
+	case n%10 == 1:
+		return one
+	}
+	return several
+}
+
+func parseNumber(s string) (int64, error) {
+	if s == "" || !allDigits(s) {
+		return 0, fmt.Errorf("not a number '%s'", s)
+	}
+	res, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || res > maxNumber {
+		return 0, fmt.Errorf("number '%s' out of range", s)
+	}
+	return res, nil
+}
+
+func allDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package sayas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSay(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpretAs string
+		format      string
+		text        string
+		want        string
+		wantErr     bool
+	}{
+		{name: "cardinal", interpretAs: Cardinal, text: "0", want: "nulis"},
+		{name: "cardinal teen", interpretAs: Cardinal, text: "15", want: "penkiolika"},
+		{name: "cardinal", interpretAs: Cardinal, text: "121", want: "šimtas dvidešimt vienas"},
+		{name: "cardinal thousands", interpretAs: Cardinal, text: "1 234", want: "tūkstantis du šimtai trisdešimt keturi"},
+		{name: "cardinal plural", interpretAs: Cardinal, text: "15000", want: "penkiolika tūkstančių"},
+		{name: "cardinal several", interpretAs: Cardinal, text: "22000", want: "dvidešimt du tūkstančiai"},
+		{name: "cardinal million", interpretAs: Cardinal, text: "2001000", want: "du milijonai tūkstantis"},
+		{name: "cardinal minus", interpretAs: Cardinal, text: "-5", want: "minus penki"},
+		{name: "cardinal decimal", interpretAs: Cardinal, text: "3,05", want: "trys kablelis nulis penki"},
+		{name: "cardinal wrong", interpretAs: Cardinal, text: "3a", wantErr: true},
+		{name: "cardinal wrong fraction", interpretAs: Cardinal, text: "3,", wantErr: true},
+		{name: "ordinal", interpretAs: Ordinal, text: "3", want: "trečias"},
+		{name: "ordinal compound", interpretAs: Ordinal, text: "25.", want: "dvidešimt penktas"},
+		{name: "ordinal tens", interpretAs: Ordinal, text: "40-as", want: "keturiasdešimtas"},
+		{name: "ordinal hundreds", interpretAs: Ordinal, text: "200", want: "dvišimtas"},
+		{name: "ordinal thousands", interpretAs: Ordinal, text: "1112", want: "tūkstantis šimtas dvyliktas"},
+		{name: "ordinal wrong", interpretAs: Ordinal, text: "x", wantErr: true},
+		{name: "digits", interpretAs: Digits, text: "1204", want: "vienas du nulis keturi"},
+		{name: "digits wrong", interpretAs: Digits, text: "12a", wantErr: true},
+		{name: "telephone", interpretAs: Telephone, text: "+370 612 34567",
+			want: "plius trys šimtai septyniasdešimt, šeši šimtai dvylika, trys šimtai keturiasdešimt penki, šešiasdešimt septyni"},
+		{name: "telephone zero", interpretAs: Telephone, text: "8-612-0456", want: "aštuoni, šeši šimtai dvylika, nulis keturi, penkiasdešimt šeši"},
+		{name: "telephone wrong", interpretAs: Telephone, text: "8 61x", wantErr: true},
+		{name: "date", interpretAs: Date, format: "ymd", text: "2024-03-15",
+			want: "du tūkstančiai dvidešimt ketvirtųjų metų kovo penkioliktoji"},
+		{name: "date default", interpretAs: Date, text: "2023.01.03", want: "du tūkstančiai dvidešimt trečiųjų metų sausio trečioji"},
+		{name: "date dmy", interpretAs: Date, format: "dmy", text: "01/05/2000", want: "dvitūkstantųjų metų gegužės pirmoji"},
+		{name: "date year", interpretAs: Date, format: "y", text: "1990", want: "tūkstantis devyni šimtai devyniasdešimtieji metai"},
+		{name: "date year third", interpretAs: Date, format: "y", text: "2003", want: "du tūkstančiai tretieji metai"},
+		{name: "date month", interpretAs: Date, format: "ym", text: "2024-06", want: "du tūkstančiai dvidešimt ketvirtųjų metų birželis"},
+		{name: "date day month", interpretAs: Date, format: "md", text: "12-24", want: "gruodžio dvidešimt ketvirtoji"},
+		{name: "date wrong month", interpretAs: Date, format: "ymd", text: "2024-13-01", wantErr: true},
+		{name: "date wrong day", interpretAs: Date, format: "ymd", text: "2024-12-32", wantErr: true},
+		{name: "date wrong count", interpretAs: Date, format: "ymd", text: "2024-12", wantErr: true},
+		{name: "date wrong format", interpretAs: Date, format: "yy", text: "2024", wantErr: true},
+		{name: "time", interpretAs: Time, text: "14:30", want: "keturiolika valandų trisdešimt minučių"},
+		{name: "time one", interpretAs: Time, text: "21:01", want: "dvidešimt viena valanda viena minutė"},
+		{name: "time several", interpretAs: Time, text: "2:00:22", want: "dvi valandos dvidešimt dvi sekundės"},
+		{name: "time wrong", interpretAs: Time, text: "14:60", wantErr: true},
+		{name: "time no minutes", interpretAs: Time, text: "14", wantErr: true},
+		{name: "unknown", interpretAs: "currency", text: "14", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Say(tt.interpretAs, tt.format, tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	assert.NoError(t, CheckFormat(Date, ""))
+	assert.NoError(t, CheckFormat(Date, "dmy"))
+	assert.NoError(t, CheckFormat(Time, ""))
+	assert.Error(t, CheckFormat(Date, "ddd"))
+	assert.Error(t, CheckFormat(Time, "hms"))
+}
+
+func TestIsSupported(t *testing.T) {
+	assert.True(t, IsSupported(Telephone))
+	assert.False(t, IsSupported("characters"))
+}
@@ -1,7 +1,13 @@
 package api
 
+import "time"
+
 const (
-	SpeechMarkTypeWord = "word"
+	SpeechMarkTypeWord     = "word"
+	SpeechMarkTypeSentence = "sentence"
+	SpeechMarkTypeSSML     = "ssml"
+	SpeechMarkTypePhoneme  = "phoneme"
+	SpeechMarkTypeViseme   = "viseme"
 )
 
 type SymbolMode string
@@ -19,18 +25,46 @@ type Input struct {
 	//TextType may have values: text, ssml
 	TextType string `json:"textType,omitempty"`
 	//Possible values are m4a, mp3, wav, ulaw
-	OutputFormat     string  `json:"outputFormat,omitempty"`
+	OutputFormat string `json:"outputFormat,omitempty"`
+	//Possible values are none, normalized, accented, transcribed, vtt, srt, ssml
 	OutputTextFormat string  `json:"outputTextFormat,omitempty"`
 	AllowCollectData *bool   `json:"saveRequest,omitempty"`
 	Speed            float64 `json:"speed,omitempty"`
 	Voice            string  `json:"voice,omitempty"`
 	Priority         int     `json:"priority,omitempty"`
-	//Possible values are: word
+	//Possible values are: word, sentence, ssml, phoneme, viseme
 	SpeechMarkTypes      []string `json:"speechMarkTypes,omitempty"`
 	MaxEdgeSilenceMillis *int64   `json:"maxEdgeSilenceMillis,omitempty"`
 
 	SymbolMode      SymbolMode `json:"symbolMode,omitempty"`
 	SelectedSymbols []string   `json:"selectedSymbols,omitempty"`
+
+	//Lexicon overrides the pronunciation of the words
+	Lexicon []*LexiconEntry `json:"lexicon,omitempty"`
+	//Lexicons are the names of the stored lexicons, the first one is preferred
+	Lexicons []string `json:"lexicons,omitempty"`
+}
+
+// LexiconEntry is the user's pronunciation of a word
+type LexiconEntry struct {
+	Grapheme string `json:"grapheme"`
+	//Accented word, e.g. M{a/}ikrosoftas
+	Accented string `json:"accented,omitempty"`
+	//Syllables separated by '-', e.g. mai-kro-sof-tas
+	Syllables string `json:"syllables,omitempty"`
+	//Pronounced word with syllables and accent marks 3, 4 or 9, e.g. mai4-kro-sof-tas
+	Transcription string `json:"transcription,omitempty"`
+	//Lemma limits the entry to the word forms of the lemma
+	Lemma string `json:"lemma,omitempty"`
+	//Mi limits the entry to the words with the morphological info starting with the value, e.g. Ncf
+	Mi string `json:"mi,omitempty"`
+}
+
+// LexiconEntryRecord is a stored lexicon entry
+type LexiconEntryRecord struct {
+	ID string `json:"id"`
+	LexiconEntry
+	Updated time.Time `json:"updated"`
 }
 
 // SpeechMark
@@ -39,9 +73,13 @@ type SpeechMark struct {
 	TimeInMillis int64 `json:"timeMillis" msgpack:"timeMillis,omitempty"`
 	//In Millis
 	Duration int64 `json:"durationMillis,omitempty" msgpack:"duration,omitempty"`
-	//Possible values are: word
+	//Possible values are: word, sentence, ssml, phoneme, viseme
 	Type  string `json:"type,omitempty" msgpack:"type,omitempty"`
 	Value string `json:"value,omitempty" msgpack:"value,omitempty"`
+	//Character offsets of the marked text in the input, for SSML in the raw XML.
+	//Marks of phonemes and visemes point to the text of their word
+	Start int `json:"start" msgpack:"start"`
+	End   int `json:"end" msgpack:"end"`
 }
 
 // Result is synthesis result
@@ -52,9 +90,214 @@ type Result struct {
 	Text          string        `json:"text,omitempty" msgpack:"text,omitempty"`
 	RequestID     string        `json:"requestID,omitempty" msgpack:"requestID,omitempty"`
 	SpeechMarks   []*SpeechMark `json:"speechMarks,omitempty" msgpack:"speechMarks,omitempty"`
+	//SkippedStages are the failed optional pipeline stages, the result is degraded if not empty
+	SkippedStages []string `json:"skippedStages,omitempty" msgpack:"skippedStages,omitempty"`
+}
+
+// VoiceMetadata is a configured voice info
+type VoiceMetadata struct {
+	Language   string `json:"language,omitempty"`
+	Gender     string `json:"gender,omitempty"`
+	SampleRate int    `json:"sampleRate,omitempty"`
+	//Supports SSML prosody
+	Prosody bool `json:"prosody,omitempty"`
+	//Supports SSML emphasis
+	Emphasis bool `json:"emphasis,omitempty"`
+}
+
+// Voice is a public voice info
+type Voice struct {
+	Name string `json:"name"`
+	//Voice is the resolved voice name
+	Voice string `json:"voice"`
+	VoiceMetadata
+}
+
+// VoicesResult is a response for /voices request
+type VoicesResult struct {
+	Default string   `json:"default"`
+	Voices  []*Voice `json:"voices"`
+}
+
+// SSMLValidateResult is a response for /ssml/validate request
+type SSMLValidateResult struct {
+	Valid    bool         `json:"valid"`
+	Errors   []*SSMLIssue `json:"errors"`
+	Warnings []*SSMLIssue `json:"warnings"`
+}
+
+// SSMLIssue is an error or a warning of the SSML, Line and Column are 1-based
+type SSMLIssue struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Tag     string `json:"tag,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error codes
+const (
+	ErrCodeBadRequest       = "BAD_REQUEST"
+	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeInternal         = "INTERNAL_SERVER_ERROR"
+	ErrCodeRequestNotFound  = "REQUEST_NOT_FOUND"
+	ErrCodeNoText           = "NO_TEXT"
+	ErrCodeTextDoesNotMatch = "TEXT_DOES_NOT_MATCH"
+	ErrCodeBadAccent        = "BAD_ACCENT"
+	ErrCodeWordTooLong      = "WORD_TOO_LONG"
+	ErrCodeTextTooLong      = "TEXT_TOO_LONG"
+	ErrCodeBadSymbols       = "BAD_SYMBOLS"
+	ErrCodeBadAudio         = "BAD_AUDIO"
+	ErrCodeJobTimeout       = "JOB_TIMEOUT"
+	ErrCodeJobInterrupted   = "JOB_INTERRUPTED"
+)
+
+// Error is an error response
+type Error struct {
+	Code    string        `json:"code" msgpack:"code"`
+	Message string        `json:"message" msgpack:"message"`
+	Details *ErrorDetails `json:"details,omitempty" msgpack:"details,omitempty"`
+}
+
+// ErrorDetails contains the data of the failed input
+type ErrorDetails struct {
+	BadAccents []string `json:"badAccents,omitempty" msgpack:"badAccents,omitempty"`
+	Word       string   `json:"word,omitempty" msgpack:"word,omitempty"`
+	MaxLength  int      `json:"maxLength,omitempty" msgpack:"maxLength,omitempty"`
+	Length     int      `json:"length,omitempty" msgpack:"length,omitempty"`
+	//Original and Cleaned are set for the word with wrong symbols
+	Original string `json:"original,omitempty" msgpack:"original,omitempty"`
+	Cleaned  string `json:"cleaned,omitempty" msgpack:"cleaned,omitempty"`
+	//Audio is the key of the failed <audio> clip
+	Audio string `json:"audio,omitempty" msgpack:"audio,omitempty"`
 }
 
 // InfoResult is a response for /synthesizeInfo request
 type InfoResult struct {
 	Count int64 `json:"count"`
 }
+
+// AnalyzeResult is a response for /analyze request
+type AnalyzeResult struct {
+	Words []*AnalyzeWord `json:"words" msgpack:"words"`
+	//SkippedStages are the failed optional pipeline stages
+	SkippedStages []string `json:"skippedStages,omitempty" msgpack:"skippedStages,omitempty"`
+}
+
+// AnalyzeWord contains the results of the pipeline stages for one word
+type AnalyzeWord struct {
+	// Type is one of WORD, SEPARATOR, SPACE, SENTENCE_END
+	Type      string `json:"type" msgpack:"type"`
+	Word      string `json:"word,omitempty" msgpack:"word,omitempty"`
+	Separator string `json:"separator,omitempty" msgpack:"separator,omitempty"`
+	Mi        string `json:"mi,omitempty" msgpack:"mi,omitempty"`
+	Lemma     string `json:"lemma,omitempty" msgpack:"lemma,omitempty"`
+	// Part is the index of the synthesis part
+	Part int `json:"part" msgpack:"part"`
+	// Accent is the final accent passed to the transcriber
+	Accent             int              `json:"accent,omitempty" msgpack:"accent,omitempty"`
+	AccentVariant      *AccentVariant   `json:"accentVariant,omitempty" msgpack:"accentVariant,omitempty"`
+	AccentVariants     []*AccentVariant `json:"accentVariants,omitempty" msgpack:"accentVariants,omitempty"`
+	UserAccent         int              `json:"userAccent,omitempty" msgpack:"userAccent,omitempty"`
+	UserTranscription  string           `json:"userTranscription,omitempty" msgpack:"userTranscription,omitempty"`
+	UserSyllables      string           `json:"userSyllables,omitempty" msgpack:"userSyllables,omitempty"`
+	Clitic             *Clitic          `json:"clitic,omitempty" msgpack:"clitic,omitempty"`
+	NER                string           `json:"ner,omitempty" msgpack:"ner,omitempty"`
+	Obscene            bool             `json:"obscene,omitempty" msgpack:"obscene,omitempty"`
+	TransliteratedFrom string           `json:"transliteratedFrom,omitempty" msgpack:"transliteratedFrom,omitempty"`
+	// FromWord is the source word if the word was made by the URL reader
+	FromWord      string       `json:"fromWord,omitempty" msgpack:"fromWord,omitempty"`
+	Transcription string       `json:"transcription,omitempty" msgpack:"transcription,omitempty"`
+	TextPart      *AnalyzeText `json:"textPart,omitempty" msgpack:"textPart,omitempty"`
+}
+
+// AccentVariant is the accenter's result
+type AccentVariant struct {
+	Accent   int     `json:"accent" msgpack:"accent"`
+	Accented string  `json:"accented,omitempty" msgpack:"accented,omitempty"`
+	Ml       string  `json:"ml,omitempty" msgpack:"ml,omitempty"`
+	Syll     string  `json:"syll,omitempty" msgpack:"syll,omitempty"`
+	Usage    float64 `json:"usage" msgpack:"usage"`
+}
+
+// Clitic is the clitics detector's decision
+type Clitic struct {
+	// Type is NONE or CUSTOM
+	Type   string `json:"type" msgpack:"type"`
+	Accent int    `json:"accent,omitempty" msgpack:"accent,omitempty"`
+}
+
+// AnalyzeText is the input text part the word came from
+type AnalyzeText struct {
+	// SSMLPart is the index of the SSML part, it is 0 for plain text
+	SSMLPart    int    `json:"ssmlPart" msgpack:"ssmlPart"`
+	Text        string `json:"text" msgpack:"text"`
+	Accented    string `json:"accented,omitempty" msgpack:"accented,omitempty"`
+	Language    string `json:"language,omitempty" msgpack:"language,omitempty"`
+	InterpretAs string `json:"interpretAs,omitempty" msgpack:"interpretAs,omitempty"`
+}
+
+// Ready statuses
+const (
+	ReadyOK   = "OK"
+	ReadyFail = "FAIL"
+)
+
+// ReadyResult is a response for /ready request
+type ReadyResult struct {
+	Status       string              `json:"status"`
+	Dependencies []*DependencyStatus `json:"dependencies"`
+}
+
+// DependencyStatus is the probe result of one downstream service
+type DependencyStatus struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	LatencyMillis int64  `json:"latencyMillis"`
+	Error         string `json:"error,omitempty"`
+}
+
+// JobStatus is a state of the asynchronous synthesis job
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is a response for /synthesize/jobs requests
+type Job struct {
+	ID     string    `json:"id" msgpack:"id"`
+	Status JobStatus `json:"status" msgpack:"status"`
+	//In range [0, 1]
+	Progress float64   `json:"progress" msgpack:"progress"`
+	Created  time.Time `json:"created" msgpack:"created"`
+	Updated  time.Time `json:"updated" msgpack:"updated"`
+	Error    *Error    `json:"error,omitempty" msgpack:"error,omitempty"`
+	Result   *Result   `json:"result,omitempty" msgpack:"result,omitempty"`
+}
+
+// WSRequest is a client's message for /synthesize/ws
+type WSRequest struct {
+	//Possible values are: config, text, flush
+	Type string `json:"type"`
+	//Text fragment for the type 'text'
+	Text string `json:"text,omitempty"`
+	//Synthesis params for the type 'config', the text is ignored
+	Config *Input `json:"config,omitempty"`
+}
+
+// WSResponse is a server's JSON message for /synthesize/ws,
+// the message of the type 'sentence' is followed by a binary message with the audio
+type WSResponse struct {
+	//Possible values are: sentence, flushed, error
+	Type        string        `json:"type"`
+	Sentence    int           `json:"sentence,omitempty"`
+	Text        string        `json:"text,omitempty"`
+	SpeechMarks []*SpeechMark `json:"speechMarks,omitempty"`
+	Error       *Error        `json:"error,omitempty"`
+	//SkippedStages are the failed optional pipeline stages of the sentence
+	SkippedStages []string `json:"skippedStages,omitempty"`
+}
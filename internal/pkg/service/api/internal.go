@@ -3,6 +3,7 @@
 package api
 
 import (
+	"io"
 	"strconv"
 
 	"github.com/airenas/tts-line/pkg/ssml"
@@ -20,13 +21,19 @@ const (
 	TextAccented
 	//TextTranscribed output data that was sent to AM
 	TextTranscribed
+	//TextVTT output WebVTT subtitles
+	TextVTT
+	//TextSRT output SubRip subtitles
+	TextSRT
+	//TextSSML output SSML with the accents and syllables of every word
+	TextSSML
 )
 
 func (e TextFormatEnum) String() string {
-	if e < TextNone || e > TextAccented {
+	if e < TextNone || e > TextSSML {
 		return "TextFormatEnum:" + strconv.Itoa(int(e))
 	}
-	return [...]string{"", "normalized", "accented"}[e]
+	return [...]string{"", "normalized", "accented", "transcribed", "vtt", "srt", "ssml"}[e]
 }
 
 // AudioFormatEnum represent possible audio outputs
@@ -57,6 +64,10 @@ const (
 	ContentJSON
 	//ContentMsgPack value
 	ContentMsgPack
+	//ContentAudioStream value - chunked wav stream
+	ContentAudioStream
+	//ContentAudio value - raw audio bytes
+	ContentAudio
 )
 
 // TTSRequestConfig config for request
@@ -80,4 +91,9 @@ type TTSRequestConfig struct {
 
 	SymbolMode      SymbolMode
 	SelectedSymbols []string
+	Lexicon         []*LexiconEntry
+	Lexicons        []string // names of the stored lexicons
+
+	// AudioStream receives wav audio as soon as the parts are synthesized
+	AudioStream io.Writer
 }
@@ -11,6 +11,10 @@ func TestTextFormatEnumString(t *testing.T) {
 	assert.Equal(t, "", TextNone.String())
 	assert.Equal(t, "normalized", TextNormalized.String())
 	assert.Equal(t, "accented", TextAccented.String())
+	assert.Equal(t, "transcribed", TextTranscribed.String())
+	assert.Equal(t, "vtt", TextVTT.String())
+	assert.Equal(t, "srt", TextSRT.String())
+	assert.Equal(t, "ssml", TextSSML.String())
 	assert.Equal(t, "TextFormatEnum:100", TextFormatEnum(100).String())
 }
 
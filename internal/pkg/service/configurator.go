@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
 	"github.com/airenas/tts-line/internal/pkg/service/api"
 	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/airenas/tts-line/pkg/ssml"
@@ -24,16 +27,40 @@ const (
 	headerSaveTags      = "x-tts-save-tags"
 	headerMaxTextLen    = "x-tts-max-text-len"
 	headerAudioSuffix   = "x-tts-audio-suffix"
+	headerLexicons      = "x-tts-lexicons"
 
 	defaultVoiceKey = "default"
+	// maxVoiceAliasDepth is the max number of alias steps from a requested voice name to the model voice
+	maxVoiceAliasDepth = 4
+
+	paramStream  = "stream"
+	mimeAudioWAV = "audio/wav"
+
+	maxLexiconEntries = 1000
+	maxLexicons       = 10
 )
 
+// audioContentTypes maps Accept values to the raw audio formats
+var audioContentTypes = []struct {
+	mime   string
+	format api.AudioFormatEnum
+}{
+	{mime: "audio/mpeg", format: api.AudioMP3},
+	{mime: mimeAudioWAV, format: api.AudioWAV},
+	{mime: "audio/mp4", format: api.AudioM4A},
+	{mime: "audio/basic", format: api.AudioULAW},
+}
+
 // TTSConfigutaror tts request configuration
 type TTSConfigutaror struct {
 	defaultOutputFormat api.AudioFormatEnum
 	outputMetadata      []string
 	availableVoices     map[string]string
+	voicesMetadata      map[string]*api.VoiceMetadata
 	noSSML              bool
+	ssmlLanguages       map[string]bool // known <lang> values, not checked if empty
+	ssmlMaxChars        int             // max text length of /ssml/validate, not checked if 0
+	storedLexicons      bool
 }
 
 // NewTTSConfigurator creates the initial request configuration
@@ -60,7 +87,11 @@ func NewTTSConfigurator(cfg *viper.Viper) (*TTSConfigutaror, error) {
 	}
 	log.Info().Msgf("Metadata: %v", res.outputMetadata)
 
-	res.availableVoices, err = initVoices(cfg.GetStringSlice("output.voices"))
+	voices, metadata, err := readVoices(cfg.Get("output.voices"))
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read voices")
+	}
+	res.availableVoices, err = initVoices(voices)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't init voices")
 	}
@@ -69,9 +100,49 @@ func NewTTSConfigurator(cfg *viper.Viper) (*TTSConfigutaror, error) {
 		return nil, errors.Wrap(err, "no default voice")
 	}
 	log.Info().Msgf("Voices. Default: %s, all: %v", dVoice, res.availableVoices)
+	res.voicesMetadata = metadata
+	res.ssmlLanguages = make(map[string]bool)
+	for _, l := range cfg.GetStringSlice("ssml.languages") {
+		res.ssmlLanguages[strings.ToLower(strings.TrimSpace(l))] = true
+	}
 	return res, nil
 }
 
+// Voices returns all available voices
+func (c *TTSConfigutaror) Voices() *api.VoicesResult {
+	res := &api.VoicesResult{}
+	res.Default, _ = getVoice(c.availableVoices, defaultVoiceKey)
+	for k := range c.availableVoices {
+		if k == defaultVoiceKey {
+			continue
+		}
+		v, _ := getVoice(c.availableVoices, k)
+		voice := &api.Voice{Name: k, Voice: v}
+		if m := getVoiceMetadata(c.availableVoices, c.voicesMetadata, k); m != nil {
+			voice.VoiceMetadata = *m
+		}
+		res.Voices = append(res.Voices, voice)
+	}
+	sort.Slice(res.Voices, func(i, j int) bool { return res.Voices[i].Name < res.Voices[j].Name })
+	return res
+}
+
+// getVoiceMetadata returns metadata of the first voice in the alias chain that has it
+func getVoiceMetadata(voices map[string]string, metadata map[string]*api.VoiceMetadata, voiceKey string) *api.VoiceMetadata {
+	key := voiceKey
+	for i := 0; i <= maxVoiceAliasDepth; i++ {
+		if res, ok := metadata[key]; ok {
+			return res
+		}
+		next, ok := voices[key]
+		if !ok || next == key {
+			break
+		}
+		key = next
+	}
+	return nil
+}
+
 // NewTTSConfiguratorNoSSML creates the initial request configuration with no SSML allowed
 func NewTTSConfiguratorNoSSML(cfg *viper.Viper) (*TTSConfigutaror, error) {
 	res, err := NewTTSConfigurator(cfg)
@@ -82,6 +153,16 @@ func NewTTSConfiguratorNoSSML(cfg *viper.Viper) (*TTSConfigutaror, error) {
 	return res, nil
 }
 
+// EnableStoredLexicons allows the stored lexicons in the request, they are rejected otherwise
+func (c *TTSConfigutaror) EnableStoredLexicons() {
+	c.storedLexicons = true
+}
+
+// SetSSMLMaxChars sets the max length of the text checked by ValidateSSML, it is the limit of the synthesis validator
+func (c *TTSConfigutaror) SetSSMLMaxChars(maxChars int) {
+	c.ssmlMaxChars = maxChars
+}
+
 func getVoice(voices map[string]string, voiceKey string) (string, error) {
 	key := voiceKey
 	if key == "" {
@@ -93,7 +174,7 @@ func getVoice(voices map[string]string, voiceKey string) (string, error) {
 	}
 	//try go deeper
 	// allow mapping default:astra.latest, astra.latest:astra.v02
-	for i := 0; i < 3; i++ {
+	for i := 1; i < maxVoiceAliasDepth; i++ {
 		resN, ok := voices[res]
 		if !ok {
 			break
@@ -112,10 +193,30 @@ func (c *TTSConfigutaror) Configure(ctx context.Context, r *http.Request, inText
 	if err != nil {
 		return nil, err
 	}
-	res.OutputContentType, err = getOutputContentType(ctx, getHeader(r, echo.HeaderAccept))
+	stream, err := getStream(r.URL.Query().Get(paramStream))
 	if err != nil {
 		return nil, err
 	}
+	if stream {
+		res.OutputContentType, err = getStreamContentType(getHeader(r, echo.HeaderAccept))
+		if err != nil {
+			return nil, err
+		}
+		if res.OutputFormat != api.AudioDefault && res.OutputFormat != api.AudioWAV {
+			return nil, errors.New("stream supports only wav output")
+		}
+		res.OutputFormat = api.AudioWAV
+	} else if af, ok := getAudioContentFormat(getHeader(r, echo.HeaderAccept)); ok {
+		if inF, _ := getOutputAudioFormat(inText.OutputFormat); inF != api.AudioDefault && inF != af {
+			return nil, errors.Errorf("outputFormat '%s' does not match 'Accept: %s'", inText.OutputFormat, getAudioMIME(af))
+		}
+		res.OutputContentType, res.OutputFormat = api.ContentAudio, af
+	} else {
+		res.OutputContentType, err = getOutputContentType(ctx, getHeader(r, echo.HeaderAccept))
+		if err != nil {
+			return nil, err
+		}
+	}
 	if res.OutputFormat == api.AudioDefault {
 		res.OutputFormat = c.defaultOutputFormat
 	}
@@ -151,6 +252,15 @@ func (c *TTSConfigutaror) Configure(ctx context.Context, r *http.Request, inText
 		return nil, err
 	}
 	log.Ctx(ctx).Info().Int64("edgeSil", res.MaxEdgeSilenceMillis).Any("speechMarks", res.SpeechMarkTypes).Send()
+	if stream && (len(res.SpeechMarkTypes) > 0 || res.OutputTextFormat != api.TextNone) {
+		return nil, errors.New("stream does not support speech marks or text output")
+	}
+	if res.OutputContentType == api.ContentAudio && len(res.SpeechMarkTypes) > 0 {
+		return nil, errors.New("audio response does not support speech marks")
+	}
+	if (res.OutputTextFormat == api.TextVTT || res.OutputTextFormat == api.TextSRT) && res.OutputFormat == api.AudioNone {
+		return nil, errors.Errorf("outputTextFormat '%s' requires audio", res.OutputTextFormat.String())
+	}
 	if inText.Priority < 0 {
 		return nil, errors.Errorf("wrong priority (>=0) value: %d", inText.Priority)
 	}
@@ -164,6 +274,17 @@ func (c *TTSConfigutaror) Configure(ctx context.Context, r *http.Request, inText
 		return nil, err
 	}
 	res.SelectedSymbols = inText.SelectedSymbols
+	res.Lexicon, err = getLexicon(inText.Lexicon)
+	if err != nil {
+		return nil, err
+	}
+	res.Lexicons, err = getLexicons(inText.Lexicons, getHeader(r, headerLexicons))
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Lexicons) > 0 && !c.storedLexicons {
+		return nil, errors.New("stored lexicons are not enabled")
+	}
 
 	if strings.HasPrefix(res.Text, "<speak") || inText.TextType == "ssml" {
 		if c.noSSML {
@@ -181,6 +302,65 @@ func (c *TTSConfigutaror) Configure(ctx context.Context, r *http.Request, inText
 	return res, nil
 }
 
+// ValidateSSML returns all errors and warnings of the SSML text
+func (c *TTSConfigutaror) ValidateSSML(text, voice string) *api.SSMLValidateResult {
+	var knownLanguage func(string) bool
+	if len(c.ssmlLanguages) > 0 {
+		knownLanguage = func(s string) bool { return c.ssmlLanguages[s] }
+	}
+	vr := ssml.Validate(strings.NewReader(text), &ssml.Text{Voice: voice},
+		func(s string) (string, error) {
+			return getVoice(c.availableVoices, s)
+		}, knownLanguage)
+	errs := mapSSMLIssues(vr.Errors)
+	if c.ssmlMaxChars > 0 && vr.TextLen > c.ssmlMaxChars {
+		errs = append(errs, &api.SSMLIssue{Code: ssml.CodeTextTooLong, Message: utils.NewErrTextTooLong(vr.TextLen, c.ssmlMaxChars).Error()})
+	}
+	return &api.SSMLValidateResult{Valid: len(errs) == 0, Errors: errs, Warnings: mapSSMLIssues(vr.Warnings)}
+}
+
+func mapSSMLIssues(issues []*ssml.Issue) []*api.SSMLIssue {
+	res := make([]*api.SSMLIssue, 0, len(issues))
+	for _, i := range issues {
+		res = append(res, &api.SSMLIssue{Line: i.Line, Column: i.Column, Tag: i.Tag, Code: i.Code, Message: i.Msg})
+	}
+	return res
+}
+
+// getLexicon validates the entries, they are parsed again by the lexicon processor
+func getLexicon(entries []*api.LexiconEntry) ([]*api.LexiconEntry, error) {
+	if len(entries) > maxLexiconEntries {
+		return nil, errors.Errorf("too many lexicon entries %d, max %d", len(entries), maxLexiconEntries)
+	}
+	if _, err := lexicon.New(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// getLexicons returns the names of the stored lexicons, the input value overrides the header
+func getLexicons(names []string, header string) ([]string, error) {
+	if len(names) == 0 && strings.TrimSpace(header) != "" {
+		names = strings.Split(header, ",")
+	}
+	var res []string
+	used := map[string]bool{}
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if !IsLexiconName(n) {
+			return nil, errors.Errorf("wrong lexicon name '%s'", n)
+		}
+		if !used[n] {
+			used[n] = true
+			res = append(res, n)
+		}
+	}
+	if len(res) > maxLexicons {
+		return nil, errors.Errorf("too many lexicons %d, max %d", len(res), maxLexicons)
+	}
+	return res, nil
+}
+
 func getSymbolMode(symbolMode api.SymbolMode) (api.SymbolMode, error) {
 	for _, m := range [...]api.SymbolMode{api.SymbolModeNone, api.SymbolModeRead, api.SymbolModeReadSelected, api.SymbolModeReadAll} {
 		if symbolMode == m {
@@ -200,10 +380,13 @@ func getMaxEdgeSilence(value *int64) (int64, error) {
 	return *value, nil
 }
 
+var speechMarkTypes = map[string]bool{api.SpeechMarkTypeWord: true, api.SpeechMarkTypeSentence: true,
+	api.SpeechMarkTypeSSML: true, api.SpeechMarkTypePhoneme: true, api.SpeechMarkTypeViseme: true}
+
 func getSpeechMarkTypes(s []string) (map[string]bool, error) {
 	res := make(map[string]bool)
 	for _, v := range s {
-		if v != api.SpeechMarkTypeWord {
+		if !speechMarkTypes[v] {
 			return nil, errors.Errorf("Unknown speech mark type '%s'", v)
 		}
 		res[v] = true
@@ -225,6 +408,15 @@ func getOutputTextFormat(s string) (api.TextFormatEnum, error) {
 	if st == "transcribed" {
 		return api.TextTranscribed, nil
 	}
+	if st == "vtt" {
+		return api.TextVTT, nil
+	}
+	if st == "srt" {
+		return api.TextSRT, nil
+	}
+	if st == "ssml" {
+		return api.TextSSML, nil
+	}
 	return api.TextNone, errors.New("Unknown text format " + s)
 }
 
@@ -283,6 +475,109 @@ func getOutputContentType(ctx context.Context, s string) (api.OutputContentTypeE
 	return api.ContentJSON, nil
 }
 
+// getAudioContentFormat returns the raw audio format if the first known Accept value is audio
+func getAudioContentFormat(s string) (api.AudioFormatEnum, bool) {
+	for _, v := range strings.Split(s, ",") {
+		v, _, _ = strings.Cut(v, ";")
+		v = strings.TrimSpace(v)
+		if v == echo.MIMEApplicationJSON || v == echo.MIMEApplicationMsgpack {
+			return api.AudioNone, false
+		}
+		for _, ac := range audioContentTypes {
+			if v == ac.mime {
+				return ac.format, true
+			}
+		}
+	}
+	return api.AudioNone, false
+}
+
+func getAudioMIME(f api.AudioFormatEnum) string {
+	for _, ac := range audioContentTypes {
+		if f == ac.format {
+			return ac.mime
+		}
+	}
+	return echo.MIMEOctetStream
+}
+
+func getStream(s string) (bool, error) {
+	st := strings.TrimSpace(s)
+	if st == "" {
+		return false, nil
+	}
+	res, err := strconv.ParseBool(st)
+	if err != nil {
+		return false, errors.Errorf("wrong stream value '%s'", s)
+	}
+	return res, nil
+}
+
+func getStreamContentType(s string) (api.OutputContentTypeEnum, error) {
+	if f, ok := getAudioContentFormat(s); !ok || f != api.AudioWAV {
+		return api.ContentUnspecified, errors.Errorf("stream requires 'Accept: %s'", mimeAudioWAV)
+	}
+	return api.ContentAudioStream, nil
+}
+
+// voiceConfig is a voice config with metadata, name is mapped to the voice
+type voiceConfig struct {
+	Name  string `json:"name"`
+	Voice string `json:"voice"`
+	api.VoiceMetadata
+}
+
+// readVoices reads voices configured as 'key:voice' strings or as voiceConfig
+func readVoices(v interface{}) ([]string, map[string]*api.VoiceMetadata, error) {
+	metadata := make(map[string]*api.VoiceMetadata)
+	switch vt := v.(type) {
+	case nil:
+		return nil, metadata, nil
+	case string:
+		return strings.Fields(vt), metadata, nil
+	case []string:
+		return vt, metadata, nil
+	case []interface{}:
+		var res []string
+		for _, item := range vt {
+			switch it := item.(type) {
+			case string:
+				res = append(res, it)
+			case map[string]interface{}:
+				vc, err := toVoiceConfig(it)
+				if err != nil {
+					return nil, nil, err
+				}
+				res = append(res, vc.Name+":"+defaultS(vc.Voice, vc.Name))
+				metadata[vc.Name] = &vc.VoiceMetadata
+				if _, ok := metadata[vc.Voice]; !ok && vc.Voice != "" {
+					metadata[vc.Voice] = &vc.VoiceMetadata
+				}
+			default:
+				return nil, nil, errors.Errorf("wrong voice value '%v'", item)
+			}
+		}
+		return res, metadata, nil
+	}
+	return nil, nil, errors.Errorf("wrong voices value '%v'", v)
+}
+
+func toVoiceConfig(v map[string]interface{}) (*voiceConfig, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't marshal voice")
+	}
+	res := &voiceConfig{}
+	if err := json.Unmarshal(b, res); err != nil {
+		return nil, errors.Wrapf(err, "wrong voice value '%v'", v)
+	}
+	res.Name = strings.TrimSpace(res.Name)
+	if res.Name == "" {
+		return nil, errors.Errorf("no name for voice '%v'", v)
+	}
+	return res, nil
+}
+
 func initVoices(all []string) (map[string]string, error) {
 	res := make(map[string]string)
 	for _, s := range all {
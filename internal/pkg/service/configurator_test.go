@@ -42,6 +42,57 @@ func TestNewTTSConfigurator_SeveralVoices(t *testing.T) {
 	}
 }
 
+func TestNewTTSConfigurator_VoicesMetadata(t *testing.T) {
+	c, err := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:v1\n"+
+		"   - name: v1\n     voice: v1.01\n     language: lt\n     gender: female\n     sampleRate: 22050\n     prosody: true\n"+
+		"   - name: v2\n     emphasis: true\n   - v3:v3.01"))
+	require.Nil(t, err)
+	assert.Equal(t, "v1.01", c.availableVoices["v1"])
+	assert.Equal(t, "v2", c.availableVoices["v2"])
+	res := c.Voices()
+	assert.Equal(t, "v1.01", res.Default)
+	md := api.VoiceMetadata{Language: "lt", Gender: "female", SampleRate: 22050, Prosody: true}
+	assert.Equal(t, []*api.Voice{
+		{Name: "v1", Voice: "v1.01", VoiceMetadata: md},
+		{Name: "v1.01", Voice: "v1.01", VoiceMetadata: md},
+		{Name: "v2", Voice: "v2", VoiceMetadata: api.VoiceMetadata{Emphasis: true}},
+		{Name: "v3", Voice: "v3.01"},
+		{Name: "v3.01", Voice: "v3.01"},
+	}, res.Voices)
+}
+
+func Test_readVoices(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    []string
+		wantMD  map[string]*api.VoiceMetadata
+		wantErr bool
+	}{
+		{name: "nil", v: nil, want: nil, wantMD: map[string]*api.VoiceMetadata{}},
+		{name: "string", v: "default:v1 v1:v2", want: []string{"default:v1", "v1:v2"}, wantMD: map[string]*api.VoiceMetadata{}},
+		{name: "strings", v: []string{"default:v1"}, want: []string{"default:v1"}, wantMD: map[string]*api.VoiceMetadata{}},
+		{name: "map", v: []interface{}{"default:v1", map[string]interface{}{"name": "v1", "language": "lt"}},
+			want: []string{"default:v1", "v1:v1"}, wantMD: map[string]*api.VoiceMetadata{"v1": {Language: "lt"}}},
+		{name: "lowercased keys", v: []interface{}{map[string]interface{}{"name": "v1", "voice": "v2", "samplerate": 16000}},
+			want: []string{"v1:v2"}, wantMD: map[string]*api.VoiceMetadata{"v1": {SampleRate: 16000}, "v2": {SampleRate: 16000}}},
+		{name: "no name", v: []interface{}{map[string]interface{}{"voice": "v1"}}, wantErr: true},
+		{name: "wrong type", v: []interface{}{map[string]interface{}{"name": "v1", "sampleRate": "olia"}}, wantErr: true},
+		{name: "wrong item", v: []interface{}{10}, wantErr: true},
+		{name: "wrong", v: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotMD, err := readVoices(tt.v)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+			if !tt.wantErr {
+				assert.Equal(t, tt.wantMD, gotMD)
+			}
+		})
+	}
+}
+
 func TestNewTTSConfigurator_Fail(t *testing.T) {
 	_, err := NewTTSConfigurator(test.NewConfig(t, ""))
 	assert.NotNil(t, err)
@@ -84,6 +135,54 @@ func TestConfigure_Format(t *testing.T) {
 	}
 }
 
+func TestConfigure_Lexicon(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+	lex := []*api.LexiconEntry{{Grapheme: "Tesla", Accented: "T{e/}sla"}}
+	res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicon: lex})
+	assert.Nil(t, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, lex, res.Lexicon)
+	}
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicon: []*api.LexiconEntry{{Grapheme: "Tesla"}}})
+	assert.NotNil(t, err)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicon: make([]*api.LexiconEntry, maxLexiconEntries+1)})
+	assert.ErrorContains(t, err, "too many")
+}
+
+func TestConfigure_Lexicons(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	c.EnableStoredLexicons()
+	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+	res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia"})
+	assert.Nil(t, err)
+	assert.Nil(t, res.Lexicons)
+	req.Header.Add(headerLexicons, "l1, l2,l1")
+	res, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"l1", "l2"}, res.Lexicons)
+	res, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicons: []string{"in.1"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"in.1"}, res.Lexicons)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicons: []string{"l/1"}})
+	assert.NotNil(t, err)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicons: []string{""}})
+	assert.NotNil(t, err)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia",
+		Lexicons: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}})
+	assert.ErrorContains(t, err, "too many")
+}
+
+func TestConfigure_LexiconsDisabled(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+	_, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", Lexicons: []string{"l1"}})
+	assert.ErrorContains(t, err, "not enabled")
+	req.Header.Add(headerLexicons, "l1")
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia"})
+	assert.ErrorContains(t, err, "not enabled")
+}
+
 func TestConfigure_FormatHeader(t *testing.T) {
 	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  metadata:\n   - r=a\n  voices:\n   - default:aaa"))
 	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
@@ -125,6 +224,111 @@ func TestConfigure_OutputContentType__msgPack(t *testing.T) {
 	assert.Equal(t, api.ContentMsgPack, res.OutputContentType)
 }
 
+func TestConfigure_OutputContentType_Audio(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	tests := []struct {
+		accept string
+		format string
+		want   api.AudioFormatEnum
+	}{
+		{accept: "audio/mpeg", want: api.AudioMP3},
+		{accept: "audio/wav", want: api.AudioWAV},
+		{accept: "audio/mp4", format: "m4a", want: api.AudioM4A},
+		{accept: "audio/basic;q=0.9, */*", want: api.AudioULAW},
+		{accept: "text/html, audio/wav", want: api.AudioWAV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+			req.Header.Add(echo.HeaderAccept, tt.accept)
+			req.Header.Add(headerDefaultFormat, "m4a")
+			res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputFormat: tt.format})
+			require.Nil(t, err)
+			assert.Equal(t, api.ContentAudio, res.OutputContentType)
+			assert.Equal(t, tt.want, res.OutputFormat)
+		})
+	}
+}
+
+func TestConfigure_OutputContentType_AudioFail(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+	req.Header.Add(echo.HeaderAccept, "audio/mpeg")
+	_, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputFormat: "wav"})
+	assert.NotNil(t, err)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputFormat: "none"})
+	assert.NotNil(t, err)
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", SpeechMarkTypes: []string{"word"}})
+	assert.NotNil(t, err)
+	res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputTextFormat: "normalized"})
+	require.Nil(t, err)
+	assert.Equal(t, api.TextNormalized, res.OutputTextFormat)
+}
+
+func Test_getAudioContentFormat(t *testing.T) {
+	tests := []struct {
+		v      string
+		want   api.AudioFormatEnum
+		wantOK bool
+	}{
+		{v: "", want: api.AudioNone, wantOK: false},
+		{v: "audio/mpeg", want: api.AudioMP3, wantOK: true},
+		{v: " audio/mp4 ", want: api.AudioM4A, wantOK: true},
+		{v: "application/json, audio/mpeg", want: api.AudioNone, wantOK: false},
+		{v: "application/msgpack", want: api.AudioNone, wantOK: false},
+		{v: "audio/ogg, audio/basic", want: api.AudioULAW, wantOK: true},
+		{v: "audio/*", want: api.AudioNone, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.v, func(t *testing.T) {
+			got, ok := getAudioContentFormat(tt.v)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestConfigure_Stream(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  metadata:\n   - r=a\n  voices:\n   - default:aaa"))
+	for _, accept := range []string{"audio/wav", "audio/wav, */*", "audio/wav;q=0.9", "*/*, audio/wav"} {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/synthesize?stream=true", strings.NewReader("text"))
+			req.Header.Add(echo.HeaderAccept, accept)
+			res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia"})
+			assert.Nil(t, err)
+			require.NotNil(t, res)
+			assert.Equal(t, api.ContentAudioStream, res.OutputContentType)
+			assert.Equal(t, api.AudioWAV, res.OutputFormat)
+		})
+	}
+}
+
+func TestConfigure_Stream_Fail(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  metadata:\n   - r=a\n  voices:\n   - default:aaa"))
+	tests := []struct {
+		name   string
+		url    string
+		accept string
+		inp    api.Input
+	}{
+		{name: "Accept", url: "/synthesize?stream=true", accept: "application/json", inp: api.Input{Text: "olia"}},
+		{name: "Accept JSON first", url: "/synthesize?stream=true", accept: "application/json, audio/wav", inp: api.Input{Text: "olia"}},
+		{name: "Accept mp3", url: "/synthesize?stream=true", accept: "audio/mpeg, audio/wav", inp: api.Input{Text: "olia"}},
+		{name: "Value", url: "/synthesize?stream=olia", accept: "audio/wav", inp: api.Input{Text: "olia"}},
+		{name: "Format", url: "/synthesize?stream=1", accept: "audio/wav", inp: api.Input{Text: "olia", OutputFormat: "mp3"}},
+		{name: "Marks", url: "/synthesize?stream=1", accept: "audio/wav", inp: api.Input{Text: "olia", SpeechMarkTypes: []string{"word"}}},
+		{name: "Text", url: "/synthesize?stream=1", accept: "audio/wav", inp: api.Input{Text: "olia", OutputTextFormat: "accented"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader("text"))
+			req.Header.Add(echo.HeaderAccept, tt.accept)
+			_, err := c.Configure(context.TODO(), req, &tt.inp)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
 func TestConfigure_MaxTextLen(t *testing.T) {
 	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  metadata:\n   - r=a\n  voices:\n   - default:aaa"))
 	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
@@ -201,6 +405,18 @@ func TestConfigure_FailTextFormat(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestConfigure_SubtitlesNoAudio(t *testing.T) {
+	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n\n  voices:\n   - default:aaa"))
+	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
+	res, err := c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputTextFormat: "vtt"})
+	assert.Nil(t, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, api.TextVTT, res.OutputTextFormat)
+	}
+	_, err = c.Configure(context.TODO(), req, &api.Input{Text: "olia", OutputFormat: "none", OutputTextFormat: "srt"})
+	assert.NotNil(t, err)
+}
+
 func TestConfigure_FailSpeed(t *testing.T) {
 	c, _ := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n\n  voices:\n   - default:aaa"))
 	req := httptest.NewRequest("POST", "/synthesize", strings.NewReader("text"))
@@ -302,6 +518,9 @@ func TestOutputTextFormat(t *testing.T) {
 		{in: "normalized", res: api.TextNormalized, isErr: false},
 		{in: "accented", res: api.TextAccented, isErr: false},
 		{in: "transcribed", res: api.TextTranscribed, isErr: false},
+		{in: "vtt", res: api.TextVTT, isErr: false},
+		{in: "srt", res: api.TextSRT, isErr: false},
+		{in: "ssml", res: api.TextSSML, isErr: false},
 		{in: "olia", res: api.TextNone, isErr: true},
 	}
 
@@ -402,6 +621,7 @@ func TestVoices(t *testing.T) {
 		{v: "in", av: map[string]string{"in": "aaa.latest", "aaa.latest": "aaa2", "aaa2": "aaa"}, e: "aaa", isErr: false},
 		{v: "a", av: map[string]string{"aa": "aa", "aaa2": "a"}, e: "", isErr: true},
 		{v: "rec", av: map[string]string{"rec": "rec1", "rec1": "rec2", "rec2": "rec"}, e: "rec1", isErr: false},
+		{v: "deep", av: map[string]string{"deep": "d1", "d1": "d2", "d2": "d3", "d3": "d4", "d4": "d5"}, e: "d4", isErr: false},
 	}
 
 	for i, tt := range tests {
@@ -449,6 +669,8 @@ func Test_getSpeechMarkTypes(t *testing.T) {
 		wantErr bool
 	}{
 		{name: "OK", args: args{s: []string{"word"}}, want: map[string]bool{"word": true}, wantErr: false},
+		{name: "All", args: args{s: []string{"word", "sentence", "ssml", "phoneme", "viseme"}},
+			want: map[string]bool{"word": true, "sentence": true, "ssml": true, "phoneme": true, "viseme": true}, wantErr: false},
 		{name: "Fail", args: args{s: []string{"word1"}}, want: nil, wantErr: true},
 		{name: "Empty", args: args{s: nil}, want: map[string]bool{}, wantErr: false},
 	}
@@ -556,3 +778,37 @@ func Test_getSymbolMode(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateSSML(t *testing.T) {
+	c, err := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa\n"+
+		"ssml:\n  languages: [lt, EN]"))
+	require.Nil(t, err)
+	res := c.ValidateSSML(`<speak><voice name="aaa">olia</voice><lang xml:lang="en">a</lang></speak>`, "")
+	assert.Equal(t, &api.SSMLValidateResult{Valid: true, Errors: []*api.SSMLIssue{}, Warnings: []*api.SSMLIssue{}}, res)
+
+	res = c.ValidateSSML("<speak>\n<voice name=\"bbb\">olia</voice><lang xml:lang=\"de\">a</lang></speak>", "")
+	assert.False(t, res.Valid)
+	assert.Equal(t, []*api.SSMLIssue{{Line: 2, Column: 1, Tag: "voice", Code: "INVALID_TAG", Message: "unknown voice 'bbb'"}}, res.Errors)
+	assert.Equal(t, []*api.SSMLIssue{{Line: 2, Column: 31, Tag: "lang", Code: "UNKNOWN_LANGUAGE", Message: "language 'de' is not supported"}},
+		res.Warnings)
+}
+
+func TestValidateSSML_TooLong(t *testing.T) {
+	c, err := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	require.Nil(t, err)
+	c.SetSSMLMaxChars(5)
+	res := c.ValidateSSML(`<speak>olia <break time="1s"/>olia</speak>`, "")
+	assert.False(t, res.Valid)
+	assert.Equal(t, []*api.SSMLIssue{{Code: "TEXT_TOO_LONG", Message: "text size too long, passed 8 chars, max 5"}}, res.Errors)
+	c.SetSSMLMaxChars(8)
+	res = c.ValidateSSML(`<speak>olia <break time="1s"/>olia</speak>`, "")
+	assert.True(t, res.Valid)
+}
+
+func TestValidateSSML_NoLanguages(t *testing.T) {
+	c, err := NewTTSConfigurator(test.NewConfig(t, "output:\n  defaultFormat: mp3\n  voices:\n   - default:aaa"))
+	require.Nil(t, err)
+	res := c.ValidateSSML(`<speak><lang xml:lang="de">a</lang></speak>`, "")
+	assert.True(t, res.Valid)
+	assert.Empty(t, res.Warnings)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/gen/tts"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	tts.UnimplementedTTSServer
+	data *PrData
+}
+
+// StartGRPCServer starts the gRPC service in the background, returns the function to stop it
+func StartGRPCServer(port int, data *PrData) (func(), error) {
+	goapp.Log.Info().Msgf("Starting gRPC TTS Line service at %d", port)
+	if data == nil || data.Processor == nil || data.Configurator == nil {
+		return nil, fmt.Errorf("no synt data")
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("listen %d: %w", port, err)
+	}
+	s := newGRPCServer(data)
+	go func() {
+		if err := s.Serve(lis); err != nil {
+			goapp.Log.Error().Err(err).Msg("gRPC server stopped")
+		}
+	}()
+	return s.GracefulStop, nil
+}
+
+func newGRPCServer(data *PrData) *grpc.Server {
+	res := grpc.NewServer()
+	tts.RegisterTTSServer(res, &grpcServer{data: data})
+	return res
+}
+
+func (s *grpcServer) Synthesize(ctx context.Context, in *tts.SynthesizeInput) (*tts.SynthesizeReply, error) {
+	ctx = loggerWithTrace(ctx).WithContext(ctx)
+	defer goapp.Estimate("gRPC synthesize method")()
+
+	cfg, err := s.data.Configurator.Configure(ctx, grpcRequest(ctx, false), toInput(in))
+	if err != nil {
+		log.Ctx(ctx).Warn().Msg("Cannot prepare request config " + err.Error())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp, err := s.data.Processor.Work(ctx, cfg)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return toReply(resp), nil
+}
+
+func (s *grpcServer) SynthesizeStream(in *tts.SynthesizeInput, stream grpc.ServerStreamingServer[tts.SynthesizeStreamReply]) error {
+	ctx := loggerWithTrace(stream.Context()).WithContext(stream.Context())
+	defer goapp.Estimate("gRPC synthesize stream method")()
+
+	cfg, err := s.data.Configurator.Configure(ctx, grpcRequest(ctx, true), toInput(in))
+	if err != nil {
+		log.Ctx(ctx).Warn().Msg("Cannot prepare request config " + err.Error())
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	sw := &grpcStreamWriter{stream: stream}
+	cfg.AudioStream = sw
+	resp, err := s.data.Processor.Work(ctx, cfg)
+	if err != nil {
+		return grpcError(ctx, err)
+	}
+	if !sw.started { // no streaming processors configured
+		if _, err := sw.Write(resp.Audio); err != nil {
+			return err
+		}
+	}
+	resp.Audio = nil
+	return stream.Send(&tts.SynthesizeStreamReply{Payload: &tts.SynthesizeStreamReply_Result{Result: toReply(resp)}})
+}
+
+// grpcStreamWriter sends audio chunks to the stream
+type grpcStreamWriter struct {
+	stream  grpc.ServerStreamingServer[tts.SynthesizeStreamReply]
+	started bool
+}
+
+func (w *grpcStreamWriter) Write(p []byte) (int, error) {
+	w.started = true
+	if err := w.stream.Send(&tts.SynthesizeStreamReply{Payload: &tts.SynthesizeStreamReply_Chunk{Chunk: p}}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// grpcRequest makes http request for the configurator, the metadata is passed as headers
+func grpcRequest(ctx context.Context, stream bool) *http.Request {
+	url := "/synthesize"
+	if stream {
+		url += "?" + paramStream + "=true"
+	}
+	res, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, vs := range md {
+		if strings.HasPrefix(k, ":") || k == strings.ToLower(echo.HeaderAccept) {
+			continue
+		}
+		for _, v := range vs {
+			res.Header.Add(k, v)
+		}
+	}
+	if stream {
+		res.Header.Set(echo.HeaderAccept, mimeAudioWAV)
+	}
+	return res
+}
+
+func grpcError(ctx context.Context, err error) error {
+	if e := badReqError(err); e != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("can't process")
+		if e.Code == api.ErrCodeRequestNotFound {
+			return status.Error(codes.NotFound, e.Message)
+		}
+		return status.Error(codes.InvalidArgument, e.Message)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		log.Ctx(ctx).Warn().Err(err).Msg("can't process")
+		return status.FromContextError(err).Err()
+	}
+	log.Ctx(ctx).Error().Err(err).Msg("can't process")
+	return status.Error(codes.Internal, "Internal error")
+}
+
+func toInput(in *tts.SynthesizeInput) *api.Input {
+	return &api.Input{
+		Text:                 in.GetText(),
+		TextType:             in.GetTextType(),
+		OutputFormat:         in.GetOutputFormat(),
+		OutputTextFormat:     in.GetOutputTextFormat(),
+		AllowCollectData:     in.SaveRequest,
+		Speed:                in.GetSpeed(),
+		Voice:                in.GetVoice(),
+		Priority:             int(in.GetPriority()),
+		SpeechMarkTypes:      in.GetSpeechMarkTypes(),
+		MaxEdgeSilenceMillis: in.MaxEdgeSilenceMillis,
+		SymbolMode:           api.SymbolMode(in.GetSymbolMode()),
+		SelectedSymbols:      in.GetSelectedSymbols(),
+		Lexicon:              toLexicon(in.GetLexicon()),
+		Lexicons:             in.GetLexicons(),
+	}
+}
+
+func toLexicon(in []*tts.LexiconEntry) []*api.LexiconEntry {
+	var res []*api.LexiconEntry
+	for _, e := range in {
+		res = append(res, &api.LexiconEntry{Grapheme: e.GetGrapheme(), Accented: e.GetAccented(), Syllables: e.GetSyllables(),
+			Transcription: e.GetTranscription(), Lemma: e.GetLemma(), Mi: e.GetMi()})
+	}
+	return res
+}
+
+func toReply(in *api.Result) *tts.SynthesizeReply {
+	res := &tts.SynthesizeReply{Audio: in.Audio, Text: in.Text, RequestId: in.RequestID, SkippedStages: in.SkippedStages}
+	for _, sm := range in.SpeechMarks {
+		res.SpeechMarks = append(res.SpeechMarks, &tts.SpeechMark{TimeMillis: sm.TimeInMillis,
+			DurationMillis: sm.Duration, Type: sm.Type, Value: sm.Value, Start: int32(sm.Start), End: int32(sm.End)})
+	}
+	return res
+}
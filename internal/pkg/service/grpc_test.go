@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/airenas/tts-line/internal/pkg/gen/tts"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test/mocks"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func initGRPCTest(t *testing.T) tts.TTSClient {
+	t.Helper()
+	initTest(t)
+	lis := bufconn.Listen(1024 * 1024)
+	s := newGRPCServer(&tData.SyntData)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return tts.NewTTSClient(conn)
+}
+
+func TestStartGRPCServer_Fail(t *testing.T) {
+	_, err := StartGRPCServer(0, nil)
+	assert.NotNil(t, err)
+	_, err = StartGRPCServer(0, &PrData{Processor: &mocks.Synthesizer{}})
+	assert.NotNil(t, err)
+}
+
+func TestGRPC_Synthesize(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3"), Text: "olia", RequestID: "rID",
+		SpeechMarks: []*api.SpeechMark{{TimeInMillis: 10, Duration: 20, Type: "word", Value: "olia", Start: 3, End: 7}}}, nil)
+
+	save := true
+	ctx := metadata.AppendToOutgoingContext(context.TODO(), headerCollectData, "always", "accept", "audio/mpeg")
+	resp, err := cl.Synthesize(ctx, &tts.SynthesizeInput{Text: "olia", Voice: "astra", SaveRequest: &save,
+		SpeechMarkTypes: []string{"word"}, Priority: 10})
+	require.Nil(t, err)
+	assert.Equal(t, []byte("mp3"), resp.GetAudio())
+	assert.Equal(t, "olia", resp.GetText())
+	assert.Equal(t, "rID", resp.GetRequestId())
+	require.Equal(t, 1, len(resp.GetSpeechMarks()))
+	sm := resp.GetSpeechMarks()[0]
+	assert.Equal(t, int64(10), sm.GetTimeMillis())
+	assert.Equal(t, int64(20), sm.GetDurationMillis())
+	assert.Equal(t, "word", sm.GetType())
+	assert.Equal(t, "olia", sm.GetValue())
+	assert.Equal(t, int32(3), sm.GetStart())
+	assert.Equal(t, int32(7), sm.GetEnd())
+
+	req := mocks.To[*http.Request](cnfMock.Calls[0].Arguments[0])
+	assert.Equal(t, "always", req.Header.Get(headerCollectData))
+	assert.Equal(t, "", req.Header.Get(echo.HeaderAccept))
+	inp := mocks.To[*api.Input](cnfMock.Calls[0].Arguments[1])
+	assert.Equal(t, "olia", inp.Text)
+	assert.Equal(t, "astra", inp.Voice)
+	assert.Equal(t, true, *inp.AllowCollectData)
+	assert.Equal(t, []string{"word"}, inp.SpeechMarkTypes)
+	assert.Equal(t, 10, inp.Priority)
+	assert.Nil(t, inp.MaxEdgeSilenceMillis)
+}
+
+func TestGRPC_Synthesize_Fail(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, errors.New("wrong voice")).Once()
+	_, err := cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, utils.NewErrWordTooLong("haha")).Once()
+	_, err = cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "Word too long: 'haha'", status.Convert(err).Message())
+
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.Wrap(utils.ErrNoRecord, "olia")).Once()
+	_, err = cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.Wrap(context.DeadlineExceeded, "olia")).Once()
+	_, err = cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.New("haha"))
+	_, err = cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGRPC_Synthesize_Lexicon(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{}, nil)
+
+	_, err := cl.Synthesize(context.TODO(), &tts.SynthesizeInput{Text: "olia", Lexicons: []string{"l1", "l2"},
+		Lexicon: []*tts.LexiconEntry{{Grapheme: "olia", Accented: "{o/}lia", Syllables: "o-lia", Transcription: "o4-lia",
+			Lemma: "olia", Mi: "Ncf"}}})
+	require.Nil(t, err)
+	inp := mocks.To[*api.Input](cnfMock.Calls[0].Arguments[1])
+	assert.Equal(t, []string{"l1", "l2"}, inp.Lexicons)
+	assert.Equal(t, []*api.LexiconEntry{{Grapheme: "olia", Accented: "{o/}lia", Syllables: "o-lia", Transcription: "o4-lia",
+		Lemma: "olia", Mi: "Ncf"}}, inp.Lexicon)
+}
+
+func TestGRPC_SynthesizeStream(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Run(func(args mock.Arguments) {
+		cfg := mocks.To[*api.TTSRequestConfig](args[0])
+		_, _ = cfg.AudioStream.Write([]byte("wav1"))
+		_, _ = cfg.AudioStream.Write([]byte("wav2"))
+	}).Return(&api.Result{Audio: []byte("wav1wav2"), RequestID: "rID", SkippedStages: []string{"obscene"}}, nil)
+
+	st, err := cl.SynthesizeStream(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	require.Nil(t, err)
+	chunks, res := receiveGRPCStream(t, st)
+	assert.Equal(t, []string{"wav1", "wav2"}, chunks)
+	require.NotNil(t, res)
+	assert.Equal(t, "rID", res.GetRequestId())
+	assert.Equal(t, []string{"obscene"}, res.GetSkippedStages())
+	assert.Nil(t, res.GetAudio())
+
+	req := mocks.To[*http.Request](cnfMock.Calls[0].Arguments[0])
+	assert.Equal(t, "true", req.URL.Query().Get(paramStream))
+	assert.Equal(t, mimeAudioWAV, req.Header.Get(echo.HeaderAccept))
+}
+
+func TestGRPC_SynthesizeStream_NoStreamWrites(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav")}, nil)
+
+	st, err := cl.SynthesizeStream(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	require.Nil(t, err)
+	chunks, res := receiveGRPCStream(t, st)
+	assert.Equal(t, []string{"wav"}, chunks)
+	assert.NotNil(t, res)
+}
+
+func TestGRPC_SynthesizeStream_Fail(t *testing.T) {
+	cl := initGRPCTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.New("haha"))
+
+	st, err := cl.SynthesizeStream(context.TODO(), &tts.SynthesizeInput{Text: "olia"})
+	require.Nil(t, err)
+	_, err = st.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func receiveGRPCStream(t *testing.T, st grpc.ServerStreamingClient[tts.SynthesizeStreamReply]) ([]string, *tts.SynthesizeReply) {
+	t.Helper()
+	var chunks []string
+	var res *tts.SynthesizeReply
+	for {
+		msg, err := st.Recv()
+		if err == io.EOF {
+			return chunks, res
+		}
+		require.Nil(t, err)
+		if c := msg.GetChunk(); c != nil {
+			chunks = append(chunks, string(c))
+		}
+		if r := msg.GetResult(); r != nil {
+			res = r
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// JobStore keeps asynchronous synthesis jobs
+type JobStore interface {
+	Save(context.Context, *api.Job) error
+	// Get returns utils.ErrNoRecord if there is no such job
+	Get(context.Context, string) (*api.Job, error)
+}
+
+// JobRunner runs synthesis jobs in the background
+type JobRunner struct {
+	synt             Synthesizer
+	store            JobStore
+	timeout          time.Duration
+	shutdownTimeout  time.Duration
+	workerQueueLimit chan bool
+
+	lock    sync.Mutex
+	running map[string]*runningJob
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+type runningJob struct {
+	progress *utils.Progress
+	cancel   context.CancelFunc
+}
+
+var errJobRunnerClosed = errors.New("job runner is closed")
+
+// NewJobRunner creates async jobs runner
+func NewJobRunner(synt Synthesizer, store JobStore, cfg *viper.Viper) (*JobRunner, error) {
+	if synt == nil {
+		return nil, errors.New("no synthesizer")
+	}
+	if store == nil {
+		return nil, errors.New("no job store")
+	}
+	if cfg == nil {
+		return nil, errors.New("no jobs config")
+	}
+	res := &JobRunner{synt: synt, store: store, running: make(map[string]*runningJob)}
+	res.timeout = cfg.GetDuration("timeout")
+	if res.timeout <= 0 {
+		res.timeout = 15 * time.Minute
+	}
+	res.shutdownTimeout = cfg.GetDuration("shutdownTimeout")
+	if res.shutdownTimeout <= 0 {
+		res.shutdownTimeout = 30 * time.Second
+	}
+	workers := cfg.GetInt("workers")
+	if workers < 1 {
+		workers = 2
+	}
+	res.workerQueueLimit = make(chan bool, workers)
+	goapp.Log.Info().Int("workers", workers).Str("timeout", res.timeout.String()).
+		Str("shutdownTimeout", res.shutdownTimeout.String()).Msg("Job runner initialized")
+	return res, nil
+}
+
+// Add saves a new job and starts it in the background
+func (jr *JobRunner) Add(ctx context.Context, cfg *api.TTSRequestConfig) (*api.Job, error) {
+	now := time.Now()
+	job := &api.Job{ID: uuid.NewString(), Status: api.JobQueued, Created: now, Updated: now}
+	// detach from the request but keep the logger and the trace
+	jCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jr.timeout)
+	progress := &utils.Progress{}
+	jCtx = utils.WithProgress(jCtx, progress)
+
+	jr.lock.Lock()
+	if jr.closed {
+		jr.lock.Unlock()
+		cancel()
+		return nil, errJobRunnerClosed
+	}
+	jr.running[job.ID] = &runningJob{progress: progress, cancel: cancel}
+	jr.wg.Add(1)
+	jr.lock.Unlock()
+
+	if err := jr.store.Save(ctx, job); err != nil {
+		jr.remove(job.ID)
+		cancel()
+		jr.wg.Done()
+		return nil, err
+	}
+	go func(job api.Job) {
+		defer jr.wg.Done()
+		defer cancel()
+		defer jr.remove(job.ID)
+		jr.run(jCtx, &job, cfg, progress)
+	}(*job)
+	return job, nil
+}
+
+// Close stops accepting new jobs and waits for the started ones up to shutdownTimeout.
+// The unfinished jobs are cancelled and saved as interrupted
+func (jr *JobRunner) Close() {
+	jr.lock.Lock()
+	jr.closed = true
+	jr.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		jr.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(jr.shutdownTimeout):
+		jr.lock.Lock()
+		goapp.Log.Warn().Int("jobs", len(jr.running)).Msg("Cancel unfinished jobs")
+		for _, r := range jr.running {
+			r.cancel()
+		}
+		jr.lock.Unlock()
+		<-done
+	}
+	if c, ok := jr.store.(interface{ Close() }); ok {
+		c.Close()
+	}
+	goapp.Log.Info().Msg("Job runner closed")
+}
+
+func (jr *JobRunner) run(ctx context.Context, job *api.Job, cfg *api.TTSRequestConfig, progress *utils.Progress) {
+	select {
+	case jr.workerQueueLimit <- true:
+		defer func() { <-jr.workerQueueLimit }()
+	case <-ctx.Done():
+		jr.save(ctx, job, api.JobFailed, nil, ctx.Err())
+		return
+	}
+	log.Ctx(ctx).Info().Str("job", job.ID).Msg("Job started")
+	jr.save(ctx, job, api.JobRunning, nil, nil)
+	res, err := jr.synt.Work(ctx, cfg)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) { // cancelled on shutdown
+			err = ctx.Err()
+		}
+		jr.save(ctx, job, api.JobFailed, nil, err)
+		return
+	}
+	job.Progress = progress.Value()
+	jr.save(ctx, job, api.JobDone, res, nil)
+	log.Ctx(ctx).Info().Str("job", job.ID).Msg("Job finished")
+}
+
+func (jr *JobRunner) save(ctx context.Context, job *api.Job, status api.JobStatus, res *api.Result, err error) {
+	job.Status = status
+	job.Updated = time.Now()
+	job.Result = res
+	if status == api.JobDone {
+		job.Progress = 1
+	}
+	if err != nil {
+		job.Error = jobError(ctx, err)
+	}
+	// the job context may be already cancelled
+	if sErr := jr.store.Save(context.WithoutCancel(ctx), job); sErr != nil {
+		log.Ctx(ctx).Error().Err(sErr).Str("job", job.ID).Msg("can't save job")
+	}
+}
+
+func jobError(ctx context.Context, err error) *api.Error {
+	if e := badReqError(err); e != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("can't process job")
+		return e
+	}
+	if errors.Is(err, context.Canceled) {
+		log.Ctx(ctx).Warn().Err(err).Msg("job interrupted")
+		return &api.Error{Code: api.ErrCodeJobInterrupted, Message: "Job interrupted"}
+	}
+	log.Ctx(ctx).Error().Err(err).Msg("can't process job")
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &api.Error{Code: api.ErrCodeJobTimeout, Message: "Job timeout"}
+	}
+	return &api.Error{Code: api.ErrCodeInternal, Message: "Internal error"}
+}
+
+func (jr *JobRunner) remove(ID string) {
+	jr.lock.Lock()
+	defer jr.lock.Unlock()
+	delete(jr.running, ID)
+}
+
+// Get returns the job with the current progress
+func (jr *JobRunner) Get(ctx context.Context, ID string) (*api.Job, error) {
+	res, err := jr.store.Get(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != api.JobQueued && res.Status != api.JobRunning {
+		return res, nil
+	}
+	jr.lock.Lock()
+	r, ok := jr.running[ID]
+	jr.lock.Unlock()
+	if ok {
+		res.Progress = r.progress.Value()
+	} else if time.Since(res.Created) > jr.timeout {
+		// the job was interrupted by restart
+		res.Status = api.JobFailed
+		res.Error = &api.Error{Code: api.ErrCodeJobInterrupted, Message: "Job interrupted"}
+	}
+	return res, nil
+}
+
+func synthesizeJob(data *PrData, jobs *JobRunner) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service synthesize job method")()
+
+		inp, err := takeInput(c)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Send()
+			return err
+		}
+
+		cfg, err := data.Configurator.Configure(ctx, c.Request(), inp)
+		if err != nil {
+			log.Ctx(ctx).Warn().Msg("Cannot prepare request config " + err.Error())
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		if cfg.OutputContentType == api.ContentAudioStream {
+			log.Ctx(ctx).Warn().Msg("Stream is not supported for jobs")
+			return echo.NewHTTPError(http.StatusBadRequest, "Stream is not supported for jobs")
+		}
+
+		job, err := jobs.Add(ctx, cfg)
+		if err != nil {
+			if errors.Is(err, errJobRunnerClosed) {
+				log.Ctx(ctx).Warn().Err(err).Msg("can't add job")
+				return echo.NewHTTPError(http.StatusServiceUnavailable)
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("can't add job")
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		log.Ctx(ctx).Info().Str("job", job.ID).Msg("Job added")
+		c.Response().Header().Set(echo.HeaderLocation, "/synthesize/jobs/"+job.ID)
+		if cfg.OutputContentType == api.ContentMsgPack {
+			return writeResponseMsgPackCode(c, http.StatusAccepted, job)
+		}
+		return writeResponseCode(c, http.StatusAccepted, job)
+	}
+}
+
+func synthesizeJobStatus(jobs *JobRunner) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service job status method")()
+
+		ID := c.Param("id")
+		if ID == "" {
+			log.Ctx(ctx).Warn().Msg("No job ID")
+			return echo.NewHTTPError(http.StatusBadRequest, "No job ID")
+		}
+
+		job, err := jobs.Get(ctx, ID)
+		if err != nil {
+			if errors.Is(err, utils.ErrNoRecord) {
+				log.Ctx(ctx).Warn().Str("job", ID).Msg("no job")
+				return echo.NewHTTPError(http.StatusNotFound, "Job not found")
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("can't get job")
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+
+		ct, _ := getOutputContentType(ctx, getHeader(c.Request(), echo.HeaderAccept))
+		if ct == api.ContentMsgPack {
+			return writeResponseMsgPack(c, job)
+		}
+		if job.Result != nil {
+			res := *job.Result
+			res.AudioAsString = toBase64(ctx, res.Audio)
+			res.Audio = nil
+			job.Result = &res
+		}
+		return writeResponse(c, job)
+	}
+}
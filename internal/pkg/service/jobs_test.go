@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/jobs"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/airenas/tts-line/internal/pkg/test/mocks"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func initJobsTest(t *testing.T) *JobRunner {
+	t.Helper()
+	initTest(t)
+	st, err := jobs.NewMemoryStore(time.Minute)
+	require.Nil(t, err)
+	t.Cleanup(st.Close)
+	tData.Jobs, err = NewJobRunner(synthesizerMock, st, test.NewConfig(t, "workers: 1"))
+	require.Nil(t, err)
+	tEcho = initRoutes(tData)
+	return tData.Jobs
+}
+
+func TestNewJobRunner(t *testing.T) {
+	st, _ := jobs.NewMemoryStore(time.Minute)
+	defer st.Close()
+	jr, err := NewJobRunner(&mocks.Synthesizer{}, st, test.NewConfig(t, ""))
+	require.Nil(t, err)
+	assert.Equal(t, 15*time.Minute, jr.timeout)
+	assert.Equal(t, 30*time.Second, jr.shutdownTimeout)
+	assert.Equal(t, 2, cap(jr.workerQueueLimit))
+	_, err = NewJobRunner(nil, st, test.NewConfig(t, ""))
+	assert.NotNil(t, err)
+	_, err = NewJobRunner(&mocks.Synthesizer{}, nil, test.NewConfig(t, ""))
+	assert.NotNil(t, err)
+	_, err = NewJobRunner(&mocks.Synthesizer{}, st, nil)
+	assert.NotNil(t, err)
+}
+
+func TestJobs_NoRoutes(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/synthesize/jobs/1", nil)
+	testCode(t, req, http.StatusNotFound)
+}
+
+func TestJobs_Returns(t *testing.T) {
+	jr := initJobsTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav"), RequestID: "rID",
+		SkippedStages: []string{"obscene"}}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/synthesize/jobs", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, http.StatusAccepted)
+	var job api.Job
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, api.JobQueued, job.Status)
+	assert.Equal(t, "/synthesize/jobs/"+job.ID, resp.Header().Get(echo.HeaderLocation))
+	jr.wg.Wait()
+
+	tResp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/synthesize/jobs/"+job.ID, nil)
+	resp = testCode(t, req, http.StatusOK)
+	var res api.Job
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, api.JobDone, res.Status)
+	assert.Equal(t, 1.0, res.Progress)
+	require.NotNil(t, res.Result)
+	assert.Equal(t, toBase64(context.TODO(), []byte("wav")), res.Result.AudioAsString)
+	assert.Equal(t, "rID", res.Result.RequestID)
+	assert.Equal(t, []string{"obscene"}, res.Result.SkippedStages)
+	synthesizerMock.AssertNumberOfCalls(t, "Work", 1)
+}
+
+func TestJobs_Failed(t *testing.T) {
+	jr := initJobsTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, utils.NewErrWordTooLong("haha"))
+
+	job, err := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	require.Nil(t, err)
+	jr.wg.Wait()
+	res, err := jr.Get(context.TODO(), job.ID)
+	require.Nil(t, err)
+	assert.Equal(t, api.JobFailed, res.Status)
+	assert.Equal(t, &api.Error{Code: api.ErrCodeWordTooLong, Message: "Word too long: 'haha'",
+		Details: &api.ErrorDetails{Word: "haha"}}, res.Error)
+	assert.Nil(t, res.Result)
+}
+
+func TestJobs_FailedInternal(t *testing.T) {
+	jr := initJobsTest(t)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.New("haha"))
+
+	job, _ := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	jr.wg.Wait()
+	res, _ := jr.Get(context.TODO(), job.ID)
+	assert.Equal(t, api.JobFailed, res.Status)
+	assert.Equal(t, &api.Error{Code: api.ErrCodeInternal, Message: "Internal error"}, res.Error)
+}
+
+func TestJobs_Running(t *testing.T) {
+	jr := initJobsTest(t)
+	wait := make(chan bool)
+	synthesizerMock.On("Work", mock.Anything).Run(func(args mock.Arguments) { <-wait }).
+		Return(&api.Result{Audio: []byte("wav")}, nil)
+
+	job, _ := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	jr.lock.Lock()
+	jr.running[job.ID].progress.AddTotal(4)
+	jr.running[job.ID].progress.AddDone(1)
+	jr.lock.Unlock()
+	res, err := jr.Get(context.TODO(), job.ID)
+	require.Nil(t, err)
+	assert.Contains(t, []api.JobStatus{api.JobQueued, api.JobRunning}, res.Status)
+	assert.Equal(t, 0.25, res.Progress)
+	close(wait)
+	jr.wg.Wait()
+	res, _ = jr.Get(context.TODO(), job.ID)
+	assert.Equal(t, api.JobDone, res.Status)
+}
+
+func TestJobs_Interrupted(t *testing.T) {
+	jr := initJobsTest(t)
+	at := time.Now().Add(-time.Hour)
+	_ = jr.store.Save(context.TODO(), &api.Job{ID: "1", Status: api.JobRunning, Created: at, Updated: time.Now()})
+	res, err := jr.Get(context.TODO(), "1")
+	require.Nil(t, err)
+	assert.Equal(t, api.JobFailed, res.Status)
+	assert.Equal(t, &api.Error{Code: api.ErrCodeJobInterrupted, Message: "Job interrupted"}, res.Error)
+}
+
+func TestJobs_Close(t *testing.T) {
+	jr := initJobsTest(t)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav")}, nil)
+	job, err := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	require.Nil(t, err)
+
+	jr.Close()
+	res, _ := jr.Get(context.TODO(), job.ID)
+	assert.Equal(t, api.JobDone, res.Status)
+	_, err = jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	assert.ErrorIs(t, err, errJobRunnerClosed)
+
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1"}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/synthesize/jobs", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusServiceUnavailable)
+}
+
+// waitSynthesizer works until the context is cancelled
+type waitSynthesizer struct{}
+
+func (s *waitSynthesizer) Work(ctx context.Context, _ *api.TTSRequestConfig) (*api.Result, error) {
+	<-ctx.Done()
+	return nil, errors.New("olia")
+}
+
+func TestJobs_Close_Interrupts(t *testing.T) {
+	st, err := jobs.NewMemoryStore(time.Minute)
+	require.Nil(t, err)
+	jr, err := NewJobRunner(&waitSynthesizer{}, st, test.NewConfig(t, "workers: 1\nshutdownTimeout: 10ms"))
+	require.Nil(t, err)
+	running, err := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	require.Nil(t, err)
+	queued, err := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia2"})
+	require.Nil(t, err)
+
+	jr.Close()
+	for _, ID := range []string{running.ID, queued.ID} {
+		res, err := st.Get(context.TODO(), ID)
+		require.Nil(t, err)
+		assert.Equal(t, api.JobFailed, res.Status)
+		assert.Equal(t, &api.Error{Code: api.ErrCodeJobInterrupted, Message: "Job interrupted"}, res.Error)
+	}
+}
+
+func TestJobs_NotFound(t *testing.T) {
+	initJobsTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/synthesize/jobs/olia", nil)
+	testCode(t, req, http.StatusNotFound)
+}
+
+func TestJobs_MsgPack(t *testing.T) {
+	jr := initJobsTest(t)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav")}, nil)
+	job, _ := jr.Add(context.TODO(), &api.TTSRequestConfig{Text: "olia1"})
+	jr.wg.Wait()
+
+	req := httptest.NewRequest(http.MethodGet, "/synthesize/jobs/"+job.ID, nil)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationMsgpack)
+	resp := testCode(t, req, http.StatusOK)
+	assert.Equal(t, echo.MIMEApplicationMsgpack, resp.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, resp.Body.String(), "wav")
+	assert.NotContains(t, resp.Body.String(), "audioAsString")
+}
+
+func TestJobs_FailConfigure(t *testing.T) {
+	initJobsTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, errors.New("No format mmp"))
+	req := httptest.NewRequest(http.MethodPost, "/synthesize/jobs", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusBadRequest)
+}
+
+func TestJobs_FailStream(t *testing.T) {
+	initJobsTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1",
+		OutputContentType: api.ContentAudioStream}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/synthesize/jobs", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusBadRequest)
+}
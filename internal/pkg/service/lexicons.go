@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/lexicon"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+// LexiconStore keeps the entries of the stored lexicons
+type LexiconStore interface {
+	Entries(ctx context.Context, name string) ([]*api.LexiconEntryRecord, error)
+	Add(ctx context.Context, name string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error)
+	// Update returns utils.ErrNoRecord if there is no such entry
+	Update(ctx context.Context, name, ID string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error)
+	// Delete returns utils.ErrNoRecord if there is no such entry
+	Delete(ctx context.Context, name, ID string) error
+}
+
+// LexiconsData is the stored lexicons management configuration
+type LexiconsData struct {
+	store      LexiconStore
+	adminToken string
+}
+
+// NewLexiconsData creates the stored lexicons management configuration,
+// the routes require 'Authorization: Bearer <adminToken>'
+func NewLexiconsData(store LexiconStore, cfg *viper.Viper) (*LexiconsData, error) {
+	if store == nil {
+		return nil, errors.New("no lexicon store")
+	}
+	if cfg == nil {
+		return nil, errors.New("no lexicons config")
+	}
+	res := &LexiconsData{store: store, adminToken: cfg.GetString("adminToken")}
+	if res.adminToken == "" {
+		return nil, errors.New("no lexicons adminToken")
+	}
+	return res, nil
+}
+
+// adminAuth allows only the requests with the admin token
+func adminAuth(token string) echo.MiddlewareFunc {
+	return middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			return true, nil
+		}
+		log.Ctx(c.Request().Context()).Warn().Msg("wrong admin token")
+		return false, nil
+	})
+}
+
+var lexiconNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,100}$`)
+
+// IsLexiconName checks if the value is a valid name of the stored lexicon
+func IsLexiconName(s string) bool {
+	return lexiconNameRegexp.MatchString(s)
+}
+
+func lexiconEntries(store LexiconStore) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service lexicon entries method")()
+
+		name, err := takeLexiconName(c)
+		if err != nil {
+			return err
+		}
+		res, err := store.Entries(ctx, name)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("can't get lexicon entries")
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		return writeResponse(c, res)
+	}
+}
+
+func addLexiconEntry(store LexiconStore) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service add lexicon entry method")()
+
+		name, err := takeLexiconName(c)
+		if err != nil {
+			return err
+		}
+		entry, err := takeLexiconEntry(c)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Send()
+			return err
+		}
+		res, err := store.Add(ctx, name, entry)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("can't add lexicon entry")
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		log.Ctx(ctx).Info().Str("lexicon", name).Str("id", res.ID).Msg("lexicon entry added")
+		return writeResponseCode(c, http.StatusCreated, res)
+	}
+}
+
+func updateLexiconEntry(store LexiconStore) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service update lexicon entry method")()
+
+		name, err := takeLexiconName(c)
+		if err != nil {
+			return err
+		}
+		entry, err := takeLexiconEntry(c)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Send()
+			return err
+		}
+		ID := c.Param("id")
+		res, err := store.Update(ctx, name, ID, entry)
+		if err != nil {
+			return lexiconEntryError(ctx, err, ID)
+		}
+		log.Ctx(ctx).Info().Str("lexicon", name).Str("id", res.ID).Msg("lexicon entry updated")
+		return writeResponse(c, res)
+	}
+}
+
+func deleteLexiconEntry(store LexiconStore) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service delete lexicon entry method")()
+
+		name, err := takeLexiconName(c)
+		if err != nil {
+			return err
+		}
+		ID := c.Param("id")
+		if err := store.Delete(ctx, name, ID); err != nil {
+			return lexiconEntryError(ctx, err, ID)
+		}
+		log.Ctx(ctx).Info().Str("lexicon", name).Str("id", ID).Msg("lexicon entry deleted")
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func lexiconEntryError(ctx context.Context, err error, ID string) error {
+	if errors.Is(err, utils.ErrNoRecord) {
+		log.Ctx(ctx).Warn().Str("id", goapp.Sanitize(ID)).Msg("no lexicon entry")
+		return echo.NewHTTPError(http.StatusNotFound, "Entry not found")
+	}
+	log.Ctx(ctx).Error().Err(err).Msg("can't change lexicon entry")
+	return echo.NewHTTPError(http.StatusInternalServerError)
+}
+
+func takeLexiconName(c echo.Context) (string, error) {
+	res := c.Param("name")
+	if !IsLexiconName(res) {
+		log.Ctx(c.Request().Context()).Warn().Str("lexicon", goapp.Sanitize(res)).Msg("wrong lexicon name")
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Wrong lexicon name")
+	}
+	return res, nil
+}
+
+func takeLexiconEntry(c echo.Context) (*api.LexiconEntry, error) {
+	ctype := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Wrong content type. Expected '"+echo.MIMEApplicationJSON+"'")
+	}
+	res := new(api.LexiconEntry)
+	if err := c.Bind(res); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Cannot decode input")
+	}
+	if _, err := lexicon.Parse(res); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return res, nil
+}
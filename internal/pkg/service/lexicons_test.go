@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLexiconStore struct{ mock.Mock }
+
+func (m *mockLexiconStore) Entries(ctx context.Context, name string) ([]*api.LexiconEntryRecord, error) {
+	args := m.Called(ctx, name)
+	return mockLexiconRecords(args.Get(0)), args.Error(1)
+}
+
+func (m *mockLexiconStore) Add(ctx context.Context, name string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error) {
+	args := m.Called(ctx, name, entry)
+	return mockLexiconRecord(args.Get(0)), args.Error(1)
+}
+
+func (m *mockLexiconStore) Update(ctx context.Context, name, ID string, entry *api.LexiconEntry) (*api.LexiconEntryRecord, error) {
+	args := m.Called(ctx, name, ID, entry)
+	return mockLexiconRecord(args.Get(0)), args.Error(1)
+}
+
+func (m *mockLexiconStore) Delete(ctx context.Context, name, ID string) error {
+	args := m.Called(ctx, name, ID)
+	return args.Error(0)
+}
+
+func mockLexiconRecords(a interface{}) []*api.LexiconEntryRecord {
+	if a == nil {
+		return nil
+	}
+	return a.([]*api.LexiconEntryRecord)
+}
+
+func mockLexiconRecord(a interface{}) *api.LexiconEntryRecord {
+	if a == nil {
+		return nil
+	}
+	return a.(*api.LexiconEntryRecord)
+}
+
+func initLexiconsTest(t *testing.T) *mockLexiconStore {
+	t.Helper()
+	initTest(t)
+	res := &mockLexiconStore{}
+	var err error
+	tData.Lexicons, err = NewLexiconsData(res, test.NewConfig(t, "adminToken: secret"))
+	require.Nil(t, err)
+	tEcho = initRoutes(tData)
+	return res
+}
+
+func toLexiconReader(e api.LexiconEntry) io.Reader {
+	bytes, _ := json.Marshal(e)
+	return strings.NewReader(string(bytes))
+}
+
+func newLexiconRequest(method, path string, e *api.LexiconEntry) *http.Request {
+	var res *http.Request
+	if e == nil {
+		res = httptest.NewRequest(method, path, nil)
+	} else {
+		res = httptest.NewRequest(method, path, toLexiconReader(*e))
+		res.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	res.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	return res
+}
+
+func TestNewLexiconsData(t *testing.T) {
+	ld, err := NewLexiconsData(&mockLexiconStore{}, test.NewConfig(t, "adminToken: secret"))
+	require.Nil(t, err)
+	assert.Equal(t, "secret", ld.adminToken)
+	_, err = NewLexiconsData(&mockLexiconStore{}, test.NewConfig(t, ""))
+	assert.NotNil(t, err)
+	_, err = NewLexiconsData(nil, test.NewConfig(t, "adminToken: secret"))
+	assert.NotNil(t, err)
+	_, err = NewLexiconsData(&mockLexiconStore{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestLexicons_Unauthorized(t *testing.T) {
+	st := initLexiconsTest(t)
+	req := newLexiconRequest(http.MethodGet, "/lexicons/l1/entries", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer olia")
+	testCode(t, req, http.StatusUnauthorized)
+	tResp = httptest.NewRecorder()
+	req = newLexiconRequest(http.MethodDelete, "/lexicons/l1/entries/1", nil)
+	req.Header.Del(echo.HeaderAuthorization)
+	testCode(t, req, http.StatusBadRequest)
+	st.AssertNotCalled(t, "Entries", mock.Anything, mock.Anything)
+	st.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIsLexiconName(t *testing.T) {
+	assert.True(t, IsLexiconName("lex"))
+	assert.True(t, IsLexiconName("Lex_1.2-a"))
+	assert.False(t, IsLexiconName(""))
+	assert.False(t, IsLexiconName("l/1"))
+	assert.False(t, IsLexiconName("l 1"))
+	assert.False(t, IsLexiconName(strings.Repeat("a", 101)))
+}
+
+func TestLexicons_NoRoutes(t *testing.T) {
+	initTest(t)
+	testCode(t, newLexiconRequest(http.MethodGet, "/lexicons/l1/entries", nil), http.StatusNotFound)
+}
+
+func TestLexicons_Entries(t *testing.T) {
+	st := initLexiconsTest(t)
+	st.On("Entries", mock.Anything, "l1").Return([]*api.LexiconEntryRecord{{ID: "1",
+		LexiconEntry: api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla"}}}, nil)
+	resp := testCode(t, newLexiconRequest(http.MethodGet, "/lexicons/l1/entries", nil), http.StatusOK)
+	var res []*api.LexiconEntryRecord
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, "1", res[0].ID)
+	assert.Equal(t, "T{e/}sla", res[0].Accented)
+}
+
+func TestLexicons_Entries_Fail(t *testing.T) {
+	st := initLexiconsTest(t)
+	st.On("Entries", mock.Anything, "l1").Return(nil, errors.New("olia"))
+	testCode(t, newLexiconRequest(http.MethodGet, "/lexicons/l1/entries", nil), http.StatusInternalServerError)
+	tResp = httptest.NewRecorder()
+	testCode(t, newLexiconRequest(http.MethodGet, "/lexicons/l$1/entries", nil), http.StatusBadRequest)
+}
+
+func TestLexicons_Add(t *testing.T) {
+	st := initLexiconsTest(t)
+	e := &api.LexiconEntry{Grapheme: "Tesla", Accented: "T{e/}sla", Mi: "Np"}
+	st.On("Add", mock.Anything, "l1", e).Return(&api.LexiconEntryRecord{ID: "1", LexiconEntry: *e}, nil)
+	resp := testCode(t, newLexiconRequest(http.MethodPost, "/lexicons/l1/entries", e), http.StatusCreated)
+	var res api.LexiconEntryRecord
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "1", res.ID)
+	assert.Equal(t, "Np", res.Mi)
+}
+
+func TestLexicons_Add_Fail(t *testing.T) {
+	st := initLexiconsTest(t)
+	testCode(t, newLexiconRequest(http.MethodPost, "/lexicons/l1/entries", &api.LexiconEntry{Grapheme: "Tesla"}),
+		http.StatusBadRequest)
+	tResp = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/lexicons/l1/entries", strings.NewReader("{}"))
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	testCode(t, req, http.StatusBadRequest)
+	tResp = httptest.NewRecorder()
+	st.On("Add", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("olia"))
+	testCode(t, newLexiconRequest(http.MethodPost, "/lexicons/l1/entries", &api.LexiconEntry{Grapheme: "Tesla", Syllables: "tes-la"}),
+		http.StatusInternalServerError)
+}
+
+func TestLexicons_Update(t *testing.T) {
+	st := initLexiconsTest(t)
+	e := &api.LexiconEntry{Grapheme: "Tesla", Syllables: "tes-la"}
+	st.On("Update", mock.Anything, "l1", "1", e).Return(&api.LexiconEntryRecord{ID: "1", LexiconEntry: *e}, nil)
+	resp := testCode(t, newLexiconRequest(http.MethodPut, "/lexicons/l1/entries/1", e), http.StatusOK)
+	var res api.LexiconEntryRecord
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "tes-la", res.Syllables)
+}
+
+func TestLexicons_Update_Fail(t *testing.T) {
+	st := initLexiconsTest(t)
+	e := &api.LexiconEntry{Grapheme: "Tesla", Syllables: "tes-la"}
+	st.On("Update", mock.Anything, "l1", "1", e).Return(nil, utils.ErrNoRecord)
+	st.On("Update", mock.Anything, "l1", "2", e).Return(nil, errors.New("olia"))
+	testCode(t, newLexiconRequest(http.MethodPut, "/lexicons/l1/entries/1", e), http.StatusNotFound)
+	tResp = httptest.NewRecorder()
+	testCode(t, newLexiconRequest(http.MethodPut, "/lexicons/l1/entries/2", e), http.StatusInternalServerError)
+	tResp = httptest.NewRecorder()
+	testCode(t, newLexiconRequest(http.MethodPut, "/lexicons/l1/entries/1", &api.LexiconEntry{Grapheme: "Tesla"}),
+		http.StatusBadRequest)
+}
+
+func TestLexicons_Delete(t *testing.T) {
+	st := initLexiconsTest(t)
+	st.On("Delete", mock.Anything, "l1", "1").Return(nil)
+	st.On("Delete", mock.Anything, "l1", "2").Return(utils.ErrNoRecord)
+	testCode(t, newLexiconRequest(http.MethodDelete, "/lexicons/l1/entries/1", nil), http.StatusNoContent)
+	tResp = httptest.NewRecorder()
+	testCode(t, newLexiconRequest(http.MethodDelete, "/lexicons/l1/entries/2", nil), http.StatusNotFound)
+}
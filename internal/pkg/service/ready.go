@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ProbeFunc checks if a dependency is reachable
+type ProbeFunc func(context.Context) error
+
+type probe struct {
+	name string
+	f    ProbeFunc
+}
+
+// ReadyData probes the downstream dependencies and caches the result
+type ReadyData struct {
+	probes        []probe
+	timeout       time.Duration
+	cacheDuration time.Duration
+
+	m       sync.Mutex
+	last    *api.ReadyResult
+	checked time.Time
+}
+
+// NewReadyData creates readiness checker
+func NewReadyData(cfg *viper.Viper) (*ReadyData, error) {
+	if cfg == nil {
+		return nil, errors.New("no ready config")
+	}
+	res := &ReadyData{}
+	res.timeout = cfg.GetDuration("timeout")
+	if res.timeout <= 0 {
+		res.timeout = 3 * time.Second
+	}
+	res.cacheDuration = cfg.GetDuration("cacheDuration")
+	if res.cacheDuration <= 0 {
+		res.cacheDuration = 5 * time.Second
+	}
+	goapp.Log.Info().Str("timeout", res.timeout.String()).Str("cacheDuration", res.cacheDuration.String()).Msg("Ready initialized")
+	return res, nil
+}
+
+// Add adds the dependency probe
+func (r *ReadyData) Add(name string, f ProbeFunc) {
+	r.probes = append(r.probes, probe{name: name, f: f})
+}
+
+// Check returns the cached result or probes all dependencies in parallel,
+// the probes do not depend on the caller's cancelation as the result is shared
+func (r *ReadyData) Check(ctx context.Context) *api.ReadyResult {
+	if res := r.cached(); res != nil {
+		return res
+	}
+	pCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	res := &api.ReadyResult{Status: api.ReadyOK, Dependencies: make([]*api.DependencyStatus, len(r.probes))}
+	var wg sync.WaitGroup
+	for i, p := range r.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res.Dependencies[i] = runProbe(pCtx, p)
+		}()
+	}
+	wg.Wait()
+	for _, d := range res.Dependencies {
+		if d.Status != api.ReadyOK {
+			log.Ctx(ctx).Warn().Str("name", d.Name).Str("error", d.Error).Msg("not ready")
+			res.Status = api.ReadyFail
+		}
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.last, r.checked = res, time.Now()
+	return res
+}
+
+func (r *ReadyData) cached() *api.ReadyResult {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.last != nil && time.Since(r.checked) < r.cacheDuration {
+		return r.last
+	}
+	return nil
+}
+
+func runProbe(ctx context.Context, p probe) *api.DependencyStatus {
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- p.f(ctx) }()
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done(): // the probe may ignore the context
+		err = ctx.Err()
+	}
+	res := &api.DependencyStatus{Name: p.name, Status: api.ReadyOK, LatencyMillis: time.Since(start).Milliseconds()}
+	if err != nil {
+		res.Status, res.Error = api.ReadyFail, err.Error()
+	}
+	return res
+}
+
+// NewHTTPProbe makes a probe for HTTP service, any response except 5xx means the service is up
+func NewHTTPProbe(urlStr string) (ProbeFunc, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("wrong url '%s'", urlStr)
+	}
+	u.RawQuery = ""
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 10000))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}, nil
+}
+
+// NewGRPCProbe makes a probe that checks if the gRPC connection can be established
+func NewGRPCProbe(target string) (ProbeFunc, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("init gRPC client: %w", err)
+	}
+	return func(ctx context.Context) error {
+		// do not wait for the backoff after a failure
+		conn.ResetConnectBackoff()
+		conn.Connect()
+		connecting := false
+		for {
+			s := conn.GetState()
+			switch s {
+			case connectivity.Ready:
+				return nil
+			case connectivity.Connecting:
+				connecting = true
+			case connectivity.TransientFailure:
+				if connecting {
+					return errors.New("can't connect")
+				}
+			}
+			if !conn.WaitForStateChange(ctx, s) {
+				return fmt.Errorf("state %s: %w", s, ctx.Err())
+			}
+		}
+	}, nil
+}
+
+func ready(data *ReadyData) func(echo.Context) error {
+	return func(c echo.Context) error {
+		res := data.Check(c.Request().Context())
+		code := http.StatusOK
+		if res.Status != api.ReadyOK {
+			code = http.StatusServiceUnavailable
+		}
+		return c.JSON(code, res)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestNewReadyData(t *testing.T) {
+	r, err := NewReadyData(test.NewConfig(t, ""))
+	require.Nil(t, err)
+	assert.Equal(t, 3*time.Second, r.timeout)
+	assert.Equal(t, 5*time.Second, r.cacheDuration)
+	r, _ = NewReadyData(test.NewConfig(t, "timeout: 1s\ncacheDuration: 10s"))
+	assert.Equal(t, time.Second, r.timeout)
+	assert.Equal(t, 10*time.Second, r.cacheDuration)
+	_, err = NewReadyData(nil)
+	assert.NotNil(t, err)
+}
+
+func TestReadyData_Check(t *testing.T) {
+	r, _ := NewReadyData(test.NewConfig(t, ""))
+	var calls atomic.Int32
+	r.Add("ok", func(context.Context) error { calls.Add(1); return nil })
+	r.Add("fail", func(context.Context) error { return errors.New("olia") })
+
+	res := r.Check(context.TODO())
+	assert.Equal(t, api.ReadyFail, res.Status)
+	require.Equal(t, 2, len(res.Dependencies))
+	assert.Equal(t, "ok", res.Dependencies[0].Name)
+	assert.Equal(t, api.ReadyOK, res.Dependencies[0].Status)
+	assert.Equal(t, "fail", res.Dependencies[1].Name)
+	assert.Equal(t, api.ReadyFail, res.Dependencies[1].Status)
+	assert.Equal(t, "olia", res.Dependencies[1].Error)
+
+	assert.Equal(t, res, r.Check(context.TODO()))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestReadyData_Check_Expires(t *testing.T) {
+	r, _ := NewReadyData(test.NewConfig(t, "cacheDuration: 1ms"))
+	var calls atomic.Int32
+	r.Add("ok", func(context.Context) error { calls.Add(1); return nil })
+	assert.Equal(t, api.ReadyOK, r.Check(context.TODO()).Status)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, api.ReadyOK, r.Check(context.TODO()).Status)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestReadyData_Check_Timeout(t *testing.T) {
+	r, _ := NewReadyData(test.NewConfig(t, "timeout: 10ms"))
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	r.Add("slow", func(context.Context) error { <-block; return nil })
+	res := r.Check(context.TODO())
+	assert.Equal(t, api.ReadyFail, res.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), res.Dependencies[0].Error)
+}
+
+func TestReadyData_Check_CanceledCaller(t *testing.T) {
+	r, _ := NewReadyData(test.NewConfig(t, ""))
+	r.Add("ok", func(ctx context.Context) error { return ctx.Err() })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, api.ReadyOK, r.Check(ctx).Status)
+}
+
+func TestReadyData_Check_NoLockWhileProbing(t *testing.T) {
+	r, _ := NewReadyData(test.NewConfig(t, "timeout: 1s"))
+	var calls atomic.Int32
+	both := make(chan struct{})
+	r.Add("wait", func(ctx context.Context) error {
+		if calls.Add(1) == 2 {
+			close(both)
+		}
+		select {
+		case <-both:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	resCh := make(chan *api.ReadyResult, 2)
+	for range 2 {
+		go func() { resCh <- r.Check(context.TODO()) }()
+	}
+	assert.Equal(t, api.ReadyOK, (<-resCh).Status)
+	assert.Equal(t, api.ReadyOK, (<-resCh).Status)
+}
+
+func TestReady(t *testing.T) {
+	initTest(t)
+	r, _ := NewReadyData(test.NewConfig(t, ""))
+	fail := false
+	r.Add("olia", func(context.Context) error {
+		if fail {
+			return errors.New("olia")
+		}
+		return nil
+	})
+	tData.Ready = r
+	tEcho = initRoutes(tData)
+
+	resp := testCode(t, httptest.NewRequest(http.MethodGet, "/ready", nil), http.StatusOK)
+	var res api.ReadyResult
+	require.Nil(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, api.ReadyOK, res.Status)
+
+	fail = true
+	r.last = nil
+	tResp = httptest.NewRecorder()
+	resp = testCode(t, httptest.NewRequest(http.MethodGet, "/ready", nil), http.StatusServiceUnavailable)
+	require.Nil(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, api.ReadyFail, res.Status)
+	assert.Equal(t, "olia", res.Dependencies[0].Error)
+}
+
+func TestReady_NoRoute(t *testing.T) {
+	initTest(t)
+	testCode(t, httptest.NewRequest(http.MethodGet, "/ready", nil), http.StatusNotFound)
+}
+
+func TestNewHTTPProbe(t *testing.T) {
+	code := http.StatusMethodNotAllowed
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.String()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := NewHTTPProbe(server.URL + "/accent?human=true")
+	require.Nil(t, err)
+	assert.Nil(t, p(context.TODO()))
+	assert.Equal(t, "/accent", path)
+	code = http.StatusBadGateway
+	assert.NotNil(t, p(context.TODO()))
+
+	_, err = NewHTTPProbe("olia")
+	assert.NotNil(t, err)
+}
+
+func TestNewHTTPProbe_Fail(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	p, err := NewHTTPProbe(server.URL)
+	require.Nil(t, err)
+	assert.NotNil(t, p(context.TODO()))
+}
+
+func TestNewGRPCProbe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	s := grpc.NewServer()
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	p, err := NewGRPCProbe(lis.Addr().String())
+	require.Nil(t, err)
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	assert.Nil(t, p(ctx))
+}
+
+func TestNewGRPCProbe_Fail(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	addr := lis.Addr().String()
+	_ = lis.Close()
+
+	p, err := NewGRPCProbe(addr)
+	require.Nil(t, err)
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	assert.NotNil(t, p(ctx))
+	assert.Nil(t, ctx.Err())
+}
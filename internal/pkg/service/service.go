@@ -8,6 +8,7 @@ import (
 	"fmt"
 	slog "log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -44,6 +45,18 @@ type (
 	InfoGetter interface {
 		Provide(ID string) (*api.InfoResult, error)
 	}
+	//Analyzer runs the pipeline without the acoustic model and returns the per-word results
+	Analyzer interface {
+		Analyze(context.Context, *api.TTSRequestConfig) (*api.AnalyzeResult, error)
+	}
+	//VoicesProvider returns available voices
+	VoicesProvider interface {
+		Voices() *api.VoicesResult
+	}
+	//SSMLValidator checks SSML with the configured voices
+	SSMLValidator interface {
+		ValidateSSML(text, voice string) *api.SSMLValidateResult
+	}
 
 	//PrData is method process data
 	PrData struct {
@@ -51,12 +64,32 @@ type (
 		Configurator Configurator
 	}
 
+	//AnalyzeData is analyze method process data
+	AnalyzeData struct {
+		Processor    Analyzer
+		Configurator Configurator
+	}
+
 	//Data is service operation data
 	Data struct {
 		Port           int
 		SyntData       PrData
 		SyntCustomData PrData
 		InfoGetterData InfoGetter
+		// VoicesData lists voices for /voices, optional
+		VoicesData VoicesProvider
+		// Jobs runs async synthesis, optional
+		Jobs *JobRunner
+		// WS configures websocket synthesis, optional
+		WS *WSData
+		// Analyze runs /analyze, optional
+		Analyze *AnalyzeData
+		// Ready probes the dependencies for /ready, optional
+		Ready *ReadyData
+		// SSMLValidator runs /ssml/validate, optional
+		SSMLValidator SSMLValidator
+		// Lexicons manages the stored lexicons, optional
+		Lexicons *LexiconsData
 	}
 )
 
@@ -96,6 +129,20 @@ func validate(data *Data) error {
 	return nil
 }
 
+const (
+	headerRequestID = "x-tts-request-id"
+	// headerText contains URL escaped text, it is not sent if longer than maxHeaderTextLen
+	headerText = "x-tts-text"
+	// headerTextOmitted is set to 'true' if the requested text is too long for headerText,
+	// use the JSON or msgpack response to get such text
+	headerTextOmitted = "x-tts-text-omitted"
+	// maxHeaderTextLen keeps the headers in the usual 8KB limit of the proxies
+	maxHeaderTextLen = 4096
+	// headerSkippedStages lists the failed optional stages of the degraded response
+	headerSkippedStages = "x-tts-skipped-stages"
+	headerTrailer       = "Trailer"
+)
+
 var promMdlw *prometheus.Prometheus
 
 func init() {
@@ -104,6 +151,7 @@ func init() {
 
 func initRoutes(data *Data) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = httpErrorHandler
 	e.Use(middleware.Logger())
 	promMdlw.Use(e)
 	e.Use(otelecho.Middleware(utils.ServiceName, otelecho.WithSkipper(skipper)))
@@ -113,7 +161,33 @@ func initRoutes(data *Data) *echo.Echo {
 	e.POST("/synthesize", synthesizeText(&data.SyntData))
 	e.POST("/synthesizeCustom", synthesizeCustom(&data.SyntCustomData))
 	e.GET("/request/:requestID", synthesizeInfo(data.InfoGetterData))
+	if data.VoicesData != nil {
+		e.GET("/voices", voices(data.VoicesData))
+	}
+	if data.Jobs != nil {
+		e.POST("/synthesize/jobs", synthesizeJob(&data.SyntData, data.Jobs))
+		e.GET("/synthesize/jobs/:id", synthesizeJobStatus(data.Jobs))
+	}
+	if data.WS != nil {
+		e.GET("/synthesize/ws", synthesizeWS(&data.SyntData, data.WS))
+	}
+	if data.Analyze != nil {
+		e.POST("/analyze", analyze(data.Analyze))
+	}
+	if data.SSMLValidator != nil {
+		e.POST("/ssml/validate", validateSSML(data.SSMLValidator))
+	}
+	if data.Lexicons != nil {
+		g := e.Group("/lexicons", adminAuth(data.Lexicons.adminToken))
+		g.GET("/:name/entries", lexiconEntries(data.Lexicons.store))
+		g.POST("/:name/entries", addLexiconEntry(data.Lexicons.store))
+		g.PUT("/:name/entries/:id", updateLexiconEntry(data.Lexicons.store))
+		g.DELETE("/:name/entries/:id", deleteLexiconEntry(data.Lexicons.store))
+	}
 	e.GET("/live", live(data))
+	if data.Ready != nil {
+		e.GET("/ready", ready(data.Ready))
+	}
 
 	goapp.Log.Info().Msg("Routes:")
 	for _, r := range e.Routes() {
@@ -123,7 +197,7 @@ func initRoutes(data *Data) *echo.Echo {
 }
 
 func skipper(c echo.Context) bool {
-	return c.Request().RequestURI == "/live"
+	return c.Request().RequestURI == "/live" || c.Request().RequestURI == "/ready"
 }
 
 func synthesizeText(data *PrData) func(echo.Context) error {
@@ -143,16 +217,24 @@ func synthesizeText(data *PrData) func(echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		}
 
+		if cfg.OutputContentType == api.ContentAudioStream {
+			return synthesizeStream(c, data.Processor, cfg)
+		}
+
 		resp, err := data.Processor.Work(ctx, cfg)
 		if err != nil {
-			if d, msg := badReqError(err); d {
+			if e := badReqError(err); e != nil {
 				log.Ctx(ctx).Warn().Err(err).Msg("can't process")
-				return echo.NewHTTPError(http.StatusBadRequest, msg)
+				return echo.NewHTTPError(http.StatusBadRequest, e)
 			}
 			log.Ctx(ctx).Error().Err(err).Msg("can't process")
 			return echo.NewHTTPError(http.StatusInternalServerError)
 		}
 
+		setSkippedStagesHeader(c, resp.SkippedStages)
+		if cfg.OutputContentType == api.ContentAudio {
+			return writeResponseAudio(c, cfg.OutputFormat, resp)
+		}
 		return writeResponseMsgPackOrJson(c, cfg.OutputContentType, resp)
 	}
 }
@@ -188,21 +270,116 @@ func synthesizeCustom(data *PrData) func(echo.Context) error {
 		cfg.RequestID = rID
 		cfg.AllowCollectData = true // turn collect data to true as it is mandatory for this request
 
+		if cfg.OutputContentType == api.ContentAudioStream {
+			return synthesizeStream(c, data.Processor, cfg)
+		}
+
 		resp, err := data.Processor.Work(ctx, cfg)
 		if err != nil {
-			if d, msg := badReqError(err); d {
+			if e := badReqError(err); e != nil {
 				log.Ctx(ctx).Warn().Err(err).Msg("can't process")
-				return echo.NewHTTPError(http.StatusBadRequest, msg)
+				return echo.NewHTTPError(http.StatusBadRequest, e)
 			}
 			log.Ctx(ctx).Error().Err(err).Msg("can't process")
 			return echo.NewHTTPError(http.StatusInternalServerError)
 		}
 		resp.RequestID = ""
 
+		setSkippedStagesHeader(c, resp.SkippedStages)
+		if cfg.OutputContentType == api.ContentAudio {
+			return writeResponseAudio(c, cfg.OutputFormat, resp)
+		}
 		return writeResponseMsgPackOrJson(c, cfg.OutputContentType, resp)
 	}
 }
 
+func analyze(data *AnalyzeData) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service analyze method")()
+
+		inp, err := takeInput(c)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Send()
+			return err
+		}
+
+		cfg, err := data.Configurator.Configure(ctx, c.Request(), inp)
+		if err != nil {
+			log.Ctx(ctx).Warn().Msg("Cannot prepare request config " + err.Error())
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		resp, err := data.Processor.Analyze(ctx, cfg)
+		if err != nil {
+			if e := badReqError(err); e != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("can't process")
+				return echo.NewHTTPError(http.StatusBadRequest, e)
+			}
+			log.Ctx(ctx).Error().Err(err).Msg("can't process")
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+		setSkippedStagesHeader(c, resp.SkippedStages)
+		if cfg.OutputContentType == api.ContentMsgPack {
+			return writeResponseMsgPack(c, resp)
+		}
+		return writeResponse(c, resp)
+	}
+}
+
+func synthesizeStream(c echo.Context, synt Synthesizer, cfg *api.TTSRequestConfig) error {
+	ctx := c.Request().Context()
+	sw := &streamWriter{c: c}
+	cfg.AudioStream = sw
+
+	resp, err := synt.Work(ctx, cfg)
+	if err != nil {
+		if sw.started {
+			// nothing to do - the status is already sent, the client gets incomplete audio
+			log.Ctx(ctx).Error().Err(err).Msg("can't finish stream")
+			return nil
+		}
+		if e := badReqError(err); e != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("can't process")
+			return echo.NewHTTPError(http.StatusBadRequest, e)
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("can't process")
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	if !sw.started { // no streaming processors configured
+		setSkippedStagesHeader(c, resp.SkippedStages)
+		_, err = sw.Write(resp.Audio)
+		return err
+	}
+	// the header is declared as a trailer by the started stream
+	setSkippedStagesHeader(c, resp.SkippedStages)
+	return nil
+}
+
+// streamWriter writes chunked wav data to the response
+type streamWriter struct {
+	c       echo.Context
+	started bool
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	resp := w.c.Response()
+	if !w.started {
+		resp.Header().Set(echo.HeaderContentType, mimeAudioWAV)
+		resp.Header().Set(headerTrailer, headerSkippedStages)
+		resp.WriteHeader(http.StatusOK)
+		w.started = true
+	}
+	n, err := resp.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err := http.NewResponseController(resp.Writer).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return n, err
+	}
+	return n, nil
+}
+
 func synthesizeInfo(data InfoGetter) func(echo.Context) error {
 	return func(c echo.Context) error {
 		ctx := c.Request().Context()
@@ -217,9 +394,9 @@ func synthesizeInfo(data InfoGetter) func(echo.Context) error {
 
 		resp, err := data.Provide(rID)
 		if err != nil {
-			if d, msg := badReqError(err); d {
+			if e := badReqError(err); e != nil {
 				log.Ctx(ctx).Warn().Err(err).Msg("can't process")
-				return echo.NewHTTPError(http.StatusBadRequest, msg)
+				return echo.NewHTTPError(http.StatusBadRequest, e)
 			}
 			log.Ctx(ctx).Error().Err(err).Msg("can't process")
 			return echo.NewHTTPError(http.StatusInternalServerError)
@@ -228,6 +405,30 @@ func synthesizeInfo(data InfoGetter) func(echo.Context) error {
 	}
 }
 
+func voices(data VoicesProvider) func(echo.Context) error {
+	return func(c echo.Context) error {
+		defer goapp.Estimate("Service voices method")()
+
+		return writeResponse(c, data.Voices())
+	}
+}
+
+func validateSSML(data SSMLValidator) func(echo.Context) error {
+	return func(c echo.Context) error {
+		defer goapp.Estimate("Service ssml validate method")()
+
+		inp, err := takeInput(c)
+		if err != nil {
+			log.Ctx(c.Request().Context()).Warn().Err(err).Send()
+			return err
+		}
+		if strings.TrimSpace(inp.Text) == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "No text")
+		}
+		return writeResponse(c, data.ValidateSSML(inp.Text, inp.Voice))
+	}
+}
+
 func takeInput(c echo.Context) (*api.Input, error) {
 	ctype := c.Request().Header.Get(echo.HeaderContentType)
 	if !strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
@@ -249,6 +450,30 @@ func writeResponseMsgPackOrJson(c echo.Context, contentType api.OutputContentTyp
 	return writeResponse(c, resp)
 }
 
+// writeResponseAudio writes audio bytes, the other fields go to the headers
+func writeResponseAudio(c echo.Context, format api.AudioFormatEnum, resp *api.Result) error {
+	h := c.Response().Header()
+	if resp.RequestID != "" {
+		h.Set(headerRequestID, resp.RequestID)
+	}
+	if resp.Text != "" {
+		if text := url.PathEscape(resp.Text); len(text) <= maxHeaderTextLen {
+			h.Set(headerText, text)
+		} else {
+			log.Ctx(c.Request().Context()).Warn().Int("len", len(text)).Msg("text too long for the header, skipped")
+			h.Set(headerTextOmitted, "true")
+		}
+	}
+	return c.Blob(http.StatusOK, getAudioMIME(format), resp.Audio)
+}
+
+// setSkippedStagesHeader marks the degraded response, it is sent as a trailer if the stream is already started
+func setSkippedStagesHeader(c echo.Context, stages []string) {
+	if len(stages) > 0 {
+		c.Response().Header().Set(headerSkippedStages, strings.Join(stages, ","))
+	}
+}
+
 func toBase64(ctx context.Context, b []byte) string {
 	_, span := utils.StartSpan(ctx, "processor.toBase64")
 	defer span.End()
@@ -257,47 +482,105 @@ func toBase64(ctx context.Context, b []byte) string {
 }
 
 func writeResponse(c echo.Context, resp interface{}) error {
+	return writeResponseCode(c, http.StatusOK, resp)
+}
+
+func writeResponseCode(c echo.Context, code int, resp interface{}) error {
 	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	c.Response().WriteHeader(http.StatusOK)
+	c.Response().WriteHeader(code)
 	enc := json.NewEncoder(c.Response())
 	enc.SetEscapeHTML(false)
 	return enc.Encode(resp)
 }
 
 func writeResponseMsgPack(c echo.Context, resp interface{}) error {
+	return writeResponseMsgPackCode(c, http.StatusOK, resp)
+}
+
+func writeResponseMsgPackCode(c echo.Context, code int, resp interface{}) error {
 	c.Response().Header().Set(echo.HeaderContentType, echo.MIMEApplicationMsgpack)
-	c.Response().WriteHeader(http.StatusOK)
+	c.Response().WriteHeader(code)
 	enc := msgpack.NewEncoder(c.Response())
 	return enc.Encode(resp)
 }
 
-func badReqError(err error) (bool, string) {
+// httpErrorHandler writes errors as api.Error in JSON or msgpack
+func httpErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	ctx := c.Request().Context()
+	he := &echo.HTTPError{}
+	if !errors.As(err, &he) {
+		he = echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	resp := toErrorResponse(he)
+	var wErr error
+	if c.Request().Method == http.MethodHead {
+		wErr = c.NoContent(he.Code)
+	} else if ct, _ := getOutputContentType(ctx, getHeader(c.Request(), echo.HeaderAccept)); ct == api.ContentMsgPack {
+		wErr = writeResponseMsgPackCode(c, he.Code, resp)
+	} else {
+		wErr = c.JSON(he.Code, resp)
+	}
+	if wErr != nil {
+		log.Ctx(ctx).Warn().Err(wErr).Msg("can't write error")
+	}
+}
+
+func toErrorResponse(he *echo.HTTPError) *api.Error {
+	switch m := he.Message.(type) {
+	case *api.Error:
+		return m
+	case string:
+		return &api.Error{Code: errorCode(he.Code), Message: m}
+	}
+	return &api.Error{Code: errorCode(he.Code), Message: http.StatusText(he.Code)}
+}
+
+// errorCode makes code from the HTTP status, e.g. BAD_REQUEST
+func errorCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+// badReqError returns the error response if the error is caused by the input, or nil
+func badReqError(err error) *api.Error {
 	if errors.Is(err, utils.ErrNoRecord) {
-		return true, "RequestID not found"
+		return &api.Error{Code: api.ErrCodeRequestNotFound, Message: "RequestID not found"}
 	}
 	if errors.Is(err, utils.ErrNoInput) {
-		return true, "No text"
+		return &api.Error{Code: api.ErrCodeNoText, Message: "No text"}
 	}
 	if errors.Is(err, utils.ErrTextDoesNotMatch) {
-		return true, "Original text does not match the modified"
+		return &api.Error{Code: api.ErrCodeTextDoesNotMatch, Message: "Original text does not match the modified"}
 	}
 	var errBA *utils.ErrBadAccent
 	if errors.As(err, &errBA) {
-		return true, fmt.Sprintf("Bad accents: %v", errBA.BadAccents)
+		return &api.Error{Code: api.ErrCodeBadAccent, Message: fmt.Sprintf("Bad accents: %v", errBA.BadAccents),
+			Details: &api.ErrorDetails{BadAccents: errBA.BadAccents}}
 	}
 	var errWTL *utils.ErrWordTooLong
 	if errors.As(err, &errWTL) {
-		return true, fmt.Sprintf("Word too long: '%s'", errWTL.Word)
+		return &api.Error{Code: api.ErrCodeWordTooLong, Message: fmt.Sprintf("Word too long: '%s'", errWTL.Word),
+			Details: &api.ErrorDetails{Word: errWTL.Word}}
 	}
 	var errTTL *utils.ErrTextTooLong
 	if errors.As(err, &errTTL) {
-		return true, fmt.Sprintf("Text too long: passed %d chars, max allowed %d", errTTL.Len, errTTL.Max)
+		return &api.Error{Code: api.ErrCodeTextTooLong,
+			Message: fmt.Sprintf("Text too long: passed %d chars, max allowed %d", errTTL.Len, errTTL.Max),
+			Details: &api.ErrorDetails{Length: errTTL.Len, MaxLength: errTTL.Max}}
 	}
 	var errBadS *utils.ErrBadSymbols
 	if errors.As(err, &errBadS) {
-		return true, fmt.Sprintf("Wrong symbols: '%s'", errBadS.Orig)
+		return &api.Error{Code: api.ErrCodeBadSymbols, Message: fmt.Sprintf("Wrong symbols: '%s'", errBadS.Orig),
+			Details: &api.ErrorDetails{Original: errBadS.Orig, Cleaned: errBadS.Cleaned}}
+	}
+	var errBadA *utils.ErrBadAudio
+	if errors.As(err, &errBadA) {
+		return &api.Error{Code: api.ErrCodeBadAudio, Message: fmt.Sprintf("Wrong audio '%s': %s", errBadA.Key, errBadA.Msg),
+			Details: &api.ErrorDetails{Audio: errBadA.Key}}
 	}
-	return false, ""
+	return nil
 }
 
 func live(data *Data) func(echo.Context) error {
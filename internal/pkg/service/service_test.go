@@ -17,6 +17,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -73,6 +75,32 @@ func Test_Returns(t *testing.T) {
 	assert.Equal(t, "olia", inp.Text)
 }
 
+func Test_Returns_SkippedStages(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav"),
+		SkippedStages: []string{"obscene", "clitics"}}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "obscene,clitics", resp.Header().Get(headerSkippedStages))
+	assert.Contains(t, resp.Body.String(), `"skippedStages":["obscene","clitics"]`)
+}
+
+func Test_Returns_NoSkippedStages(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3,
+		OutputContentType: api.ContentAudio}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav")}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "", resp.Header().Get(headerSkippedStages))
+	assert.NotContains(t, resp.Body.String(), "skippedStages")
+}
+
 func Test_Returns_MsgPack(t *testing.T) {
 	initTest(t)
 	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3,
@@ -88,6 +116,125 @@ func Test_Returns_MsgPack(t *testing.T) {
 	assert.NotContains(t, string(bytes), `audioAsString`)
 }
 
+func Test_Returns_Audio(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3,
+		OutputContentType: api.ContentAudio}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3"), RequestID: "rID", Text: "Labas rytas"}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "audio/mpeg", resp.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "rID", resp.Header().Get(headerRequestID))
+	assert.Equal(t, "Labas%20rytas", resp.Header().Get(headerText))
+	assert.Equal(t, "", resp.Header().Get(headerTextOmitted))
+	assert.Equal(t, "mp3", resp.Body.String())
+}
+
+func Test_Returns_Audio_LongText(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3,
+		OutputContentType: api.ContentAudio}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3"), RequestID: "rID",
+		Text: strings.Repeat("ą", maxHeaderTextLen/6+1)}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "rID", resp.Header().Get(headerRequestID))
+	assert.Equal(t, "", resp.Header().Get(headerText))
+	assert.Equal(t, "true", resp.Header().Get(headerTextOmitted))
+	assert.Equal(t, "mp3", resp.Body.String())
+}
+
+func TestCustom_Returns_Audio(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioULAW,
+		OutputContentType: api.ContentAudio}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("ulaw"), RequestID: "rID"}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesizeCustom?requestID=rID", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "audio/basic", resp.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "", resp.Header().Get(headerRequestID))
+	assert.Equal(t, "", resp.Header().Get(headerText))
+	assert.Equal(t, "ulaw", resp.Body.String())
+}
+
+func Test_Stream(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Run(func(args mock.Arguments) {
+		cfg := mocks.To[*api.TTSRequestConfig](args[0])
+		_, _ = cfg.AudioStream.Write([]byte("wav1"))
+		_, _ = cfg.AudioStream.Write([]byte("wav2"))
+	}).Return(&api.Result{Audio: []byte("wav")}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize?stream=true", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "audio/wav", resp.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "wav1wav2", resp.Body.String())
+	assert.True(t, resp.Flushed)
+}
+
+func Test_Stream_SkippedStagesTrailer(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Run(func(args mock.Arguments) {
+		cfg := mocks.To[*api.TTSRequestConfig](args[0])
+		_, _ = cfg.AudioStream.Write([]byte("wav1"))
+	}).Return(&api.Result{SkippedStages: []string{"obscene"}}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize?stream=true", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "wav1", resp.Body.String())
+	assert.Equal(t, "obscene", resp.Result().Trailer.Get(headerSkippedStages))
+}
+
+func Test_Stream_NoStreamWrites(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("wav")}, nil)
+
+	req := httptest.NewRequest("POST", "/synthesize?stream=true", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "wav", resp.Body.String())
+}
+
+func Test_Stream_Fail(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, utils.NewErrWordTooLong("haha"))
+
+	req := httptest.NewRequest("POST", "/synthesize?stream=true", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, 400)
+}
+
+func Test_Stream_FailAfterStart(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioWAV,
+		OutputContentType: api.ContentAudioStream}, nil)
+	synthesizerMock.On("Work", mock.Anything).Run(func(args mock.Arguments) {
+		cfg := mocks.To[*api.TTSRequestConfig](args[0])
+		_, _ = cfg.AudioStream.Write([]byte("wav1"))
+	}).Return(nil, errors.New("haha"))
+
+	req := httptest.NewRequest("POST", "/synthesize?stream=true", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, 200)
+	assert.Equal(t, "wav1", resp.Body.String())
+}
+
 func Test_Fail(t *testing.T) {
 	initTest(t)
 	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia1", OutputFormat: api.AudioMP3}, nil)
@@ -112,16 +259,16 @@ func Test_FailConfigure(t *testing.T) {
 	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "olia"}))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	resp := testCode(t, req, 400)
-	assert.Equal(t, `{"message":"No format mmp"}`+"\n", resp.Body.String())
+	assert.Equal(t, `{"code":"BAD_REQUEST","message":"No format mmp"}`+"\n", resp.Body.String())
 }
 
 func Test_SSMLError(t *testing.T) {
 	initTest(t)
-	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, &ssml.ErrParse{Pos: 10, Msg: "multiple <speak>"})
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, &ssml.ErrParse{Pos: 19, Line: 1, Column: 20, Msg: "multiple <speak>"})
 	req := httptest.NewRequest("POST", "/synthesize", toReader(api.Input{Text: "<speak>olia</speak><speak>"}))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	resp := testCode(t, req, 400)
-	assert.Equal(t, `{"message":"ssml: 10: multiple \u003cspeak\u003e"}`+"\n", resp.Body.String())
+	assert.Equal(t, `{"code":"BAD_REQUEST","message":"ssml: 1:20: multiple \u003cspeak\u003e"}`+"\n", resp.Body.String())
 }
 
 func Test_FailOnWrongInput(t *testing.T) {
@@ -227,7 +374,7 @@ func TestCustom_FailConfigure(t *testing.T) {
 	req := httptest.NewRequest("POST", "/synthesizeCustom?requestID=1", toReader(api.Input{Text: "olia"}))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	resp := testCode(t, req, 400)
-	assert.Equal(t, `{"message":"No format mmp"}`+"\n", resp.Body.String())
+	assert.Equal(t, `{"code":"BAD_REQUEST","message":"No format mmp"}`+"\n", resp.Body.String())
 }
 
 func TestCustom_FailOnWrongInput(t *testing.T) {
@@ -246,31 +393,177 @@ func TestCustom_FailNoRequest(t *testing.T) {
 
 func TestBadReqError(t *testing.T) {
 	tests := []struct {
-		v  error
-		e  bool
-		es string
+		v    error
+		code string
+		es   string
+		d    *api.ErrorDetails
 	}{
-		{v: errors.New("olia"), e: false, es: ""},
-		{v: utils.ErrNoRecord, e: true, es: "RequestID not found"},
-		{v: utils.ErrTextDoesNotMatch, e: true, es: "Original text does not match the modified"},
-		{v: utils.NewErrBadAccent([]string{"olia"}), e: true, es: "Bad accents: [olia]"},
-		{v: errors.Wrap(utils.NewErrBadAccent([]string{"olia"}), "test"), e: true, es: "Bad accents: [olia]"},
-		{v: utils.NewErrWordTooLong("oliaaa"), e: true, es: "Word too long: 'oliaaa'"},
-		{v: errors.Wrap(utils.NewErrWordTooLong("oliaaa"), "err"), e: true, es: "Word too long: 'oliaaa'"},
-		{v: errors.Wrap(utils.ErrNoInput, "err"), e: true, es: "No text"},
-		{v: errors.Wrap(utils.NewErrTextTooLong(300, 200), "err"), e: true, es: "Text too long: passed 300 chars, max allowed 200"},
-		{v: errors.Wrap(utils.NewErrBadSymbols("olia", "ooo2"), "err"), e: true, es: "Wrong symbols: 'olia'"},
+		{v: errors.New("olia")},
+		{v: utils.ErrNoRecord, code: api.ErrCodeRequestNotFound, es: "RequestID not found"},
+		{v: utils.ErrTextDoesNotMatch, code: api.ErrCodeTextDoesNotMatch, es: "Original text does not match the modified"},
+		{v: utils.NewErrBadAccent([]string{"olia"}), code: api.ErrCodeBadAccent, es: "Bad accents: [olia]",
+			d: &api.ErrorDetails{BadAccents: []string{"olia"}}},
+		{v: errors.Wrap(utils.NewErrBadAccent([]string{"olia"}), "test"), code: api.ErrCodeBadAccent, es: "Bad accents: [olia]",
+			d: &api.ErrorDetails{BadAccents: []string{"olia"}}},
+		{v: utils.NewErrWordTooLong("oliaaa"), code: api.ErrCodeWordTooLong, es: "Word too long: 'oliaaa'",
+			d: &api.ErrorDetails{Word: "oliaaa"}},
+		{v: errors.Wrap(utils.NewErrWordTooLong("oliaaa"), "err"), code: api.ErrCodeWordTooLong, es: "Word too long: 'oliaaa'",
+			d: &api.ErrorDetails{Word: "oliaaa"}},
+		{v: errors.Wrap(utils.ErrNoInput, "err"), code: api.ErrCodeNoText, es: "No text"},
+		{v: errors.Wrap(utils.NewErrTextTooLong(300, 200), "err"), code: api.ErrCodeTextTooLong,
+			es: "Text too long: passed 300 chars, max allowed 200", d: &api.ErrorDetails{Length: 300, MaxLength: 200}},
+		{v: errors.Wrap(utils.NewErrBadSymbols("olia", "ooo2"), "err"), code: api.ErrCodeBadSymbols, es: "Wrong symbols: 'olia'",
+			d: &api.ErrorDetails{Original: "olia", Cleaned: "ooo2"}},
+		{v: errors.Wrap(utils.NewErrBadAudio("jingle", "not found"), "err"), code: api.ErrCodeBadAudio,
+			es: "Wrong audio 'jingle': not found", d: &api.ErrorDetails{Audio: "jingle"}},
 	}
 
 	for i, tc := range tests {
 		t.Run("", func(t *testing.T) {
-			v, str := badReqError(tc.v)
-			assert.Equal(t, tc.e, v, "Fail %d", i)
-			assert.Equal(t, tc.es, str, "Fail %d", i)
+			v := badReqError(tc.v)
+			if tc.code == "" {
+				assert.Nil(t, v, "Fail %d", i)
+				return
+			}
+			require.NotNil(t, v, "Fail %d", i)
+			assert.Equal(t, tc.code, v.Code, "Fail %d", i)
+			assert.Equal(t, tc.es, v.Message, "Fail %d", i)
+			assert.Equal(t, tc.d, v.Details, "Fail %d", i)
 		})
 	}
 }
 
+func TestErrorHandler_NotFound(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/olia", nil)
+	resp := testCode(t, req, http.StatusNotFound)
+	assert.Equal(t, `{"code":"NOT_FOUND","message":"Not Found"}`+"\n", resp.Body.String())
+}
+
+func TestErrorHandler_MsgPack(t *testing.T) {
+	initTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, utils.NewErrWordTooLong("haha"))
+	req := httptest.NewRequest(http.MethodPost, "/synthesize", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationMsgpack)
+	resp := testCode(t, req, http.StatusBadRequest)
+	var res api.Error
+	require.Nil(t, msgpack.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, api.Error{Code: api.ErrCodeWordTooLong, Message: "Word too long: 'haha'",
+		Details: &api.ErrorDetails{Word: "haha"}}, res)
+}
+
+func Test_errorCode(t *testing.T) {
+	assert.Equal(t, api.ErrCodeBadRequest, errorCode(http.StatusBadRequest))
+	assert.Equal(t, api.ErrCodeNotFound, errorCode(http.StatusNotFound))
+	assert.Equal(t, api.ErrCodeInternal, errorCode(http.StatusInternalServerError))
+	assert.Equal(t, "METHOD_NOT_ALLOWED", errorCode(http.StatusMethodNotAllowed))
+}
+
+func TestVoices_Returns(t *testing.T) {
+	initTest(t)
+	vMock := &mockVoicesProvider{}
+	tData.VoicesData = vMock
+	tEcho = initRoutes(tData)
+	vMock.On("Voices").Return(&api.VoicesResult{Default: "v1",
+		Voices: []*api.Voice{{Name: "v1", Voice: "v1", VoiceMetadata: api.VoiceMetadata{Language: "lt"}}}})
+
+	req := httptest.NewRequest(http.MethodGet, "/voices", nil)
+	resp := testCode(t, req, http.StatusOK)
+	assert.Equal(t, `{"default":"v1","voices":[{"name":"v1","voice":"v1","language":"lt"}]}`,
+		strings.TrimSpace(resp.Body.String()))
+}
+
+func TestVoices_NoRoute(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/voices", nil)
+	testCode(t, req, http.StatusNotFound)
+}
+
+func TestValidateSSML_Returns(t *testing.T) {
+	initTest(t)
+	vMock := &mockSSMLValidator{}
+	tData.SSMLValidator = vMock
+	tEcho = initRoutes(tData)
+	vMock.On("ValidateSSML", "<speak>", "aaa").Return(&api.SSMLValidateResult{Errors: []*api.SSMLIssue{
+		{Line: 1, Column: 8, Code: "NO_SPEAK", Message: "no </speak>"}}, Warnings: []*api.SSMLIssue{}})
+
+	req := httptest.NewRequest(http.MethodPost, "/ssml/validate", toReader(api.Input{Text: "<speak>", Voice: "aaa"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, http.StatusOK)
+	assert.Equal(t, `{"valid":false,"errors":[{"line":1,"column":8,"code":"NO_SPEAK","message":"no </speak>"}],"warnings":[]}`,
+		strings.TrimSpace(resp.Body.String()))
+}
+
+func TestValidateSSML_Fail(t *testing.T) {
+	initTest(t)
+	tData.SSMLValidator = &mockSSMLValidator{}
+	tEcho = initRoutes(tData)
+
+	req := httptest.NewRequest(http.MethodPost, "/ssml/validate", toReader(api.Input{Text: " "}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusBadRequest)
+
+	req = httptest.NewRequest(http.MethodPost, "/ssml/validate", strings.NewReader("<speak/>"))
+	testCode(t, req, http.StatusBadRequest)
+}
+
+func TestValidateSSML_NoRoute(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodPost, "/ssml/validate", toReader(api.Input{Text: "<speak/>"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusNotFound)
+}
+
+func TestAnalyze_Returns(t *testing.T) {
+	initTest(t)
+	aMock := &mockAnalyzer{}
+	tData.Analyze = &AnalyzeData{Processor: aMock, Configurator: cnfMock}
+	tEcho = initRoutes(tData)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia"}, nil)
+	aMock.On("Analyze", mock.Anything).Return(&api.AnalyzeResult{Words: []*api.AnalyzeWord{{Type: "WORD", Word: "olia",
+		Accent: 101, Transcription: "o l i a"}}}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/analyze", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp := testCode(t, req, http.StatusOK)
+	assert.Equal(t, `{"words":[{"type":"WORD","word":"olia","part":0,"accent":101,"transcription":"o l i a"}]}`+"\n",
+		resp.Body.String())
+	assert.Equal(t, "olia", mocks.To[*api.TTSRequestConfig](aMock.Calls[0].Arguments[0]).Text)
+}
+
+func TestAnalyze_Fail(t *testing.T) {
+	initTest(t)
+	aMock := &mockAnalyzer{}
+	tData.Analyze = &AnalyzeData{Processor: aMock, Configurator: cnfMock}
+	tEcho = initRoutes(tData)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, errors.New("olia")).Once()
+	req := httptest.NewRequest(http.MethodPost, "/analyze", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusBadRequest)
+
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{Text: "olia"}, nil)
+	aMock.On("Analyze", mock.Anything).Return(nil, utils.NewErrWordTooLong("olia")).Once()
+	req = httptest.NewRequest(http.MethodPost, "/analyze", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	tResp = httptest.NewRecorder()
+	testCode(t, req, http.StatusBadRequest)
+
+	tResp = httptest.NewRecorder()
+	aMock.On("Analyze", mock.Anything).Return(nil, errors.New("olia"))
+	req = httptest.NewRequest(http.MethodPost, "/analyze", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusInternalServerError)
+}
+
+func TestAnalyze_NoRoute(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodPost, "/analyze", toReader(api.Input{Text: "olia"}))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	testCode(t, req, http.StatusNotFound)
+}
+
 func TestInfo_Returns(t *testing.T) {
 	initTest(t)
 	req := httptest.NewRequest(http.MethodGet, "/request/olia1", nil)
@@ -352,6 +645,27 @@ func (m *mockConfigurator) Configure(ctx context.Context, req *http.Request, in
 	return mocks.To[*api.TTSRequestConfig](args.Get(0)), args.Error(1)
 }
 
+type mockVoicesProvider struct{ mock.Mock }
+
+func (m *mockVoicesProvider) Voices() *api.VoicesResult {
+	args := m.Called()
+	return mocks.To[*api.VoicesResult](args.Get(0))
+}
+
+type mockSSMLValidator struct{ mock.Mock }
+
+func (m *mockSSMLValidator) ValidateSSML(text, voice string) *api.SSMLValidateResult {
+	args := m.Called(text, voice)
+	return mocks.To[*api.SSMLValidateResult](args.Get(0))
+}
+
+type mockAnalyzer struct{ mock.Mock }
+
+func (m *mockAnalyzer) Analyze(ctx context.Context, cfg *api.TTSRequestConfig) (*api.AnalyzeResult, error) {
+	args := m.Called(cfg)
+	return mocks.To[*api.AnalyzeResult](args.Get(0)), args.Error(1)
+}
+
 type mockInfoGetter struct{ mock.Mock }
 
 func (m *mockInfoGetter) Provide(ID string) (*api.InfoResult, error) {
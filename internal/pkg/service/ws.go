@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/airenas/go-app/pkg/goapp"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"golang.org/x/net/websocket"
+)
+
+// SentenceSplitter splits text to sentences
+type SentenceSplitter interface {
+	// Split returns sentences, the last one may be unfinished
+	Split(context.Context, string) ([]string, error)
+}
+
+// WSData is websocket synthesis configuration
+type WSData struct {
+	splitter    SentenceSplitter
+	idleTimeout time.Duration
+	maxBuffer   int
+	origins     map[string]bool // allowed browser origins, '*' allows any
+}
+
+// NewWSData creates websocket synthesis configuration
+func NewWSData(splitter SentenceSplitter, cfg *viper.Viper) (*WSData, error) {
+	if splitter == nil {
+		return nil, errors.New("no sentence splitter")
+	}
+	if cfg == nil {
+		return nil, errors.New("no ws config")
+	}
+	res := &WSData{splitter: splitter}
+	res.idleTimeout = cfg.GetDuration("idleTimeout")
+	if res.idleTimeout <= 0 {
+		res.idleTimeout = time.Minute
+	}
+	res.maxBuffer = cfg.GetInt("maxBuffer")
+	if res.maxBuffer <= 0 {
+		res.maxBuffer = 2000
+	}
+	res.origins = make(map[string]bool)
+	for _, o := range cfg.GetStringSlice("origins") {
+		res.origins[strings.TrimSuffix(strings.TrimSpace(o), "/")] = true
+	}
+	goapp.Log.Info().Str("idleTimeout", res.idleTimeout.String()).Int("maxBuffer", res.maxBuffer).
+		Strs("origins", cfg.GetStringSlice("origins")).Msg("WS initialized")
+	return res, nil
+}
+
+const (
+	wsTypeConfig   = "config"
+	wsTypeText     = "text"
+	wsTypeFlush    = "flush"
+	wsTypeSentence = "sentence"
+	wsTypeFlushed  = "flushed"
+	wsTypeError    = "error"
+
+	// the tagger is not called for the fragments without these symbols
+	wsSentenceEndSymbols = ".!?…;:\n"
+	// the last split sentence is synthesized without waiting for more text if it ends with one of these symbols
+	wsSentenceTerminators = ".!?…\n"
+)
+
+func synthesizeWS(data *PrData, ws *WSData) func(echo.Context) error {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		defer goapp.Estimate("Service synthesize ws method")()
+
+		server := websocket.Server{
+			Handshake: func(_ *websocket.Config, r *http.Request) error { return ws.checkOrigin(r) },
+			Handler: func(conn *websocket.Conn) {
+				defer conn.Close()
+				s := &wsSession{data: data, ws: ws, req: c.Request(), conn: conn, tasks: make(chan wsTask, 10)}
+				s.run(ctx)
+			},
+		}
+		server.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
+}
+
+// checkOrigin allows the clients without Origin, e.g. not browsers, and the configured origins
+func (ws *WSData) checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" || ws.origins["*"] || ws.origins[strings.TrimSuffix(origin, "/")] {
+		return nil
+	}
+	log.Ctx(r.Context()).Warn().Str("origin", goapp.Sanitize(origin)).Msg("ws origin not allowed")
+	return fmt.Errorf("origin %s not allowed", origin)
+}
+
+type wsTask struct {
+	input api.Input
+	text  string
+	flush bool
+	err   *api.Error
+}
+
+// wsSession reads text fragments and synthesizes the finished sentences in order
+type wsSession struct {
+	data  *PrData
+	ws    *WSData
+	req   *http.Request
+	conn  *websocket.Conn
+	tasks chan wsTask
+
+	input    api.Input // is used only by the reader
+	buf      strings.Builder
+	sentence int
+}
+
+func (s *wsSession) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// hijacked connection keeps the server's deadlines
+	_ = s.conn.SetDeadline(time.Time{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		s.work(ctx)
+	}()
+
+	err := s.read(ctx)
+	if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("ws read")
+	}
+	// the client is gone, drop not finished sentences
+	cancel()
+	close(s.tasks)
+	wg.Wait()
+	log.Ctx(ctx).Info().Int("sentences", s.sentence).Msg("WS session finished")
+}
+
+func (s *wsSession) read(ctx context.Context) error {
+	for {
+		if err := s.conn.SetReadDeadline(time.Now().Add(s.ws.idleTimeout)); err != nil {
+			return err
+		}
+		var data string
+		if err := websocket.Message.Receive(s.conn, &data); err != nil {
+			return err
+		}
+		var msg api.WSRequest
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("can't decode ws message")
+			s.addTask(ctx, wsTask{err: &api.Error{Code: api.ErrCodeBadRequest, Message: "Cannot decode input"}})
+			continue
+		}
+		for _, t := range s.process(ctx, &msg) {
+			if !s.addTask(ctx, t) {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (s *wsSession) process(ctx context.Context, msg *api.WSRequest) []wsTask {
+	switch msg.Type {
+	case wsTypeConfig:
+		if err := s.configure(ctx, msg.Config); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("wrong ws config")
+			return []wsTask{{err: &api.Error{Code: api.ErrCodeBadRequest, Message: err.Error()}}}
+		}
+		return nil
+	case wsTypeText:
+		return s.addText(ctx, msg.Text)
+	case wsTypeFlush:
+		res := []wsTask{{input: s.input, text: s.buf.String(), flush: true}}
+		s.buf.Reset()
+		return res
+	}
+	log.Ctx(ctx).Warn().Str("type", goapp.Sanitize(msg.Type)).Msg("unknown ws message")
+	return []wsTask{{err: &api.Error{Code: api.ErrCodeBadRequest, Message: "Unknown message type '" + msg.Type + "'"}}}
+}
+
+func (s *wsSession) configure(ctx context.Context, inp *api.Input) error {
+	if inp == nil {
+		return errors.New("no config")
+	}
+	if inp.TextType == "ssml" {
+		return errors.New("SSML is not supported")
+	}
+	test := *inp
+	test.Text = ""
+	// validate params
+	if _, err := s.data.Configurator.Configure(ctx, s.req, &test); err != nil {
+		return err
+	}
+	s.input = test
+	return nil
+}
+
+func (s *wsSession) addText(ctx context.Context, text string) []wsTask {
+	s.buf.WriteString(text)
+	if !strings.ContainsAny(text, wsSentenceEndSymbols) && s.buf.Len() <= s.ws.maxBuffer {
+		return nil
+	}
+	sentences, err := s.ws.splitter.Split(ctx, s.buf.String())
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("can't split")
+		return []wsTask{{err: &api.Error{Code: api.ErrCodeInternal, Message: "Internal error"}}}
+	}
+	if endsSentence(sentences[len(sentences)-1]) {
+		sentences = append(sentences, "")
+	}
+	var res []wsTask
+	for _, sentence := range sentences[:len(sentences)-1] {
+		res = append(res, wsTask{input: s.input, text: sentence})
+	}
+	s.buf.Reset()
+	last := sentences[len(sentences)-1]
+	if len(last) > s.ws.maxBuffer {
+		log.Ctx(ctx).Warn().Int("len", len(last)).Msg("no sentence end")
+		return append(res, wsTask{err: &api.Error{Code: api.ErrCodeTextTooLong, Message: "Text too long without sentence end"}})
+	}
+	s.buf.WriteString(last)
+	return res
+}
+
+// endsSentence checks if the text ends with a sentence terminator, the trailing spaces are ignored
+func endsSentence(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimRight(text, " \t"))
+	return r != utf8.RuneError && strings.ContainsRune(wsSentenceTerminators, r)
+}
+
+func (s *wsSession) addTask(ctx context.Context, t wsTask) bool {
+	select {
+	case s.tasks <- t:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *wsSession) work(ctx context.Context) {
+	for t := range s.tasks {
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := s.do(ctx, t); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("ws write")
+			return
+		}
+	}
+}
+
+func (s *wsSession) do(ctx context.Context, t wsTask) error {
+	if t.err != nil {
+		return s.send(&api.WSResponse{Type: wsTypeError, Error: t.err})
+	}
+	if strings.TrimSpace(t.text) != "" {
+		s.sentence++
+		if err := s.synthesize(ctx, t.input, t.text); err != nil {
+			return err
+		}
+	}
+	if t.flush {
+		return s.send(&api.WSResponse{Type: wsTypeFlushed})
+	}
+	return nil
+}
+
+func (s *wsSession) synthesize(ctx context.Context, inp api.Input, text string) error {
+	inp.Text = text
+
+	cfg, err := s.data.Configurator.Configure(ctx, s.req, &inp)
+	if err != nil {
+		log.Ctx(ctx).Warn().Msg("Cannot prepare request config " + err.Error())
+		return s.send(&api.WSResponse{Type: wsTypeError, Sentence: s.sentence,
+			Error: &api.Error{Code: api.ErrCodeBadRequest, Message: err.Error()}})
+	}
+	resp, err := s.data.Processor.Work(ctx, cfg)
+	if err != nil {
+		e := badReqError(err)
+		if e != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("can't process")
+		} else {
+			log.Ctx(ctx).Error().Err(err).Msg("can't process")
+			e = &api.Error{Code: api.ErrCodeInternal, Message: "Internal error"}
+		}
+		return s.send(&api.WSResponse{Type: wsTypeError, Sentence: s.sentence, Error: e})
+	}
+	if err := s.send(&api.WSResponse{Type: wsTypeSentence, Sentence: s.sentence, Text: resp.Text,
+		SpeechMarks: resp.SpeechMarks, SkippedStages: resp.SkippedStages}); err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.conn, resp.Audio)
+}
+
+func (s *wsSession) send(resp *api.WSResponse) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.conn, string(b))
+}
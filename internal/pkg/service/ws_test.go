@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/airenas/tts-line/internal/pkg/test/mocks"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+var splitterMock *mockSentenceSplitter
+
+func initWSTest(t *testing.T) *websocket.Conn {
+	t.Helper()
+	server := initWSServer(t)
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/synthesize/ws", "", "http://a.lt")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	return conn
+}
+
+func initWSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	initTest(t)
+	splitterMock = &mockSentenceSplitter{}
+	var err error
+	tData.WS, err = NewWSData(splitterMock, test.NewConfig(t, "maxBuffer: 20\norigins: [http://a.lt]"))
+	require.Nil(t, err)
+	tEcho = initRoutes(tData)
+	server := httptest.NewServer(tEcho)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewWSData(t *testing.T) {
+	ws, err := NewWSData(&mockSentenceSplitter{}, test.NewConfig(t, ""))
+	require.Nil(t, err)
+	assert.Equal(t, time.Minute, ws.idleTimeout)
+	assert.Equal(t, 2000, ws.maxBuffer)
+	assert.Empty(t, ws.origins)
+	ws, _ = NewWSData(&mockSentenceSplitter{}, test.NewConfig(t, "idleTimeout: 10s\nmaxBuffer: 10\norigins: [http://a.lt/, '*']"))
+	assert.Equal(t, 10*time.Second, ws.idleTimeout)
+	assert.Equal(t, 10, ws.maxBuffer)
+	assert.Equal(t, map[string]bool{"http://a.lt": true, "*": true}, ws.origins)
+	_, err = NewWSData(nil, test.NewConfig(t, ""))
+	assert.NotNil(t, err)
+	_, err = NewWSData(&mockSentenceSplitter{}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestWS_NoRoute(t *testing.T) {
+	initTest(t)
+	req := httptest.NewRequest(http.MethodGet, "/synthesize/ws", nil)
+	testCode(t, req, http.StatusNotFound)
+}
+
+func TestWS_Synthesize(t *testing.T) {
+	conn := initWSTest(t)
+	texts := make(chan *api.Input, 10)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		texts <- mocks.To[*api.Input](args[1])
+	}).Return(&api.TTSRequestConfig{OutputFormat: api.AudioMP3}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3"), Text: "olia",
+		SpeechMarks: []*api.SpeechMark{{TimeInMillis: 10, Type: "word", Value: "olia"}}}, nil)
+	splitterMock.On("Split", "Labas. Kaip").Return([]string{"Labas.", " Kaip"}, nil)
+
+	send(t, conn, api.WSRequest{Type: "config", Config: &api.Input{Voice: "astra", Text: "ignored"}})
+	inp := <-texts
+	assert.Equal(t, "astra", inp.Voice)
+	assert.Equal(t, "", inp.Text)
+
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas"})
+	send(t, conn, api.WSRequest{Type: "text", Text: ". Kaip"})
+	resp := receive(t, conn)
+	assert.Equal(t, api.WSResponse{Type: "sentence", Sentence: 1, Text: "olia",
+		SpeechMarks: []*api.SpeechMark{{TimeInMillis: 10, Type: "word", Value: "olia"}}}, resp)
+	assert.Equal(t, "mp3", receiveAudio(t, conn))
+	inp = <-texts
+	assert.Equal(t, "Labas.", inp.Text)
+	assert.Equal(t, "astra", inp.Voice)
+
+	send(t, conn, api.WSRequest{Type: "flush"})
+	resp = receive(t, conn)
+	assert.Equal(t, 2, resp.Sentence)
+	assert.Equal(t, "mp3", receiveAudio(t, conn))
+	assert.Equal(t, " Kaip", (<-texts).Text)
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+	splitterMock.AssertNumberOfCalls(t, "Split", 1)
+}
+
+func TestWS_SentenceEnd(t *testing.T) {
+	conn := initWSTest(t)
+	texts := make(chan string, 10)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		texts <- mocks.To[*api.Input](args[1]).Text
+	}).Return(&api.TTSRequestConfig{OutputFormat: api.AudioMP3}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3")}, nil)
+	splitterMock.On("Split", "Labas. ").Return([]string{"Labas. "}, nil)
+
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas. "})
+	assert.Equal(t, api.WSResponse{Type: "sentence", Sentence: 1}, receive(t, conn))
+	assert.Equal(t, "mp3", receiveAudio(t, conn))
+	assert.Equal(t, "Labas. ", <-texts)
+
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+}
+
+func TestWS_Origin(t *testing.T) {
+	server := initWSServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/synthesize/ws"
+	conn, err := websocket.Dial(url, "", "http://a.lt")
+	require.Nil(t, err)
+	_ = conn.Close()
+	_, err = websocket.Dial(url, "", "http://b.lt")
+	assert.NotNil(t, err)
+}
+
+func Test_endsSentence(t *testing.T) {
+	for _, s := range []string{"a.", "a? ", "a…", "a\n", "a!\t"} {
+		assert.True(t, endsSentence(s), s)
+	}
+	for _, s := range []string{"", " ", "a", "a;", "a:", "a. b"} {
+		assert.False(t, endsSentence(s), s)
+	}
+}
+
+func TestWS_SkippedStages(t *testing.T) {
+	conn := initWSTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(&api.Result{Audio: []byte("mp3"), SkippedStages: []string{"obscene"}}, nil)
+
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas"})
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, api.WSResponse{Type: "sentence", Sentence: 1, SkippedStages: []string{"obscene"}}, receive(t, conn))
+	assert.Equal(t, "mp3", receiveAudio(t, conn))
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+}
+
+func TestWS_FlushEmpty(t *testing.T) {
+	conn := initWSTest(t)
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+	synthesizerMock.AssertNotCalled(t, "Work", mock.Anything)
+}
+
+func TestWS_FailWork(t *testing.T) {
+	conn := initWSTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(&api.TTSRequestConfig{}, nil)
+	synthesizerMock.On("Work", mock.Anything).Return(nil, utils.NewErrWordTooLong("haha")).Once()
+	synthesizerMock.On("Work", mock.Anything).Return(nil, errors.New("haha"))
+
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas"})
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, api.WSResponse{Type: "error", Sentence: 1, Error: &api.Error{Code: api.ErrCodeWordTooLong,
+		Message: "Word too long: 'haha'", Details: &api.ErrorDetails{Word: "haha"}}}, receive(t, conn))
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas"})
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, api.WSResponse{Type: "error", Sentence: 2, Error: &api.Error{Code: api.ErrCodeInternal,
+		Message: "Internal error"}}, receive(t, conn))
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+}
+
+func TestWS_FailConfig(t *testing.T) {
+	conn := initWSTest(t)
+	cnfMock.On("Configure", mock.Anything, mock.Anything).Return(nil, errors.New("wrong voice"))
+	send(t, conn, api.WSRequest{Type: "config", Config: &api.Input{Voice: "olia"}})
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeBadRequest, Message: "wrong voice"}}, receive(t, conn))
+	send(t, conn, api.WSRequest{Type: "config", Config: &api.Input{TextType: "ssml"}})
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeBadRequest, Message: "SSML is not supported"}}, receive(t, conn))
+	send(t, conn, api.WSRequest{Type: "config"})
+	assert.Equal(t, "error", receive(t, conn).Type)
+}
+
+func TestWS_FailMessage(t *testing.T) {
+	conn := initWSTest(t)
+	require.Nil(t, websocket.Message.Send(conn, "{olia"))
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeBadRequest, Message: "Cannot decode input"}}, receive(t, conn))
+	send(t, conn, api.WSRequest{Type: "olia"})
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeBadRequest,
+		Message: "Unknown message type 'olia'"}}, receive(t, conn))
+}
+
+func TestWS_FailSplit(t *testing.T) {
+	conn := initWSTest(t)
+	splitterMock.On("Split", mock.Anything).Return(nil, errors.New("olia"))
+	send(t, conn, api.WSRequest{Type: "text", Text: "Labas."})
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeInternal, Message: "Internal error"}}, receive(t, conn))
+}
+
+func TestWS_FailTooLong(t *testing.T) {
+	conn := initWSTest(t)
+	text := strings.Repeat("a", 21)
+	splitterMock.On("Split", text).Return([]string{text}, nil)
+	send(t, conn, api.WSRequest{Type: "text", Text: text})
+	assert.Equal(t, api.WSResponse{Type: "error", Error: &api.Error{Code: api.ErrCodeTextTooLong,
+		Message: "Text too long without sentence end"}}, receive(t, conn))
+	send(t, conn, api.WSRequest{Type: "flush"})
+	assert.Equal(t, "flushed", receive(t, conn).Type)
+	synthesizerMock.AssertNotCalled(t, "Work", mock.Anything)
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg api.WSRequest) {
+	t.Helper()
+	b, err := json.Marshal(msg)
+	require.Nil(t, err)
+	require.Nil(t, websocket.Message.Send(conn, string(b)))
+}
+
+func receive(t *testing.T, conn *websocket.Conn) api.WSResponse {
+	t.Helper()
+	var res api.WSResponse
+	require.Nil(t, websocket.JSON.Receive(conn, &res))
+	return res
+}
+
+func receiveAudio(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	var res []byte
+	require.Nil(t, websocket.Message.Receive(conn, &res))
+	return string(res)
+}
+
+type mockSentenceSplitter struct{ mock.Mock }
+
+func (m *mockSentenceSplitter) Split(ctx context.Context, text string) ([]string, error) {
+	args := m.Called(text)
+	return mocks.To[[]string](args.Get(0)), args.Error(1)
+}
@@ -0,0 +1,83 @@
+package synthesizer
+
+import (
+	"context"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/pkg/ssml"
+)
+
+// Analyze runs the processors and returns the results of the pipeline stages for each word.
+// The worker is expected to be configured without the acoustic model and vocoder.
+// Nothing is saved, so the result has no request ID
+func (mw *MainWorker) Analyze(ctx context.Context, input *api.TTSRequestConfig) (*api.AnalyzeResult, error) {
+	data, err := mw.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	res := &api.AnalyzeResult{Words: []*api.AnalyzeWord{}, SkippedStages: data.SkippedStages}
+	part := 0
+	add := func(d *TTSData, ssmlPart int) {
+		if len(d.Parts) == 0 { // not split
+			for _, w := range d.Words {
+				res.Words = append(res.Words, mapAnalyzeWord(w, part, ssmlPart))
+			}
+			return
+		}
+		for _, p := range d.Parts {
+			for _, w := range p.Words {
+				res.Words = append(res.Words, mapAnalyzeWord(w, part, ssmlPart))
+			}
+			part++
+		}
+	}
+	if len(data.SSMLParts) == 0 {
+		add(data, 0)
+	}
+	for i, p := range data.SSMLParts {
+		add(p, i)
+	}
+	return res, nil
+}
+
+func mapAnalyzeWord(w *ProcessedWord, part, ssmlPart int) *api.AnalyzeWord {
+	tgw := w.Tagged
+	res := &api.AnalyzeWord{Type: tgw.TypeStr(), Part: part, Mi: tgw.Mi, Lemma: tgw.Lemma,
+		UserAccent: w.UserAccent, UserTranscription: w.UserTranscription, UserSyllables: w.UserSyllables,
+		Obscene: w.Obscene, TransliteratedFrom: w.TransliteratedFrom, Transcription: w.Transcription}
+	if tgw.IsWord() {
+		res.Word = tgw.Word
+		res.Accent = GetTranscriberAccent(w)
+	} else {
+		res.Separator = tgw.Separator
+	}
+	if w.AccentVariant != nil {
+		res.AccentVariant = mapAccentVariant(w.AccentVariant)
+	}
+	for _, v := range w.AccentVariants {
+		res.AccentVariants = append(res.AccentVariants, mapAccentVariant(&v))
+	}
+	switch w.Clitic.Type {
+	case CliticsNone:
+		res.Clitic = &api.Clitic{Type: "NONE"}
+	case CliticsCustom:
+		res.Clitic = &api.Clitic{Type: "CUSTOM", Accent: w.Clitic.Accent}
+	}
+	if w.NERType != NERRegular {
+		res.NER = w.NERType.String()
+	}
+	if w.FromWord != nil {
+		res.FromWord = w.FromWord.Word
+	}
+	if tp := w.TextPart; tp != nil {
+		res.TextPart = &api.AnalyzeText{SSMLPart: ssmlPart, Text: tp.Text, Accented: tp.Accented, Language: tp.Language}
+		if tp.InterpretAs != ssml.InterpretAsTypeUnset {
+			res.TextPart.InterpretAs = tp.InterpretAs.String()
+		}
+	}
+	return res
+}
+
+func mapAccentVariant(v *AccentVariant) *api.AccentVariant {
+	return &api.AccentVariant{Accent: v.Accent, Accented: v.Accented, Ml: v.Ml, Syll: v.Syll, Usage: v.Usage}
+}
@@ -0,0 +1,81 @@
+package synthesizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/pkg/ssml"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	initTest(t)
+	processorMock.f = func(d *TTSData) error {
+		d.Parts = []*TTSDataPart{{Words: []*ProcessedWord{
+			{Tagged: TaggedWord{Word: "olia", Mi: "Ncmsnn-", Lemma: "olia"}, Transcription: "o l i a",
+				AccentVariant:  &AccentVariant{Accent: 101, Syll: "o-lia", Usage: 2},
+				AccentVariants: []AccentVariant{{Accent: 101, Syll: "o-lia", Usage: 2}, {Accent: 203, Usage: 1}}},
+			{Tagged: TaggedWord{Space: true}},
+		}}, {Words: []*ProcessedWord{
+			{Tagged: TaggedWord{Word: "ai"}, Clitic: Clitic{Type: CliticsNone}, AccentVariant: &AccentVariant{Accent: 101},
+				NERType: NERSingleLetter, Obscene: true, TransliteratedFrom: "AI"},
+			{Tagged: TaggedWord{Separator: "."}},
+			{Tagged: TaggedWord{SentenceEnd: true}},
+		}}}
+		return nil
+	}
+	res, err := worker.Analyze(context.TODO(), &api.TTSRequestConfig{Text: "olia AI.", AllowCollectData: true})
+	require.Nil(t, err)
+	require.Equal(t, 5, len(res.Words))
+	assert.Equal(t, &api.AnalyzeWord{Type: "WORD", Word: "olia", Mi: "Ncmsnn-", Lemma: "olia", Accent: 101,
+		AccentVariant:  &api.AccentVariant{Accent: 101, Syll: "o-lia", Usage: 2},
+		AccentVariants: []*api.AccentVariant{{Accent: 101, Syll: "o-lia", Usage: 2}, {Accent: 203, Usage: 1}},
+		Transcription:  "o l i a"}, res.Words[0])
+	assert.Equal(t, &api.AnalyzeWord{Type: "SPACE"}, res.Words[1])
+	assert.Equal(t, &api.AnalyzeWord{Type: "WORD", Word: "ai", Part: 1, AccentVariant: &api.AccentVariant{Accent: 101},
+		Clitic: &api.Clitic{Type: "NONE"}, NER: "NERSingleLetter", Obscene: true, TransliteratedFrom: "AI"}, res.Words[2])
+	assert.Equal(t, &api.AnalyzeWord{Type: "SEPARATOR", Separator: ".", Part: 1}, res.Words[3])
+	assert.Equal(t, &api.AnalyzeWord{Type: "SENTENCE_END", Part: 1}, res.Words[4])
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	initTest(t)
+	res, err := worker.Analyze(context.TODO(), &api.TTSRequestConfig{Text: "olia"})
+	require.Nil(t, err)
+	assert.Equal(t, []*api.AnalyzeWord{}, res.Words)
+}
+
+func TestAnalyze_SSML(t *testing.T) {
+	initTest(t)
+	processorMock.f = func(d *TTSData) error {
+		for _, p := range d.SSMLParts {
+			for _, tp := range p.OriginalTextParts {
+				p.Words = append(p.Words, &ProcessedWord{Tagged: TaggedWord{Word: tp.Text}, TextPart: tp})
+			}
+		}
+		return nil
+	}
+	worker.processors = nil
+	worker.AddSSML(processorMock)
+	res, err := worker.Analyze(context.TODO(), &api.TTSRequestConfig{Text: "<speak>olia</speak>",
+		SSMLParts: []ssml.Part{&ssml.Text{Texts: []ssml.TextPart{{Text: "olia", Language: "en"}}, Voice: "v1"},
+			&ssml.Pause{Duration: 10 * time.Second},
+			&ssml.Text{Texts: []ssml.TextPart{{Text: "AI", InterpretAs: ssml.InterpretAsTypeCharacters}}}}})
+	require.Nil(t, err)
+	require.Equal(t, 2, len(res.Words))
+	assert.Equal(t, &api.AnalyzeText{SSMLPart: 0, Text: "olia", Language: "en"}, res.Words[0].TextPart)
+	assert.Equal(t, &api.AnalyzeText{SSMLPart: 2, Text: "AI", InterpretAs: "characters"}, res.Words[1].TextPart)
+}
+
+func TestAnalyze_Fail(t *testing.T) {
+	initTest(t)
+	processorMock.f = func(d *TTSData) error {
+		return errors.New("olia")
+	}
+	_, err := worker.Analyze(context.TODO(), &api.TTSRequestConfig{Text: "olia"})
+	assert.NotNil(t, err)
+}
@@ -23,6 +23,7 @@ type TTSData struct {
 	TextWithNumbers []string // text after number replacement to words
 
 	AudioSuffix string // add audio suffix if var is set
+	AudioClip   []byte // wav of the <audio> part, loaded by key Cfg.AudioSrc
 
 	Words []*ProcessedWord
 	Parts []*TTSDataPart
@@ -32,6 +33,10 @@ type TTSData struct {
 
 	OriginalTextParts []*TTSTextPart
 	SSMLParts         []*TTSData
+
+	PartListener PartListener // is set for streaming requests
+
+	SkippedStages []string // failed optional stages
 }
 
 type AudioData struct {
@@ -51,13 +56,23 @@ func (a *AudioData) Seconds() float64 {
 // TTSTextPart part of the text
 type TTSTextPart struct {
 	Accented, Text, Syllables, UserOEPal, Language string
+	Written                                        string // written text of <sub>, Text is the alias
+	Transcription                                  string // transcription of <phoneme>, skips the accenter and transcriber
+	SentenceEnd                                    bool   // the part is closed by </s>
 
 	InterpretAs       ssml.InterpretAsType
 	InterpretAsDetail ssml.InterpretAsDetailType
+	InterpretAsFormat string
 
 	Prosodies []*ssml.Prosody
 
 	PauseAfter time.Duration
+
+	Marks    []*ssml.Mark // <mark> elements before the text
+	EndMarks []*ssml.Mark // <mark> elements after the last text of SSML
+
+	Source string // raw input text of the part, the cleaned text is mapped to it
+	Start  int    // character offset of Source in the input
 }
 
 // TTSConfig some TTS configuration
@@ -70,6 +85,7 @@ type TTSConfig struct {
 	SpeedRate float64
 
 	PauseDuration time.Duration
+	AudioSrc      string // key of the <audio> clip
 }
 
 // TTSDataPart partial tts data
@@ -106,22 +122,24 @@ type AudioPos struct {
 
 // ProcessedWord keeps one word info
 type ProcessedWord struct {
-	Tagged            TaggedWord
-	UserTranscription string
-	UserSyllables     string
-	TranscriptionWord string
-	AccentVariant     *AccentVariant
-	UserAccent        int
-	Clitic            Clitic
-	Transcription     string
-	Obscene           bool
-	LastEmphasisWord  bool
-	TextPart          *TTSTextPart
-	SynthesizedPos    *SynthesizedPos
-	NERType           NEREnum
-	IsLastInPart      bool
-	AudioPos          *AudioPos
-	FromWord          *TaggedWord
+	Tagged             TaggedWord
+	UserTranscription  string
+	UserSyllables      string
+	TranscriptionWord  string
+	AccentVariant      *AccentVariant
+	AccentVariants     []AccentVariant // all variants returned by the accenter
+	UserAccent         int
+	Clitic             Clitic
+	Transcription      string
+	Obscene            bool
+	LastEmphasisWord   bool
+	TextPart           *TTSTextPart
+	SynthesizedPos     *SynthesizedPos
+	NERType            NEREnum
+	IsLastInPart       bool
+	AudioPos           *AudioPos
+	FromWord           *TaggedWord
+	TransliteratedFrom string // the original word replaced by the transliterator
 }
 
 func (p *ProcessedWord) Clone() *ProcessedWord {
@@ -184,7 +202,7 @@ type AccentVariant struct {
 	Usage    float64 `json:"usage"`
 }
 
-// SSMLTypeEnum indicates part type: text, pause
+// SSMLTypeEnum indicates part type: text, pause, audio
 type SSMLTypeEnum int
 
 const (
@@ -196,6 +214,8 @@ const (
 	SSMLText
 	// SSMLPause - <p>, <break> part for synthesis
 	SSMLPause
+	// SSMLAudio - <audio> part, a prerecorded clip
+	SSMLAudio
 )
 
 // IsWord returns true if object indicates word
@@ -0,0 +1,158 @@
+package synthesizer
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/airenas/tts-line/pkg/ssml"
+)
+
+type sentenceData struct {
+	index     int
+	fromField int
+	toField   int
+	from, to  time.Duration
+	span      textSpan
+}
+
+func addToSentence(sentences []*sentenceData, w *alignedWord) []*sentenceData {
+	if len(sentences) > 0 {
+		last := sentences[len(sentences)-1]
+		if last.index == w.sentence {
+			last.toField = w.field
+			last.to = w.to
+			last.span.end = w.span.field.end
+			return sentences
+		}
+	}
+	return append(sentences, &sentenceData{index: w.sentence, fromField: w.field, toField: w.field, from: w.from, to: w.to,
+		span: w.span.field})
+}
+
+func mapSentenceMarks(sentences []*sentenceData, fields []string) []*api.SpeechMark {
+	var res []*api.SpeechMark
+	for _, s := range sentences {
+		res = append(res, &api.SpeechMark{
+			Value:        joinFields(fields[s.fromField : s.toField+1]),
+			Type:         api.SpeechMarkTypeSentence,
+			TimeInMillis: s.from.Milliseconds(),
+			Duration:     (s.to - s.from).Milliseconds(),
+			Start:        s.span.start,
+			End:          s.span.end,
+		})
+	}
+	return res
+}
+
+// mapSSMLMarks makes marks for <mark> elements.
+// A mark points to the start of the next synthesized word or to the end of the last word
+func mapSSMLMarks(data *TTSData) []*api.SpeechMark {
+	if data.Audio == nil {
+		return nil
+	}
+	var textParts []*TTSTextPart
+	indexes := map[*TTSTextPart]int{}
+	for _, p := range data.SSMLParts {
+		for _, tp := range p.OriginalTextParts {
+			indexes[tp] = len(textParts)
+			textParts = append(textParts, tp)
+		}
+	}
+	var res []*api.SpeechMark
+	flushed := 0
+	flush := func(to int, at time.Duration) {
+		for ; flushed < to; flushed++ {
+			for _, m := range textParts[flushed].Marks {
+				res = append(res, mapSSMLMark(m, at))
+			}
+		}
+	}
+	var last time.Duration
+	for _, p := range data.SSMLParts {
+		if p.Cfg.Type != SSMLText {
+			continue
+		}
+		for _, dp := range p.Parts {
+			for _, w := range dp.Words {
+				if !w.Tagged.IsWord() || w.AudioPos == nil {
+					continue
+				}
+				if i, ok := indexes[w.TextPart]; ok {
+					flush(i+1, utils.BytesToDuration(w.AudioPos.From, data.Audio.SampleRate, data.Audio.BitsPerSample))
+				}
+				last = utils.BytesToDuration(w.AudioPos.To, data.Audio.SampleRate, data.Audio.BitsPerSample)
+			}
+		}
+	}
+	flush(len(textParts), last)
+	for _, tp := range textParts {
+		for _, m := range tp.EndMarks {
+			res = append(res, mapSSMLMark(m, last))
+		}
+	}
+	return res
+}
+
+func mapSSMLMark(m *ssml.Mark, at time.Duration) *api.SpeechMark {
+	return &api.SpeechMark{Type: api.SpeechMarkTypeSSML, Value: m.Name, TimeInMillis: at.Milliseconds(), Start: m.Start, End: m.End}
+}
+
+// mapPhonemeMarks makes marks for every transcription symbol of the words using the AM durations.
+// Pauses and the symbols with zero duration are skipped. The marks get the offsets of their word from spans
+func mapPhonemeMarks(data *TTSData, types map[string]bool, visemes *Visemes, spans map[*ProcessedWord]textSpan) []*api.SpeechMark {
+	if (!types[api.SpeechMarkTypePhoneme] && !types[api.SpeechMarkTypeViseme]) || data.Audio == nil {
+		return nil
+	}
+	var res []*api.SpeechMark
+	bytesPerSample := int(data.Audio.BitsPerSample / 8)
+	toDuration := func(bytes int) time.Duration {
+		return utils.BytesToDuration(bytes, data.Audio.SampleRate, data.Audio.BitsPerSample)
+	}
+	for _, p := range data.Parts {
+		symbols := p.TranscribedSymbols
+		if len(symbols) == 0 {
+			symbols = strings.Split(p.TranscribedText, " ")
+		}
+		for _, w := range p.Words {
+			if !w.Tagged.IsWord() || w.AudioPos == nil || w.SynthesizedPos == nil {
+				continue
+			}
+			span := spans[w]
+			from := w.AudioPos.From
+			for i, d := range w.SynthesizedPos.Durations {
+				to := from + d*p.Step*bytesPerSample
+				si := w.SynthesizedPos.StartIndex + i
+				if si < len(symbols) && d > 0 {
+					if viseme := visemes.Get(symbols[si]); viseme != VisemeSilence {
+						at, dur := toDuration(from), toDuration(to)-toDuration(from)
+						if types[api.SpeechMarkTypePhoneme] {
+							res = append(res, &api.SpeechMark{Type: api.SpeechMarkTypePhoneme, Value: symbols[si],
+								TimeInMillis: at.Milliseconds(), Duration: dur.Milliseconds(), Start: span.start, End: span.end})
+						}
+						if types[api.SpeechMarkTypeViseme] {
+							res = append(res, &api.SpeechMark{Type: api.SpeechMarkTypeViseme, Value: viseme,
+								TimeInMillis: at.Milliseconds(), Duration: dur.Milliseconds(), Start: span.start, End: span.end})
+						}
+					}
+				}
+				from = to
+			}
+		}
+	}
+	return res
+}
+
+var speechMarkOrder = map[string]int{api.SpeechMarkTypeSentence: 0, api.SpeechMarkTypeSSML: 1, api.SpeechMarkTypeWord: 2,
+	api.SpeechMarkTypePhoneme: 3, api.SpeechMarkTypeViseme: 4}
+
+func sortSpeechMarks(marks []*api.SpeechMark) {
+	sort.SliceStable(marks, func(i, j int) bool {
+		if marks[i].TimeInMillis != marks[j].TimeInMillis {
+			return marks[i].TimeInMillis < marks[j].TimeInMillis
+		}
+		return speechMarkOrder[marks[i].Type] < speechMarkOrder[marks[j].Type]
+	})
+}
@@ -0,0 +1,217 @@
+package synthesizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/pkg/ssml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMarksWord(w string, from, to int, tp *TTSTextPart) *ProcessedWord {
+	return &ProcessedWord{Tagged: TaggedWord{Word: w}, AudioPos: &AudioPos{From: from, To: to},
+		SynthesizedPos: &SynthesizedPos{}, TextPart: tp}
+}
+
+func newTestMarksData() *TTSData {
+	return &TTSData{
+		CleanedText: []string{"Labas, rytas. Kaip sekasi?"},
+		Audio:       &AudioData{SampleRate: 1000, BitsPerSample: 8},
+		Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+			newTestMarksWord("labas", 0, 100, nil),
+			{Tagged: TaggedWord{Separator: ","}},
+			newTestMarksWord("rytas", 100, 200, nil),
+			{Tagged: TaggedWord{Separator: "."}},
+			{Tagged: TaggedWord{SentenceEnd: true}},
+		}}, {Words: []*ProcessedWord{
+			newTestMarksWord("kaip", 300, 400, nil),
+			newTestMarksWord("sekasi", 400, 500, nil),
+			{Tagged: TaggedWord{Separator: "?"}},
+			{Tagged: TaggedWord{SentenceEnd: true}},
+		}}},
+	}
+}
+
+func TestMapSpeechMarks_Sentence(t *testing.T) {
+	data := newTestMarksData()
+	data.Input = &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypeSentence: true}}
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSentence, Value: "Labas, rytas.", TimeInMillis: 0, Duration: 200},
+		{Type: api.SpeechMarkTypeSentence, Value: "Kaip sekasi?", TimeInMillis: 300, Duration: 200},
+	}, res)
+}
+
+func TestMapSpeechMarks_SentenceAndWord(t *testing.T) {
+	data := newTestMarksData()
+	data.Input = &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypeSentence: true,
+		api.SpeechMarkTypeWord: true}}
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSentence, Value: "Labas, rytas.", TimeInMillis: 0, Duration: 200},
+		{Type: api.SpeechMarkTypeWord, Value: "Labas", TimeInMillis: 0, Duration: 100},
+		{Type: api.SpeechMarkTypeWord, Value: "rytas", TimeInMillis: 100, Duration: 100},
+		{Type: api.SpeechMarkTypeSentence, Value: "Kaip sekasi?", TimeInMillis: 300, Duration: 200},
+		{Type: api.SpeechMarkTypeWord, Value: "Kaip", TimeInMillis: 300, Duration: 100},
+		{Type: api.SpeechMarkTypeWord, Value: "sekasi", TimeInMillis: 400, Duration: 100},
+	}, res)
+}
+
+func TestMapSpeechMarks_SSML(t *testing.T) {
+	tp1 := &TTSTextPart{Text: "Labas", Marks: []*ssml.Mark{{Name: "m1", Start: 7, End: 24}}, Source: "Labas", Start: 24}
+	tp2 := &TTSTextPart{Text: "rytas.", Marks: []*ssml.Mark{{Name: "m2"}}, Source: "rytas.", Start: 47}
+	tp3 := &TTSTextPart{Text: "Kaip", Marks: []*ssml.Mark{{Name: "m3"}}, EndMarks: []*ssml.Mark{{Name: "m4"}},
+		Source: "Kaip", Start: 70}
+	audio := &AudioData{SampleRate: 1000, BitsPerSample: 8}
+	data := &TTSData{Audio: audio,
+		Input: &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypeSSML: true,
+			api.SpeechMarkTypeSentence: true}},
+		SSMLParts: []*TTSData{
+			{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Labas", "rytas."},
+				OriginalTextParts: []*TTSTextPart{tp1, tp2},
+				Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+					newTestMarksWord("labas", 0, 100, tp1),
+					newTestMarksWord("rytas", 100, 200, tp2),
+					{Tagged: TaggedWord{SentenceEnd: true}, TextPart: tp2},
+				}}}},
+			{Cfg: TTSConfig{Type: SSMLPause}},
+			{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Kaip"},
+				OriginalTextParts: []*TTSTextPart{tp3},
+				Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+					newTestMarksWord("kaip", 1200, 1300, tp3),
+				}}}},
+		}}
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSentence, Value: "Labas rytas.", TimeInMillis: 0, Duration: 200, Start: 24, End: 53},
+		{Type: api.SpeechMarkTypeSSML, Value: "m1", TimeInMillis: 0, Start: 7, End: 24},
+		{Type: api.SpeechMarkTypeSSML, Value: "m2", TimeInMillis: 100},
+		{Type: api.SpeechMarkTypeSentence, Value: "Kaip", TimeInMillis: 1200, Duration: 100, Start: 70, End: 74},
+		{Type: api.SpeechMarkTypeSSML, Value: "m3", TimeInMillis: 1200},
+		{Type: api.SpeechMarkTypeSSML, Value: "m4", TimeInMillis: 1300},
+	}, res)
+}
+
+func TestMapSSMLMarks_NoWords(t *testing.T) {
+	tp1 := &TTSTextPart{Text: "Labas", Marks: []*ssml.Mark{{Name: "m1"}}, EndMarks: []*ssml.Mark{{Name: "m2"}}}
+	data := &TTSData{Audio: &AudioData{SampleRate: 1000, BitsPerSample: 8},
+		SSMLParts: []*TTSData{{Cfg: TTSConfig{Type: SSMLText}, OriginalTextParts: []*TTSTextPart{tp1}}}}
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSSML, Value: "m1", TimeInMillis: 0},
+		{Type: api.SpeechMarkTypeSSML, Value: "m2", TimeInMillis: 0},
+	}, mapSSMLMarks(data))
+	data.Audio = nil
+	assert.Nil(t, mapSSMLMarks(data))
+}
+
+func TestMapSpeechMarks_Phonemes(t *testing.T) {
+	data := newTestMarksData()
+	data.Input = &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypePhoneme: true,
+		api.SpeechMarkTypeViseme: true, api.SpeechMarkTypeWord: true}}
+	data.Audio = &AudioData{SampleRate: 1000, BitsPerSample: 16}
+	p := data.Parts[0]
+	p.Step = 10
+	p.TranscribedSymbols = []string{"sil", "l", "\"a", "b", "a", "s", "sil"}
+	p.Words[0].AudioPos = &AudioPos{From: 100, To: 200}
+	p.Words[0].SynthesizedPos = &SynthesizedPos{StartIndex: 1, Durations: []int{1, 2, 1, 0, 1}}
+	data.Parts = data.Parts[:1]
+	data.CleanedText = []string{"labas"}
+
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeWord, Value: "labas", TimeInMillis: 50, Duration: 50},
+		{Type: api.SpeechMarkTypePhoneme, Value: "l", TimeInMillis: 50, Duration: 10},
+		{Type: api.SpeechMarkTypeViseme, Value: "t", TimeInMillis: 50, Duration: 10},
+		{Type: api.SpeechMarkTypePhoneme, Value: "\"a", TimeInMillis: 60, Duration: 20},
+		{Type: api.SpeechMarkTypeViseme, Value: "a", TimeInMillis: 60, Duration: 20},
+		{Type: api.SpeechMarkTypePhoneme, Value: "b", TimeInMillis: 80, Duration: 10},
+		{Type: api.SpeechMarkTypeViseme, Value: "p", TimeInMillis: 80, Duration: 10},
+		{Type: api.SpeechMarkTypePhoneme, Value: "s", TimeInMillis: 90, Duration: 10},
+		{Type: api.SpeechMarkTypeViseme, Value: "s", TimeInMillis: 90, Duration: 10},
+	}, res)
+}
+
+func TestMapPhonemeMarks_Skip(t *testing.T) {
+	v, _ := NewVisemes(nil)
+	data := &TTSData{Audio: &AudioData{SampleRate: 1000, BitsPerSample: 8},
+		Parts: []*TTSDataPart{{Step: 10, TranscribedText: "sil a sp", Words: []*ProcessedWord{
+			{Tagged: TaggedWord{Word: "a"}, AudioPos: &AudioPos{From: 0, To: 30},
+				SynthesizedPos: &SynthesizedPos{StartIndex: 1, Durations: []int{2, 1}}},
+			{Tagged: TaggedWord{Separator: ","}, AudioPos: &AudioPos{From: 30, To: 40},
+				SynthesizedPos: &SynthesizedPos{StartIndex: 2, Durations: []int{1}}},
+		}}}}
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeViseme, Value: "a", TimeInMillis: 0, Duration: 20},
+	}, mapPhonemeMarks(data, map[string]bool{api.SpeechMarkTypeViseme: true}, v, nil))
+	assert.Nil(t, mapPhonemeMarks(data, map[string]bool{api.SpeechMarkTypeWord: true}, v, nil))
+	data.Audio = nil
+	assert.Nil(t, mapPhonemeMarks(data, map[string]bool{api.SpeechMarkTypeViseme: true}, v, nil))
+}
+
+func TestMapSpeechMarks_Offsets(t *testing.T) {
+	data := &TTSData{
+		CleanedText:       []string{"Turiu 25 eurų."},
+		OriginalTextParts: []*TTSTextPart{{Text: "Turiu 25 eurų.", Source: "Turiu  25 eurų."}},
+		Audio:             &AudioData{SampleRate: 1000, BitsPerSample: 8},
+		Parts: []*TTSDataPart{{Step: 10, TranscribedText: "t u r' u d' v' i d' E S' i m t p' E n' k' i e u r u:", Words: []*ProcessedWord{
+			newTestMarksWord("turiu", 0, 100, nil),
+			newTestMarksWord("dvidešimt", 100, 200, nil),
+			{Tagged: TaggedWord{Word: "penki"}, AudioPos: &AudioPos{From: 200, To: 250},
+				SynthesizedPos: &SynthesizedPos{StartIndex: 13, Durations: []int{2, 3}}},
+			newTestMarksWord("eurų", 300, 400, nil),
+			{Tagged: TaggedWord{Separator: "."}},
+			{Tagged: TaggedWord{SentenceEnd: true}},
+		}}},
+		Input: &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypeWord: true,
+			api.SpeechMarkTypeSentence: true, api.SpeechMarkTypePhoneme: true}},
+	}
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSentence, Value: "Turiu 25 eurų.", TimeInMillis: 0, Duration: 400, Start: 0, End: 15},
+		{Type: api.SpeechMarkTypeWord, Value: "Turiu", TimeInMillis: 0, Duration: 100, Start: 0, End: 5},
+		{Type: api.SpeechMarkTypeWord, Value: "25", TimeInMillis: 100, Duration: 150, Start: 7, End: 9},
+		{Type: api.SpeechMarkTypePhoneme, Value: "p'", TimeInMillis: 200, Duration: 20, Start: 7, End: 9},
+		{Type: api.SpeechMarkTypePhoneme, Value: "E", TimeInMillis: 220, Duration: 30, Start: 7, End: 9},
+		{Type: api.SpeechMarkTypeWord, Value: "eurų", TimeInMillis: 300, Duration: 100, Start: 10, End: 14},
+	}, res)
+}
+
+func newTestSubData() *TTSData {
+	tp1 := &TTSTextPart{Text: "Valstybė", Source: "Valstybė", Start: 7}
+	tp2 := &TTSTextPart{Text: "Jungtinės Amerikos Valstijos", Written: "JAV", Source: "JAV", Start: 56}
+	tp3 := &TTSTextPart{Text: "yra.", Source: "yra.", Start: 66}
+	audio := &AudioData{SampleRate: 1000, BitsPerSample: 8}
+	return &TTSData{Audio: audio, SSMLParts: []*TTSData{
+		{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Valstybė", "Jungtinės Amerikos Valstijos", "yra."},
+			OriginalTextParts: []*TTSTextPart{tp1, tp2, tp3},
+			Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+				newTestMarksWord("valstybė", 0, 100, tp1),
+				newTestMarksWord("jungtinės", 100, 200, tp2),
+				newTestMarksWord("amerikos", 200, 300, tp2),
+				newTestMarksWord("valstijos", 300, 400, tp2),
+				newTestMarksWord("yra", 400, 500, tp3),
+				{Tagged: TaggedWord{Separator: "."}, TextPart: tp3},
+				{Tagged: TaggedWord{SentenceEnd: true}, TextPart: tp3},
+			}}}}}}
+}
+
+func TestMapSpeechMarks_Sub(t *testing.T) {
+	data := newTestSubData()
+	data.Input = &api.TTSRequestConfig{SpeechMarkTypes: map[string]bool{api.SpeechMarkTypeWord: true,
+		api.SpeechMarkTypeSentence: true}}
+	res, err := mapSpeechMarks(context.TODO(), data, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []*api.SpeechMark{
+		{Type: api.SpeechMarkTypeSentence, Value: "Valstybė JAV yra.", TimeInMillis: 0, Duration: 500, Start: 7, End: 70},
+		{Type: api.SpeechMarkTypeWord, Value: "Valstybė", TimeInMillis: 0, Duration: 100, Start: 7, End: 15},
+		{Type: api.SpeechMarkTypeWord, Value: "JAV", TimeInMillis: 100, Duration: 300, Start: 56, End: 59},
+		{Type: api.SpeechMarkTypeWord, Value: "yra", TimeInMillis: 400, Duration: 100, Start: 66, End: 69},
+	}, res)
+}
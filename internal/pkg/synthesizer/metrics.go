@@ -0,0 +1,14 @@
+package synthesizer
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var degradedMetrics = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "tts_degraded_responses_total",
+		Help: "The total number of responses with skipped optional stages",
+	},
+)
+
+func init() {
+	prometheus.MustRegister(degradedMetrics)
+}
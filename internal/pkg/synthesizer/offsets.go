@@ -0,0 +1,128 @@
+package synthesizer
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/airenas/tts-line/internal/pkg/utils/dtw"
+)
+
+// textSpan is a character span in the input text
+type textSpan struct {
+	start, end int
+}
+
+// fieldSpan keeps the spans of the field and of its word without the punctuation
+type fieldSpan struct {
+	field, word textSpan
+}
+
+// mapFieldOffsets splits the cleaned text into fields and finds their spans in the source text.
+// The characters are aligned, so the spans survive the replacements and removals done by the cleaner.
+// A span is extended over the following not aligned source characters, e.g. the XML entities,
+// a field span is extended over the preceding ones too, e.g. the quotes dropped by the cleaner.
+// A span without any aligned character is empty and points to the end of the previous field.
+// The offsets are not required for the synthesis, so all spans are zero if the alignment fails
+func mapFieldOffsets(ctx context.Context, text, source string, start int) ([]string, []fieldSpan) {
+	fields := strings.Fields(text)
+	if source == "" {
+		return fields, make([]fieldSpan, len(fields))
+	}
+	runes := []rune(text)
+	so := &sourceOffsets{runes: []rune(source), start: start, last: start}
+	var err error
+	so.aligned, err = dtw.Align(ctx, toLowerStrings(runes), toLowerStrings(so.runes))
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("can't align offsets")
+		return fields, make([]fieldSpan, len(fields))
+	}
+	so.used = make([]bool, len(so.runes))
+	for _, a := range so.aligned {
+		if a >= 0 {
+			so.used[a] = true
+		}
+	}
+	res := make([]fieldSpan, 0, len(fields))
+	for from := 0; from < len(runes); {
+		if unicode.IsSpace(runes[from]) {
+			from++
+			continue
+		}
+		to := from
+		for to < len(runes) && !unicode.IsSpace(runes[to]) {
+			to++
+		}
+		wFrom, wTo := from, to
+		for wFrom < wTo && !isWordRune(runes[wFrom]) {
+			wFrom++
+		}
+		for wTo > wFrom && !isWordRune(runes[wTo-1]) {
+			wTo--
+		}
+		var fs fieldSpan
+		fs.word = so.span(wFrom, wTo)
+		fs.field = so.extendStart(so.span(from, to))
+		so.last = fs.field.end
+		res = append(res, fs)
+		from = to
+	}
+	return fields, res
+}
+
+type sourceOffsets struct {
+	runes   []rune
+	aligned []int  // source index of the text runes
+	used    []bool // source runes aligned to the text
+	start   int    // offset of the source in the input
+	last    int    // end of the previous field
+}
+
+// span returns the span of the text runes [from, to) in the input
+func (so *sourceOffsets) span(from, to int) textSpan {
+	res := textSpan{start: -1}
+	for _, a := range so.aligned[from:to] {
+		if a >= 0 {
+			if res.start < 0 {
+				res.start = a
+			}
+			res.end = a + 1
+		}
+	}
+	if res.start < 0 {
+		return textSpan{start: so.last, end: so.last}
+	}
+	for res.end < len(so.runes) && !so.used[res.end] && !unicode.IsSpace(so.runes[res.end]) {
+		res.end++
+	}
+	return textSpan{start: so.start + res.start, end: so.start + res.end}
+}
+
+// extendStart extends the span back over the not aligned source characters after the previous field
+func (so *sourceOffsets) extendStart(s textSpan) textSpan {
+	if s.start == s.end {
+		return s
+	}
+	for s.start > so.last && s.start > so.start {
+		i := s.start - so.start - 1
+		if so.used[i] || unicode.IsSpace(so.runes[i]) {
+			break
+		}
+		s.start--
+	}
+	return s
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func toLowerStrings(runes []rune) []string {
+	res := make([]string, len(runes))
+	for i, r := range runes {
+		res[i] = string(unicode.ToLower(r))
+	}
+	return res
+}
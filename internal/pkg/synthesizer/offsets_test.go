@@ -0,0 +1,58 @@
+package synthesizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapFieldOffsets(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		source string
+		start  int
+		fields []string
+		spans  []fieldSpan
+	}{
+		{name: "same", text: "Labas rytas", source: "Labas rytas", fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{textSpan{0, 5}, textSpan{0, 5}}, {textSpan{6, 11}, textSpan{6, 11}}}},
+		{name: "start", text: "Labas rytas", source: "Labas rytas", start: 10, fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{textSpan{10, 15}, textSpan{10, 15}}, {textSpan{16, 21}, textSpan{16, 21}}}},
+		{name: "spaces", text: "Labas rytas", source: "  Labas\n\n rytas ", fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{textSpan{2, 7}, textSpan{2, 7}}, {textSpan{10, 15}, textSpan{10, 15}}}},
+		{name: "non ascii", text: "Ąžuolas žalias", source: "Ąžuolas žalias", fields: []string{"Ąžuolas", "žalias"},
+			spans: []fieldSpan{{textSpan{0, 7}, textSpan{0, 7}}, {textSpan{8, 14}, textSpan{8, 14}}}},
+		{name: "punctuation", text: "(Labas), rytas.", source: "(Labas), rytas.", fields: []string{"(Labas),", "rytas."},
+			spans: []fieldSpan{{textSpan{0, 8}, textSpan{1, 6}}, {textSpan{9, 15}, textSpan{9, 14}}}},
+		{name: "changed quotes", text: "\"Labas\" rytas", source: "„Labas“ rytas", fields: []string{"\"Labas\"", "rytas"},
+			spans: []fieldSpan{{textSpan{0, 7}, textSpan{1, 6}}, {textSpan{8, 13}, textSpan{8, 13}}}},
+		{name: "removed quotes", text: "Labas, rytas.", source: "Labas, „rytas“.", fields: []string{"Labas,", "rytas."},
+			spans: []fieldSpan{{textSpan{0, 6}, textSpan{0, 5}}, {textSpan{7, 15}, textSpan{8, 14}}}},
+		{name: "removed", text: "Labas rytas", source: "Labas ☺ rytas", fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{textSpan{0, 5}, textSpan{0, 5}}, {textSpan{8, 13}, textSpan{8, 13}}}},
+		{name: "removed in word", text: "Labas rytas", source: "Lab☺as ryt☺☺", fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{textSpan{0, 6}, textSpan{0, 6}}, {textSpan{7, 12}, textSpan{7, 12}}}},
+		{name: "split", text: "Labas, rytas", source: "Labas,rytas", fields: []string{"Labas,", "rytas"},
+			spans: []fieldSpan{{textSpan{0, 6}, textSpan{0, 5}}, {textSpan{6, 11}, textSpan{6, 11}}}},
+		{name: "entity", text: "a & \"b\"", source: "a &amp; &quot;b&quot;", fields: []string{"a", "&", "\"b\""},
+			spans: []fieldSpan{{textSpan{0, 1}, textSpan{0, 1}}, {textSpan{2, 7}, textSpan{1, 1}}, {textSpan{8, 21}, textSpan{14, 15}}}},
+		{name: "no source", text: "Labas rytas", fields: []string{"Labas", "rytas"},
+			spans: []fieldSpan{{}, {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, spans := mapFieldOffsets(context.TODO(), tt.text, tt.source, tt.start)
+			assert.Equal(t, tt.fields, fields)
+			assert.Equal(t, tt.spans, spans)
+		})
+	}
+}
+
+func TestMapFieldOffsets_AlignFail(t *testing.T) {
+	fields, spans := mapFieldOffsets(context.TODO(), "Labas rytas", "Labas"+strings.Repeat("☺", 3000)+" rytas", 0)
+	assert.Equal(t, []string{"Labas", "rytas"}, fields)
+	assert.Equal(t, []fieldSpan{{}, {}}, spans)
+}
@@ -2,6 +2,8 @@ package synthesizer
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/airenas/tts-line/internal/pkg/utils"
@@ -13,6 +15,11 @@ type PartProcessor interface {
 	Process(context.Context, *TTSDataPart) error
 }
 
+// PartListener is notified when a part is synthesized
+type PartListener interface {
+	PartDone(context.Context, *TTSDataPart) error
+}
+
 // PartRunner runs parts of the job
 type PartRunner struct {
 	processors     []PartProcessor
@@ -41,6 +48,9 @@ func (p *PartRunner) Process(ctx context.Context, data *TTSData) error {
 
 	var wg sync.WaitGroup
 
+	progress := utils.ProgressFromContext(ctx)
+	progress.AddTotal(len(data.Parts))
+
 	for _, part := range data.Parts {
 		select {
 		case err := <-errCh:
@@ -51,12 +61,17 @@ func (p *PartRunner) Process(ctx context.Context, data *TTSData) error {
 				defer wg.Done()
 				defer func() { <-workerQueueLimit }()
 				err := p.process(ctx, part, closeCh)
+				if err == nil && data.PartListener != nil {
+					err = data.PartListener.PartDone(ctx, part)
+				}
 				if err != nil {
 					select {
 					case <-closeCh:
 					case errCh <- err:
 					}
+					return
 				}
+				progress.AddDone(1)
 			}(part)
 		}
 	}
@@ -80,6 +95,19 @@ func (p *PartRunner) Add(pr PartProcessor) {
 	p.processors = append(p.processors, pr)
 }
 
+// Info return info about processor
+func (p *PartRunner) Info() string {
+	prInfo := strings.Builder{}
+	for _, pr := range p.processors {
+		s := utils.RetrieveInfo(pr)
+		if s != "" {
+			prInfo.WriteString(s)
+			prInfo.WriteString("\n")
+		}
+	}
+	return fmt.Sprintf("partRunner(%d)[\n%s\n]", p.parallelWorker, strings.TrimSpace(prInfo.String()))
+}
+
 func (p *PartRunner) process(ctx context.Context, data *TTSDataPart, clCh <-chan bool) error {
 	for _, pr := range p.processors {
 		select {
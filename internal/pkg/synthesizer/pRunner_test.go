@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,6 +30,16 @@ func TestPRunner_Default(t *testing.T) {
 	runner = NewPartRunner(10)
 	assert.Equal(t, 10, runner.parallelWorker)
 }
+
+func TestPRunner_Info(t *testing.T) {
+	runner = NewPartRunner(2)
+	assert.Equal(t, "partRunner(2)[\n\n]", runner.Info())
+	runner.Add(&infoPartProcMock{info: "p1"})
+	runner.Add(&partProcMock{})
+	runner.Add(&infoPartProcMock{info: "p2"})
+	assert.Equal(t, "partRunner(2)[\np1\np2\n]", runner.Info())
+}
+
 func TestPRProcess(t *testing.T) {
 	initPRunnerTest(t)
 	partProcTest.f = func(d *TTSDataPart) error {
@@ -56,6 +67,33 @@ func TestPRProcess_Several(t *testing.T) {
 	assert.Equal(t, "mp3", string(d.Parts[1].Audio))
 }
 
+func TestPRProcess_Progress(t *testing.T) {
+	initPRunnerTest(t)
+	d.Parts = append(d.Parts, &TTSDataPart{}, &TTSDataPart{})
+	progress := &utils.Progress{}
+	err := runner.Process(utils.WithProgress(context.TODO(), progress), d)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, progress.Value())
+}
+
+func TestPRProcess_PartListener(t *testing.T) {
+	initPRunnerTest(t)
+	d.Parts = append(d.Parts, &TTSDataPart{}, &TTSDataPart{})
+	pl := &partListenerMock{}
+	d.PartListener = pl
+	err := runner.Process(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), pl.calls.Load())
+}
+
+func TestPRProcess_PartListenerFail(t *testing.T) {
+	initPRunnerTest(t)
+	d.Parts = append(d.Parts, &TTSDataPart{})
+	d.PartListener = &partListenerMock{err: errors.New("olia")}
+	err := runner.Process(context.TODO(), d)
+	assert.NotNil(t, err)
+}
+
 func TestPRProcess_Fail(t *testing.T) {
 	initPRunnerTest(t)
 	partProcTest.f = func(d *TTSDataPart) error {
@@ -134,3 +172,22 @@ type partProcMock struct {
 func (pr *partProcMock) Process(ctx context.Context, d *TTSDataPart) error {
 	return pr.f(d)
 }
+
+type infoPartProcMock struct {
+	partProcMock
+	info string
+}
+
+func (pr *infoPartProcMock) Info() string {
+	return pr.info
+}
+
+type partListenerMock struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (m *partListenerMock) PartDone(ctx context.Context, part *TTSDataPart) error {
+	m.calls.Add(1)
+	return m.err
+}
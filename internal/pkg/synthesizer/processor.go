@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/airenas/go-app/pkg/goapp"
 	"github.com/google/uuid"
@@ -29,10 +30,42 @@ type MainWorker struct {
 	processors      []Processor
 	ssmlProcessors  []Processor
 	AllowCustomCode bool
+	// Visemes maps phonemes to visemes for the speech marks, the default table is used if nil
+	Visemes *Visemes
+	// Subtitles makes vtt and srt output, the default settings are used if nil
+	Subtitles *Subtitles
 }
 
 // Work is main method
 func (mw *MainWorker) Work(ctx context.Context, input *api.TTSRequestConfig) (*api.Result, error) {
+	data, err := mw.run(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	res, err := mw.mapResult(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	res.SkippedStages = data.SkippedStages
+	return res, nil
+}
+
+func (mw *MainWorker) run(ctx context.Context, input *api.TTSRequestConfig) (*TTSData, error) {
+	skipped := &utils.SkippedStages{}
+	ctx = utils.WithSkippedStages(ctx, skipped)
+	data, err := mw.runAll(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	data.SkippedStages = skipped.Names()
+	if len(data.SkippedStages) > 0 {
+		log.Ctx(ctx).Warn().Strs("stages", data.SkippedStages).Msg("degraded result")
+		degradedMetrics.Inc()
+	}
+	return data, nil
+}
+
+func (mw *MainWorker) runAll(ctx context.Context, input *api.TTSRequestConfig) (*TTSData, error) {
 	data := &TTSData{}
 	data.OriginalText = input.Text
 	data.Input = input
@@ -64,20 +97,27 @@ func (mw *MainWorker) Work(ctx context.Context, input *api.TTSRequestConfig) (*a
 			return nil, err
 		}
 	}
-	return mapResult(ctx, data)
+	return data, nil
 }
 
 func makeSSMLParts(input *api.TTSRequestConfig) ([]*TTSData, error) {
 	var res []*TTSData
 	var last *TTSData
+	var lastText *TTSTextPart
+	var marks []*ssml.Mark
 	for _, p := range input.SSMLParts {
 		switch pc := p.(type) {
 		case *ssml.Text:
+			textParts := makeTextParts(pc.Texts, pc.Prosodies)
+			if len(textParts) > 0 {
+				textParts[0].Marks, marks = marks, nil
+				lastText = textParts[len(textParts)-1]
+			}
 			if last != nil && last.Cfg.Type == SSMLText && last.Cfg.Voice == pc.Voice {
-				last.OriginalTextParts = append(last.OriginalTextParts, makeTextParts(pc.Texts, pc.Prosodies)...)
+				last.OriginalTextParts = append(last.OriginalTextParts, textParts...)
 			} else {
 				data := &TTSData{}
-				data.OriginalTextParts = makeTextParts(pc.Texts, pc.Prosodies)
+				data.OriginalTextParts = textParts
 				data.Input = input
 				data.Cfg.Input = input
 				// data.Cfg.Prosodies = pc.Prosodies
@@ -102,10 +142,21 @@ func makeSSMLParts(input *api.TTSRequestConfig) ([]*TTSData, error) {
 			data.Cfg.Type = SSMLPause
 			res = append(res, data)
 			last = data
+		case *ssml.Audio:
+			data := &TTSData{}
+			data.Cfg.AudioSrc = pc.Src
+			data.Cfg.Type = SSMLAudio
+			res = append(res, data)
+			last = data
+		case *ssml.Mark:
+			marks = append(marks, pc)
 		default:
 			return nil, errors.Errorf("unknown SSML part type %T", pc)
 		}
 	}
+	if lastText != nil {
+		lastText.EndMarks = marks
+	}
 	return res, nil
 }
 
@@ -116,7 +167,13 @@ func makeTextParts(textPart []ssml.TextPart, prosodies []*ssml.Prosody) []*TTSTe
 			Language:          tp.Language,
 			InterpretAs:       tp.InterpretAs,
 			InterpretAsDetail: tp.InterpretAsDetail,
+			InterpretAsFormat: tp.InterpretAsFormat,
 			Prosodies:         prosodies,
+			Written:           tp.Written,
+			Transcription:     tp.Transcription,
+			SentenceEnd:       tp.SentenceEnd,
+			Source:            tp.Source,
+			Start:             tp.Start,
 		})
 	}
 	return res
@@ -150,7 +207,7 @@ func getInputText(data *TTSData) string {
 	return data.Input.Text
 }
 
-func mapResult(ctx context.Context, data *TTSData) (*api.Result, error) {
+func (mw *MainWorker) mapResult(ctx context.Context, data *TTSData) (*api.Result, error) {
 	res := &api.Result{}
 	res.Audio = data.AudioMP3
 	if data.Input.OutputTextFormat != api.TextNone {
@@ -168,14 +225,30 @@ func mapResult(ctx context.Context, data *TTSData) (*api.Result, error) {
 			if err != nil {
 				return nil, err
 			}
+		case api.TextSSML:
+			var err error
+			res.Text, err = mapSSMLText(data)
+			if err != nil {
+				return nil, err
+			}
+		case api.TextVTT, api.TextSRT:
+			subtitles := mw.Subtitles
+			if subtitles == nil {
+				subtitles, _ = NewSubtitles(nil)
+			}
+			var err error
+			res.Text, err = subtitles.Map(ctx, data)
+			if err != nil {
+				return nil, err
+			}
 		case api.TextNone:
 		default:
 			return nil, errors.Errorf("can't process OutputTextFormat %s", data.Input.OutputTextFormat.String())
 		}
 	}
-	if data.Input.SpeechMarkTypes[api.SpeechMarkTypeWord] {
+	if len(data.Input.SpeechMarkTypes) > 0 {
 		var err error
-		res.SpeechMarks, err = mapSpeechMarks(ctx, data)
+		res.SpeechMarks, err = mapSpeechMarks(ctx, data, mw.Visemes)
 		if err != nil {
 			return nil, err
 		}
@@ -184,39 +257,129 @@ func mapResult(ctx context.Context, data *TTSData) (*api.Result, error) {
 }
 
 type wordMapData struct {
-	pw *ProcessedWord
+	pw       *ProcessedWord
+	sentence int // sentence index in the part
 }
 
-func mapSpeechMarks(ctx context.Context, data *TTSData) ([]*api.SpeechMark, error) {
-	if len(data.SSMLParts) == 0 {
-		res, err := mapSpeechMarksInt(ctx, data)
-		return res, err
+func mapSpeechMarks(ctx context.Context, data *TTSData, visemes *Visemes) ([]*api.SpeechMark, error) {
+	types := data.Input.SpeechMarkTypes
+	if visemes == nil {
+		var err error
+		if visemes, err = NewVisemes(nil); err != nil {
+			return nil, err
+		}
+	}
+	parts := data.SSMLParts
+	if len(parts) == 0 {
+		parts = []*TTSData{data}
 	}
 
 	var res []*api.SpeechMark
-	for _, p := range data.SSMLParts {
-		pRes, err := mapSpeechMarksInt(ctx, p)
-		if err != nil {
-			return nil, err
+	for _, p := range parts {
+		var words []*alignedWord
+		var fields []string
+		if types[api.SpeechMarkTypeWord] || types[api.SpeechMarkTypeSentence] ||
+			types[api.SpeechMarkTypePhoneme] || types[api.SpeechMarkTypeViseme] {
+			var err error
+			words, fields, _, err = alignWords(ctx, p)
+			if err != nil {
+				return nil, err
+			}
 		}
-		res = append(res, pRes...)
+		res = append(res, mapSpeechMarksInt(words, fields, types)...)
+		res = append(res, mapPhonemeMarks(p, types, visemes, wordSpans(words))...)
+	}
+	if types[api.SpeechMarkTypeSSML] && len(data.SSMLParts) > 0 {
+		res = append(res, mapSSMLMarks(data)...)
 	}
+	sortSpeechMarks(res)
 	return res, nil
 }
 
-func mapSpeechMarksInt(ctx context.Context, data *TTSData) ([]*api.SpeechMark, error) {
-	text := strings.Join(data.CleanedText, " ")
-	if len(text) == 0 {
-		return nil, nil
+func mapSpeechMarksInt(words []*alignedWord, fields []string, types map[string]bool) []*api.SpeechMark {
+	var res []*api.SpeechMark
+	var sentences []*sentenceData
+	for _, w := range words {
+		if types[api.SpeechMarkTypeWord] {
+			res = append(res, &api.SpeechMark{
+				Value:        w.word,
+				Type:         api.SpeechMarkTypeWord,
+				TimeInMillis: w.from.Milliseconds(),
+				Duration:     (w.to - w.from).Milliseconds(),
+				Start:        w.span.word.start,
+				End:          w.span.word.end,
+			})
+		}
+		sentences = addToSentence(sentences, w)
+	}
+	if types[api.SpeechMarkTypeSentence] {
+		res = append(res, mapSentenceMarks(sentences, fields)...)
 	}
-	originalWords := strings.Fields(accent.ClearAccents(text))
-	originalWords = dropPunctuation(originalWords)
+	return res
+}
+
+type alignedWord struct {
+	word        string
+	field       int // index in the cleaned text fields
+	sentence    int // sentence index in the part
+	from, to    time.Duration
+	span        fieldSpan        // offsets in the input
+	synthesized []*ProcessedWord // synthesized words of the word, several for numbers, URLs, etc.
+}
+
+// alignWords aligns the words of the cleaned text with the synthesized words.
+// The words of a <sub> alias are joined into one written word.
+// Returns the aligned words and the fields of the cleaned text, a <sub> part has the written text in its first field.
+// The last result is the raw input text of every field, it is empty if the field is not mapped to the input
+func alignWords(ctx context.Context, data *TTSData) ([]*alignedWord, []string, []string, error) {
+	if len(strings.Join(data.CleanedText, " ")) == 0 {
+		return nil, nil, nil, nil
+	}
+	var fields, display, sources []string
+	var spans []fieldSpan
+	var fieldParts []*TTSTextPart
+	for i, ct := range data.CleanedText {
+		var tp *TTSTextPart
+		if i < len(data.OriginalTextParts) {
+			tp = data.OriginalTextParts[i]
+		}
+		if tp != nil && tp.Written != "" {
+			pFields := strings.Fields(accent.ClearAccents(ct))
+			span := textSpan{start: tp.Start, end: tp.Start + utf8.RuneCountInString(tp.Source)}
+			for j := range pFields {
+				display = append(display, "")
+				sources = append(sources, "")
+				if j == 0 {
+					display[len(display)-1] = tp.Written
+					sources[len(sources)-1] = tp.Source
+				}
+				spans = append(spans, fieldSpan{field: span, word: span})
+				fieldParts = append(fieldParts, tp)
+			}
+			fields = append(fields, pFields...)
+			continue
+		}
+		var source string
+		var start int
+		if tp != nil {
+			source, start = tp.Source, tp.Start
+		}
+		pFields, pSpans := mapFieldOffsets(ctx, accent.ClearAccents(ct), source, start)
+		fields = append(fields, pFields...)
+		display = append(display, pFields...)
+		spans = append(spans, pSpans...)
+		for _, sp := range pSpans {
+			fieldParts = append(fieldParts, tp)
+			sources = append(sources, sourceText(tp, sp.field))
+		}
+	}
+	originalWords, fieldIndexes := dropPunctuation(fields)
 	words, maps := collectWords(data.Parts)
 	aligned, err := dtw.Align(ctx, originalWords, words)
 	if err != nil {
-		return nil, fmt.Errorf("can't align words: %w", err)
+		return nil, nil, nil, fmt.Errorf("can't align words: %w", err)
 	}
-	var res []*api.SpeechMark
+	var res []*alignedWord
 	for i, w := range originalWords {
 		if aligned[i] == -1 || aligned[i] >= len(maps) {
 			continue
@@ -229,24 +392,90 @@ func mapSpeechMarksInt(ctx context.Context, data *TTSData) ([]*api.SpeechMark, e
 				Msg("Invalid word timing, skipping")
 			continue
 		}
-		sm := &api.SpeechMark{
-			Value: w,
-			Type:  api.SpeechMarkTypeWord,
-			// TimeInMillis: (from + md.start + utils.ToDuration(md.pw.SynthesizedPos.From+md.shift, data.Audio.SampleRate, md.part.Step)).Milliseconds(),
-			TimeInMillis: at.Milliseconds(),
-			Duration:     (to - at).Milliseconds(),
-		}
 		goapp.Log.Debug().Msgf("Word: %s, from: %d, to: %d, res: %d-%d (%d)",
-			w, md.pw.SynthesizedPos.From, to.Milliseconds(), sm.TimeInMillis, sm.TimeInMillis+sm.Duration, sm.Duration)
+			w, md.pw.SynthesizedPos.From, to.Milliseconds(), at.Milliseconds(), to.Milliseconds(), (to - at).Milliseconds())
+		var synthesized []*ProcessedWord
+		for _, sw := range maps[aligned[i]:max(nextAligned(aligned, i, len(maps)), aligned[i]+1)] {
+			synthesized = append(synthesized, sw.pw)
+		}
+		field := fieldIndexes[i]
+		if tp := fieldParts[field]; tp != nil && tp.Written != "" {
+			if last := lastWord(res); last != nil && fieldParts[last.field] == tp {
+				last.to = to
+				last.synthesized = append(last.synthesized, synthesized...)
+				continue
+			}
+			for field > 0 && fieldParts[field-1] == tp {
+				field--
+			}
+			w = tp.Written
+		}
+		res = append(res, &alignedWord{word: w, field: field, sentence: md.sentence, from: at, to: to,
+			span: spans[field], synthesized: synthesized})
+	}
+	return res, display, sources, nil
+}
 
-		res = append(res, sm)
+// sourceText returns the raw input text of the span, the span is an offset in the input
+func sourceText(tp *TTSTextPart, span textSpan) string {
+	if tp == nil || span.end <= span.start {
+		return ""
 	}
-	return res, nil
+	rns := []rune(tp.Source)
+	from, to := span.start-tp.Start, span.end-tp.Start
+	if from < 0 || to > len(rns) {
+		return ""
+	}
+	return string(rns[from:to])
 }
 
-func dropPunctuation(originalWords []string) []string {
+func lastWord(words []*alignedWord) *alignedWord {
+	if len(words) == 0 {
+		return nil
+	}
+	return words[len(words)-1]
+}
+
+// joinFields joins the not empty fields
+func joinFields(fields []string) string {
+	res := strings.Builder{}
+	for _, f := range fields {
+		if f == "" {
+			continue
+		}
+		if res.Len() > 0 {
+			res.WriteString(" ")
+		}
+		res.WriteString(f)
+	}
+	return res.String()
+}
+
+// nextAligned returns the index of the next aligned synthesized word or n if there is no one
+func nextAligned(aligned []int, i, n int) int {
+	for _, a := range aligned[i+1:] {
+		if a >= 0 {
+			return min(a, n)
+		}
+	}
+	return n
+}
+
+func wordSpans(words []*alignedWord) map[*ProcessedWord]textSpan {
+	res := map[*ProcessedWord]textSpan{}
+	for _, w := range words {
+		for _, pw := range w.synthesized {
+			res[pw] = w.span.word
+		}
+	}
+	return res
+}
+
+// dropPunctuation returns words without punctuation and their indexes in originalWords
+func dropPunctuation(originalWords []string) ([]string, []int) {
 	var res []string
-	for _, w := range originalWords {
+	var indexes []int
+	for i, w := range originalWords {
 		nw := strings.TrimFunc(w, func(_r rune) bool {
 			return !unicode.IsLetter(_r) && !unicode.IsDigit(_r)
 		})
@@ -254,8 +483,9 @@ func dropPunctuation(originalWords []string) []string {
 			continue
 		}
 		res = append(res, nw)
+		indexes = append(indexes, i)
 	}
-	return res
+	return res, indexes
 }
 
 func getLastWordTo(aligned []int, i int, maps []*wordMapData, sampleRate uint32, bitsPerSample uint16) time.Duration {
@@ -283,13 +513,18 @@ func getLastWordTo(aligned []int, i int, maps []*wordMapData, sampleRate uint32,
 func collectWords(parts []*TTSDataPart) ([]string, []*wordMapData) {
 	var words []string
 	var maps []*wordMapData
+	sentence := 0
 	for _, p := range parts {
 		for _, w := range p.Words {
+			if w.Tagged.SentenceEnd {
+				sentence++
+			}
 			if w.Tagged.IsWord() {
 				words = append(words, w.Tagged.Word)
 				maps = append(maps,
 					&wordMapData{
-						pw: w,
+						pw:       w,
+						sentence: sentence,
 					})
 			}
 		}
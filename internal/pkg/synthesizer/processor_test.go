@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/airenas/tts-line/pkg/ssml"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,6 +64,22 @@ func TestWork_Several(t *testing.T) {
 	assert.Equal(t, "wavmp3", string(res.Audio))
 }
 
+func TestWork_SkippedStages(t *testing.T) {
+	initTest(t)
+	worker.Add(skipProcMock("obscene"))
+	worker.Add(skipProcMock("clitics"))
+	res, err := worker.Work(context.TODO(), &api.TTSRequestConfig{Text: "olia"})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"obscene", "clitics"}, res.SkippedStages)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(degradedMetrics), 0.000001)
+
+	initTest(t)
+	res, err = worker.Work(context.TODO(), &api.TTSRequestConfig{Text: "olia"})
+	require.Nil(t, err)
+	assert.Nil(t, res.SkippedStages)
+	assert.InDelta(t, 1.0, testutil.ToFloat64(degradedMetrics), 0.000001)
+}
+
 func TestWork_HasUUID(t *testing.T) {
 	initTest(t)
 	res, _ := worker.Work(context.TODO(), &api.TTSRequestConfig{Text: "olia", AllowCollectData: true, OutputTextFormat: api.TextNormalized})
@@ -129,7 +147,7 @@ func TestMapResult_Accented(t *testing.T) {
 		AccentVariant: &AccentVariant{Accent: 101}},
 		{Tagged: TaggedWord{Space: true}}, {Tagged: TaggedWord{Separator: ","}},
 		{Tagged: TaggedWord{Word: "ai"}, AccentVariant: &AccentVariant{Accent: 302}}}}}
-	res, err := mapResult(context.TODO(), d)
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.Nil(t, err)
 	assert.Equal(t, "{a\\}a ,a{i~}", res.Text)
 }
@@ -141,7 +159,7 @@ func TestMapResult_Normalized(t *testing.T) {
 	d.Parts = []*TTSDataPart{
 		{Words: []*ProcessedWord{{Tagged: TaggedWord{Word: "oo"}}}},
 	}
-	res, err := mapResult(context.TODO(), d)
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.Nil(t, err)
 	assert.Equal(t, "oo", res.Text)
 }
@@ -150,7 +168,7 @@ func TestMapResult_Transcribed(t *testing.T) {
 	d := &TTSData{}
 	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextTranscribed}
 	d.Parts = []*TTSDataPart{{TranscribedText: "a b c sil"}, {TranscribedText: "d sil"}}
-	res, err := mapResult(context.TODO(), d)
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.Nil(t, err)
 	assert.Equal(t, "a b c sil d sil", res.Text)
 }
@@ -160,24 +178,32 @@ func TestMapResult_Transcribed_SSML(t *testing.T) {
 	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextTranscribed}
 	d.SSMLParts = []*TTSData{{Parts: []*TTSDataPart{{TranscribedText: "a b c sil"}, {TranscribedText: "d sil"}}},
 		{Parts: []*TTSDataPart{{TranscribedText: "c , sil"}}}}
-	res, err := mapResult(context.TODO(), d)
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.Nil(t, err)
 	assert.Equal(t, "a b c sil d sil c , sil", res.Text)
 }
 
+func TestMapResult_VTT(t *testing.T) {
+	d := newTestMarksData()
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextVTT}
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
+	assert.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.200\nLabas, rytas.\n\n00:00:00.300 --> 00:00:00.500\nKaip sekasi?\n", res.Text)
+}
+
 func TestMapResult_AccentedFail(t *testing.T) {
 	d := &TTSData{}
 	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextAccented}
 	d.Parts = []*TTSDataPart{{Words: []*ProcessedWord{{Tagged: TaggedWord{Word: "aa"},
 		AccentVariant: &AccentVariant{Accent: 401}}}}}
-	_, err := mapResult(context.TODO(), d)
+	_, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.NotNil(t, err)
 }
 
 func TestMapResult_FailOutputTextType(t *testing.T) {
 	d := &TTSData{}
 	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextFormatEnum(10)}
-	_, err := mapResult(context.TODO(), d)
+	_, err := (&MainWorker{}).mapResult(context.TODO(), d)
 	assert.NotNil(t, err)
 }
 
@@ -189,6 +215,14 @@ func (pr *procMock) Process(ctx context.Context, d *TTSData) error {
 	return pr.f(d)
 }
 
+// skipProcMock marks the stage as skipped like the optional stage wrapper
+type skipProcMock string
+
+func (pr skipProcMock) Process(ctx context.Context, d *TTSData) error {
+	utils.SkippedStagesFromContext(ctx).Add(string(pr))
+	return nil
+}
+
 func Test_makeSSMLParts(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -207,6 +241,28 @@ func Test_makeSSMLParts(t *testing.T) {
 			want: []*TTSData{{Cfg: TTSConfig{Type: SSMLPause, PauseDuration: time.Second}},
 				{OriginalTextParts: []*TTSTextPart{{Text: "oo", Prosodies: []*ssml.Prosody{{Rate: 0.6}}}}, Cfg: TTSConfig{Type: SSMLText, Voice: "aa"}}},
 			wantErr: false},
+		{name: "marks", args: &api.TTSRequestConfig{SSMLParts: []ssml.Part{&ssml.Mark{Name: "m1"},
+			&ssml.Text{Voice: "aa", Texts: []ssml.TextPart{{Text: "oo", Source: "oo", Start: 20}}},
+			&ssml.Mark{Name: "m2"}, &ssml.Mark{Name: "m3"},
+			&ssml.Text{Voice: "aa", Texts: []ssml.TextPart{{Text: "oo1"}}},
+			&ssml.Mark{Name: "m4"}}},
+			want: []*TTSData{{OriginalTextParts: []*TTSTextPart{{Text: "oo", Marks: []*ssml.Mark{{Name: "m1"}}, Source: "oo", Start: 20},
+				{Text: "oo1", Marks: []*ssml.Mark{{Name: "m2"}, {Name: "m3"}}, EndMarks: []*ssml.Mark{{Name: "m4"}}}},
+				Cfg: TTSConfig{Type: SSMLText, Voice: "aa"}}},
+			wantErr: false},
+		{name: "sub", args: &api.TTSRequestConfig{SSMLParts: []ssml.Part{&ssml.Text{Voice: "aa",
+			Texts: []ssml.TextPart{{Text: "Jungtinės Amerikos Valstijos", Written: "JAV"}}}}},
+			want: []*TTSData{{OriginalTextParts: []*TTSTextPart{{Text: "Jungtinės Amerikos Valstijos", Written: "JAV"}},
+				Cfg: TTSConfig{Type: SSMLText, Voice: "aa"}}},
+			wantErr: false},
+		{name: "audio", args: &api.TTSRequestConfig{SSMLParts: []ssml.Part{&ssml.Text{Voice: "aa", Texts: []ssml.TextPart{{Text: "oo"}}},
+			&ssml.Audio{Src: "jingle"}, &ssml.Pause{Duration: time.Millisecond * 200},
+			&ssml.Text{Voice: "aa", Texts: []ssml.TextPart{{Text: "oo1"}}}}},
+			want: []*TTSData{{OriginalTextParts: []*TTSTextPart{{Text: "oo"}}, Cfg: TTSConfig{Type: SSMLText, Voice: "aa"}},
+				{Cfg: TTSConfig{Type: SSMLAudio, AudioSrc: "jingle"}},
+				{Cfg: TTSConfig{Type: SSMLPause, PauseDuration: time.Millisecond * 200}},
+				{OriginalTextParts: []*TTSTextPart{{Text: "oo1"}}, Cfg: TTSConfig{Type: SSMLText, Voice: "aa"}}},
+			wantErr: false},
 		{name: "fail", args: &api.TTSRequestConfig{SSMLParts: []ssml.Part{struct{}{}}},
 			want:    []*TTSData{},
 			wantErr: true},
@@ -228,6 +284,7 @@ func Test_makeSSMLParts(t *testing.T) {
 				assert.Equal(t, tt.want[i].Cfg.Voice, got[i].Cfg.Voice)
 				// assert.Equal(t, tt.want[i].Cfg.Prosodies, got[i].Cfg.Prosodies)
 				assert.Equal(t, tt.want[i].Cfg.Type, got[i].Cfg.Type)
+				assert.Equal(t, tt.want[i].Cfg.AudioSrc, got[i].Cfg.AudioSrc)
 			}
 		})
 	}
@@ -268,21 +325,26 @@ func Test_dropPunctuation(t *testing.T) {
 		// Named input parameters for target function.
 		originalWords []string
 		want          []string
+		wantIndexes   []int
 	}{
 		{name: "with punctuation", originalWords: []string{"hello", ",", "world", "!"},
-			want: []string{"hello", "world"}},
+			want: []string{"hello", "world"}, wantIndexes: []int{0, 2}},
 		{name: "without punctuation", originalWords: []string{"hello", "world"},
-			want: []string{"hello", "world"}},
+			want: []string{"hello", "world"}, wantIndexes: []int{0, 1}},
 		{name: "only punctuation", originalWords: []string{".", "!", "?"}, want: nil},
 		{name: "empty input", originalWords: []string{}, want: nil},
-		{name: "middle", originalWords: []string{"o-o"}, want: []string{"o-o"}},
+		{name: "middle", originalWords: []string{"o-o"}, want: []string{"o-o"}, wantIndexes: []int{0}},
+		{name: "trim", originalWords: []string{"(hello", "world)."}, want: []string{"hello", "world"}, wantIndexes: []int{0, 1}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := dropPunctuation(tt.originalWords)
+			got, gotIndexes := dropPunctuation(tt.originalWords)
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("dropPunctuation() = %v, want %v", got, tt.want)
 			}
+			if !reflect.DeepEqual(gotIndexes, tt.wantIndexes) {
+				t.Errorf("dropPunctuation() indexes = %v, want %v", gotIndexes, tt.wantIndexes)
+			}
 		})
 	}
 }
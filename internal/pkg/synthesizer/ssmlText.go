@@ -0,0 +1,237 @@
+package synthesizer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/airenas/tts-line/internal/pkg/accent"
+	"github.com/airenas/tts-line/internal/pkg/utils"
+	"github.com/airenas/tts-line/pkg/ssml"
+)
+
+// mapSSMLText makes the <speak> document with the accents and syllables of every word.
+// The document keeps voices, pauses, prosodies and the request speed, so it is synthesized into the same audio
+func mapSSMLText(data *TTSData) (string, error) {
+	w := &ssmlWriter{}
+	w.res.WriteString(`<speak xmlns:intelektika="urn:intelektika">`)
+	var speed *ssml.Prosody
+	if !utils.Float64Equals(data.Cfg.SpeedRate, 0) && !utils.Float64Equals(data.Cfg.SpeedRate, 1) {
+		speed = &ssml.Prosody{Rate: data.Cfg.SpeedRate}
+		w.res.WriteString(speed.StartTag())
+	}
+	if data.Cfg.Type == SSMLMain {
+		for _, sd := range data.SSMLParts {
+			switch sd.Cfg.Type {
+			case SSMLPause:
+				w.writeBreak(sd.Cfg.PauseDuration)
+			case SSMLAudio:
+				fmt.Fprintf(&w.res, `<audio src="%s"/>`, escapeXML(sd.Cfg.AudioSrc))
+			case SSMLText:
+				if err := w.writeSSMLText(sd); err != nil {
+					return "", err
+				}
+			}
+		}
+	} else {
+		fmt.Fprintf(&w.res, `<voice name="%s">`, escapeXML(data.Cfg.Voice))
+		if err := w.writeWords(data.Words); err != nil {
+			return "", err
+		}
+		w.res.WriteString("</voice>")
+	}
+	if speed != nil {
+		w.res.WriteString(speed.EndTag())
+	}
+	w.res.WriteString("</speak>")
+	return w.res.String(), nil
+}
+
+type ssmlWriter struct {
+	res     strings.Builder
+	wasWord bool            // the last written token is a word, the next word needs a space
+	opened  []*ssml.Prosody // opened <prosody> and <emphasis> tags
+}
+
+func (w *ssmlWriter) writeSSMLText(data *TTSData) error {
+	fmt.Fprintf(&w.res, `<voice name="%s">`, escapeXML(data.Cfg.Voice))
+	partWords := map[*TTSTextPart][]*ProcessedWord{}
+	for _, pw := range data.Words {
+		partWords[pw.TextPart] = append(partWords[pw.TextPart], pw)
+	}
+	for _, tp := range data.OriginalTextParts {
+		w.openProsodies(tp.Prosodies)
+		if err := w.writePart(tp, partWords[tp]); err != nil {
+			return err
+		}
+	}
+	w.openProsodies(nil)
+	w.res.WriteString("</voice>")
+	return nil
+}
+
+// openProsodies closes the tags not in the list and opens the new ones
+func (w *ssmlWriter) openProsodies(prosodies []*ssml.Prosody) {
+	same := 0
+	for same < len(w.opened) && same < len(prosodies) && w.opened[same] == prosodies[same] {
+		same++
+	}
+	for i := len(w.opened) - 1; i >= same; i-- {
+		w.res.WriteString(w.opened[i].EndTag())
+	}
+	for _, p := range prosodies[same:] {
+		w.res.WriteString(p.StartTag())
+	}
+	w.opened = append(w.opened[:same], prosodies[same:]...)
+}
+
+func (w *ssmlWriter) writePart(tp *TTSTextPart, words []*ProcessedWord) error {
+	w.writeMarks(tp.Marks)
+	if tp.SentenceEnd {
+		w.res.WriteString("<s>")
+	}
+	if tp.Language != "" {
+		fmt.Fprintf(&w.res, `<lang xml:lang="%s">`, escapeXML(tp.Language))
+	}
+	switch {
+	case tp.Transcription != "":
+		w.writeSpace()
+		fmt.Fprintf(&w.res, `<phoneme alphabet="%s" ph="%s">%s</phoneme>`, ssml.AlphabetIntelektika,
+			escapeXML(tp.Transcription), escapeXML(tp.Text))
+		w.wasWord = true
+	case tp.InterpretAs == ssml.InterpretAsTypeCharacters:
+		w.writeSpace()
+		w.res.WriteString(`<say-as interpret-as="characters"`)
+		if tp.InterpretAsDetail != ssml.InterpretAsDetailTypeUnset {
+			fmt.Fprintf(&w.res, ` detail="%s"`, tp.InterpretAsDetail.String())
+		}
+		fmt.Fprintf(&w.res, ">%s</say-as>", escapeXML(tp.Text))
+		w.wasWord = true
+	default:
+		if err := w.writeWords(words); err != nil {
+			return err
+		}
+	}
+	if tp.Language != "" {
+		w.res.WriteString("</lang>")
+	}
+	if tp.SentenceEnd {
+		w.res.WriteString("</s>")
+	}
+	if tp.PauseAfter > 0 {
+		w.writeBreak(tp.PauseAfter)
+	}
+	w.writeMarks(tp.EndMarks)
+	return nil
+}
+
+func (w *ssmlWriter) writeWords(words []*ProcessedWord) error {
+	for _, pw := range words {
+		tgw := pw.Tagged
+		if tgw.Space {
+			w.res.WriteString(" ")
+			w.wasWord = false
+		} else if tgw.Separator != "" {
+			w.res.WriteString(escapeXML(tgw.Separator))
+			w.wasWord = false
+		} else if tgw.IsWord() {
+			if err := w.writeWord(pw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *ssmlWriter) writeWord(pw *ProcessedWord) error {
+	word := pw.Tagged.Word
+	acc, err := accent.ToAccentString(word, GetTranscriberAccent(pw))
+	if err != nil {
+		return errors.Wrapf(err, "can't mark accent for %s", word)
+	}
+	if !ssml.IsAccentedWord(acc) { // would be rejected by the SSML parser
+		if acc != word {
+			return errors.Errorf("can't write accent %s as <intelektika:w>", acc)
+		}
+		w.writeSpace()
+		w.wasWord = true
+		w.res.WriteString(escapeXML(word))
+		return nil
+	}
+	w.writeSpace()
+	w.wasWord = true
+	fmt.Fprintf(&w.res, `<intelektika:w acc="%s"`, escapeXML(acc))
+	if syll := matchSyllables(word, getWordSyllables(pw)); syll != "" {
+		fmt.Fprintf(&w.res, ` syll="%s"`, escapeXML(syll))
+	}
+	if tp := pw.TextPart; tp != nil && tp.Accented != "" && tp.UserOEPal != "" {
+		fmt.Fprintf(&w.res, ` user="%s"`, escapeXML(tp.UserOEPal))
+	}
+	fmt.Fprintf(&w.res, ">%s</intelektika:w>", escapeXML(word))
+	return nil
+}
+
+func (w *ssmlWriter) writeSpace() {
+	if w.wasWord {
+		w.res.WriteString(" ")
+	}
+}
+
+func (w *ssmlWriter) writeBreak(d time.Duration) {
+	fmt.Fprintf(&w.res, `<break time="%s"/>`, d.String())
+	w.wasWord = false
+}
+
+func (w *ssmlWriter) writeMarks(marks []*ssml.Mark) {
+	for _, m := range marks {
+		fmt.Fprintf(&w.res, `<mark name="%s"/>`, escapeXML(m.Name))
+	}
+}
+
+func getWordSyllables(pw *ProcessedWord) string {
+	if pw.TextPart != nil && pw.TextPart.Accented != "" && pw.TextPart.Syllables != "" {
+		return pw.TextPart.Syllables
+	}
+	if pw.UserSyllables != "" {
+		return pw.UserSyllables
+	}
+	if pw.AccentVariant != nil {
+		return pw.AccentVariant.Syll
+	}
+	return ""
+}
+
+// matchSyllables returns the word split by '-' as in syll, or "" if the letters do not match
+func matchSyllables(word, syll string) string {
+	if !strings.Contains(syll, "-") {
+		return ""
+	}
+	wr, sr := []rune(word), []rune(syll)
+	res := strings.Builder{}
+	i := 0
+	for _, r := range sr {
+		if r == '-' {
+			res.WriteRune(r)
+			continue
+		}
+		if i >= len(wr) || unicode.ToLower(wr[i]) != unicode.ToLower(r) {
+			return ""
+		}
+		res.WriteRune(wr[i])
+		i++
+	}
+	if i != len(wr) {
+		return ""
+	}
+	return res.String()
+}
+
+func escapeXML(s string) string {
+	res := strings.Builder{}
+	_ = xml.EscapeText(&res, []byte(s))
+	return res.String()
+}
@@ -0,0 +1,131 @@
+package synthesizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/airenas/tts-line/internal/pkg/accent"
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/pkg/ssml"
+)
+
+func TestMapResult_SSML(t *testing.T) {
+	d := &TTSData{}
+	d.Cfg.Voice = "astra"
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextSSML}
+	d.Words = []*ProcessedWord{{Tagged: TaggedWord{Word: "Labas"}, AccentVariant: &AccentVariant{Accent: 202, Syll: "la-bas"}},
+		{Tagged: TaggedWord{Space: true}},
+		{Tagged: TaggedWord{Word: "rytas"}, AccentVariant: &AccentVariant{Accent: 0, Syll: "ry-tas"}, Clitic: Clitic{Type: CliticsNone}},
+		{Tagged: TaggedWord{Word: "a&b"}, AccentVariant: &AccentVariant{}},
+		{Tagged: TaggedWord{Separator: "<"}}, {Tagged: TaggedWord{SentenceEnd: true}}}
+	res, err := (&MainWorker{}).mapResult(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, `<speak xmlns:intelektika="urn:intelektika"><voice name="astra">`+
+		`<intelektika:w acc="L{a/}bas" syll="La-bas">Labas</intelektika:w> <intelektika:w acc="rytas" syll="ry-tas">rytas</intelektika:w>`+
+		` a&amp;b&lt;</voice></speak>`, res.Text)
+}
+
+func TestMapResult_SSMLFail(t *testing.T) {
+	d := &TTSData{}
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextSSML}
+	d.Words = []*ProcessedWord{{Tagged: TaggedWord{Word: "aa"}, AccentVariant: &AccentVariant{Accent: 401}}}
+	_, err := (&MainWorker{}).mapResult(context.TODO(), d)
+	assert.NotNil(t, err)
+}
+
+func TestMapSSMLText_SSML(t *testing.T) {
+	in := `<speak><mark name="m1"/>Labas <prosody rate="150%"><emphasis>rytas</emphasis> <intelektika:w acc="{o/}le" syll="o-le" user="O*l'E">ole</intelektika:w></prosody>` +
+		`<break time="300ms"/><s>Kas</s><break time="2s"/><audio src="ding"/>` +
+		`<voice name="v2"><say-as interpret-as="characters">ABC</say-as> <phoneme alphabet="x-intelektika" ph="a - b">ab</phoneme>` +
+		` <lang xml:lang="en">hi</lang></voice><mark name="m2"/></speak>`
+	d := newTestSSMLTextData(t, in)
+	res, err := mapSSMLText(d)
+	require.Nil(t, err)
+	assert.Equal(t, `<speak xmlns:intelektika="urn:intelektika"><voice name="astra"><mark name="m1"/>`+
+		`<intelektika:w acc="Labas">Labas</intelektika:w>`+
+		`<prosody rate="150%"><emphasis level="moderate"> <intelektika:w acc="rytas">rytas</intelektika:w></emphasis> `+
+		`<intelektika:w acc="{o/}le" syll="o-le" user="O*l&#39;E">ole</intelektika:w><break time="300ms"/></prosody>`+
+		`<s><intelektika:w acc="Kas">Kas</intelektika:w></s></voice><break time="2s"/><audio src="ding"/>`+
+		`<voice name="v2"><say-as interpret-as="characters">ABC</say-as> <phoneme alphabet="x-intelektika" ph="a - b">ab</phoneme>`+
+		`<lang xml:lang="en"> <intelektika:w acc="hi">hi</intelektika:w></lang><mark name="m2"/></voice></speak>`, res)
+
+	again := newTestSSMLTextData(t, res)
+	res2, err := mapSSMLText(again)
+	require.Nil(t, err)
+	assert.Equal(t, res, res2)
+}
+
+// newTestSSMLTextData parses SSML and splits the texts into words as the tagger would do
+func newTestSSMLTextData(t *testing.T, in string) *TTSData {
+	t.Helper()
+	parts, err := ssml.Parse(strings.NewReader(in), &ssml.Text{Voice: "astra"}, func(s string) (string, error) { return s, nil })
+	require.Nil(t, err)
+	res := &TTSData{Cfg: TTSConfig{Type: SSMLMain}}
+	res.SSMLParts, err = makeSSMLParts(&api.TTSRequestConfig{SSMLParts: parts})
+	require.Nil(t, err)
+	for _, sd := range res.SSMLParts {
+		for _, tp := range sd.OriginalTextParts {
+			for i, s := range strings.Split(tp.Text, " ") {
+				if i > 0 {
+					sd.Words = append(sd.Words, &ProcessedWord{Tagged: TaggedWord{Space: true}, TextPart: tp})
+				}
+				if s != "" {
+					sd.Words = append(sd.Words, &ProcessedWord{Tagged: TaggedWord{Word: s}, TextPart: tp,
+						AccentVariant: &AccentVariant{}, UserAccent: testUserAccent(tp.Accented)})
+				}
+			}
+		}
+	}
+	return res
+}
+
+func testUserAccent(accented string) int {
+	rns := []rune(accented)
+	for i, r := range rns {
+		if r == '{' {
+			return accent.Value(rns[i+2])*100 + i + 1
+		}
+	}
+	return 0
+}
+
+func TestMapSSMLText_RoundTrip(t *testing.T) {
+	long := strings.Repeat("a", 49)
+	d := &TTSData{}
+	d.Cfg.Voice = "astra"
+	d.Cfg.SpeedRate = 1.5
+	d.Words = []*ProcessedWord{{Tagged: TaggedWord{Word: "Labas"}, AccentVariant: &AccentVariant{Accent: 202}},
+		{Tagged: TaggedWord{Space: true}},
+		{Tagged: TaggedWord{Word: "ilgas" + long[5:]}, AccentVariant: &AccentVariant{Accent: 101}},
+		{Tagged: TaggedWord{Space: true}},
+		{Tagged: TaggedWord{Word: "rytas"}, AccentVariant: &AccentVariant{}},
+		{Tagged: TaggedWord{Separator: "."}}}
+	res, err := mapSSMLText(d)
+	require.Nil(t, err)
+
+	parts, err := ssml.Parse(strings.NewReader(res), &ssml.Text{Voice: "astra"}, func(s string) (string, error) { return s, nil })
+	require.Nil(t, err)
+	require.Equal(t, 1, len(parts))
+	text, ok := parts[0].(*ssml.Text)
+	require.True(t, ok)
+	require.Equal(t, 1, len(text.Prosodies))
+	assert.InDelta(t, 1.5, text.Prosodies[0].Rate, 0.0001)
+	var words, accents []string
+	for _, tp := range text.Texts {
+		words = append(words, tp.Text)
+		accents = append(accents, tp.Accented)
+	}
+	assert.Equal(t, []string{"Labas", "ilgas" + long[5:], "rytas", "."}, words)
+	assert.Equal(t, []string{"L{a/}bas", `{i\}lgas` + long[5:], "rytas", ""}, accents)
+}
+
+func TestMapSSMLText_FailAccentedNonWord(t *testing.T) {
+	d := &TTSData{}
+	d.Words = []*ProcessedWord{{Tagged: TaggedWord{Word: strings.Repeat("a", 50)}, AccentVariant: &AccentVariant{Accent: 101}}}
+	_, err := mapSSMLText(d)
+	assert.NotNil(t, err)
+}
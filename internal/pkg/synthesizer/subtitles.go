@@ -0,0 +1,178 @@
+package synthesizer
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/spf13/viper"
+)
+
+// Subtitles groups the aligned words into WebVTT or SRT cues
+type Subtitles struct {
+	maxLineChars int
+	maxLines     int
+	maxDuration  time.Duration
+}
+
+// NewSubtitles creates the subtitles formatter.
+// The config may override 'maxLineChars', 'maxLines' and 'maxDuration' of a cue
+func NewSubtitles(cfg *viper.Viper) (*Subtitles, error) {
+	res := &Subtitles{maxLineChars: 42, maxLines: 2, maxDuration: 7 * time.Second}
+	if cfg == nil {
+		return res, nil
+	}
+	if cfg.IsSet("maxLineChars") {
+		res.maxLineChars = cfg.GetInt("maxLineChars")
+	}
+	if cfg.IsSet("maxLines") {
+		res.maxLines = cfg.GetInt("maxLines")
+	}
+	if cfg.IsSet("maxDuration") {
+		res.maxDuration = cfg.GetDuration("maxDuration")
+	}
+	if res.maxLineChars < 1 {
+		return nil, fmt.Errorf("wrong maxLineChars %d", res.maxLineChars)
+	}
+	if res.maxLines < 1 {
+		return nil, fmt.Errorf("wrong maxLines %d", res.maxLines)
+	}
+	if res.maxDuration <= 0 {
+		return nil, fmt.Errorf("wrong maxDuration %s", res.maxDuration.String())
+	}
+	return res, nil
+}
+
+type cue struct {
+	from, to time.Duration
+	lines    []string
+	part     int
+	sentence int
+}
+
+// Map returns the subtitles for the OutputTextFormat vtt or srt
+func (s *Subtitles) Map(ctx context.Context, data *TTSData) (string, error) {
+	cues, err := s.makeCues(ctx, data)
+	if err != nil {
+		return "", err
+	}
+	switch data.Input.OutputTextFormat {
+	case api.TextVTT:
+		return formatVTT(cues), nil
+	case api.TextSRT:
+		return formatSRT(cues), nil
+	}
+	return "", fmt.Errorf("no subtitles for OutputTextFormat %s", data.Input.OutputTextFormat.String())
+}
+
+func (s *Subtitles) makeCues(ctx context.Context, data *TTSData) ([]*cue, error) {
+	parts := data.SSMLParts
+	if len(parts) == 0 {
+		parts = []*TTSData{data}
+	}
+	var res []*cue
+	for pi, p := range parts {
+		if p.Audio == nil {
+			continue
+		}
+		words, fields, sources, err := alignWords(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		texts := cueTexts(fields, sources, len(data.SSMLParts) > 0)
+		for i, w := range words {
+			// the text of the word takes the unaligned fields and the punctuation before it,
+			// the last word takes the rest of the text
+			fromField := 0
+			if i > 0 {
+				fromField = words[i-1].field + 1
+			}
+			toField := w.field
+			if i == len(words)-1 {
+				toField = len(fields) - 1
+			}
+			res = s.add(res, pi, w, joinFields(texts[fromField:toField+1]))
+		}
+	}
+	return res, nil
+}
+
+// cueTexts returns the texts of the fields as they are in the user's input,
+// the cleaned field is used if it is not mapped to the input
+func cueTexts(fields, sources []string, ssml bool) []string {
+	res := make([]string, len(fields))
+	for i, f := range fields {
+		res[i] = f
+		if i < len(sources) && sources[i] != "" {
+			res[i] = sources[i]
+			if ssml {
+				res[i] = html.UnescapeString(res[i])
+			}
+			res[i] = strings.Join(strings.Fields(res[i]), " ")
+		}
+	}
+	return res
+}
+
+func (s *Subtitles) add(cues []*cue, part int, w *alignedWord, text string) []*cue {
+	if len(cues) > 0 {
+		last := cues[len(cues)-1]
+		if last.part == part && last.sentence == w.sentence && w.to-last.from <= s.maxDuration && s.addText(last, text) {
+			last.to = w.to
+			return cues
+		}
+	}
+	return append(cues, &cue{from: w.from, to: w.to, lines: []string{text}, part: part, sentence: w.sentence})
+}
+
+func (s *Subtitles) addText(c *cue, text string) bool {
+	l := c.lines[len(c.lines)-1]
+	if utf8.RuneCountInString(l)+1+utf8.RuneCountInString(text) <= s.maxLineChars {
+		c.lines[len(c.lines)-1] = l + " " + text
+		return true
+	}
+	if len(c.lines) < s.maxLines {
+		c.lines = append(c.lines, text)
+		return true
+	}
+	return false
+}
+
+func formatVTT(cues []*cue) string {
+	res := strings.Builder{}
+	res.WriteString("WEBVTT\n")
+	for _, c := range cues {
+		res.WriteString("\n")
+		res.WriteString(fmt.Sprintf("%s --> %s\n", formatCueTime(c.from, "."), formatCueTime(c.to, ".")))
+		writeCueLines(&res, c)
+	}
+	return res.String()
+}
+
+func formatSRT(cues []*cue) string {
+	res := strings.Builder{}
+	for i, c := range cues {
+		if i > 0 {
+			res.WriteString("\n")
+		}
+		res.WriteString(fmt.Sprintf("%d\n%s --> %s\n", i+1, formatCueTime(c.from, ","), formatCueTime(c.to, ",")))
+		writeCueLines(&res, c)
+	}
+	return res.String()
+}
+
+func writeCueLines(res *strings.Builder, c *cue) {
+	for _, l := range c.lines {
+		res.WriteString(l)
+		res.WriteString("\n")
+	}
+}
+
+func formatCueTime(d time.Duration, msSep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, msSep, ms%1000)
+}
@@ -0,0 +1,130 @@
+package synthesizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/airenas/tts-line/internal/pkg/service/api"
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSubtitles(t *testing.T) {
+	s, err := NewSubtitles(nil)
+	require.Nil(t, err)
+	assert.Equal(t, &Subtitles{maxLineChars: 42, maxLines: 2, maxDuration: 7 * time.Second}, s)
+	s, err = NewSubtitles(test.NewConfig(t, "maxLineChars: 20\nmaxLines: 1\nmaxDuration: 3s"))
+	require.Nil(t, err)
+	assert.Equal(t, &Subtitles{maxLineChars: 20, maxLines: 1, maxDuration: 3 * time.Second}, s)
+}
+
+func TestNewSubtitles_Fail(t *testing.T) {
+	for _, c := range []string{"maxLineChars: 0", "maxLines: 0", "maxDuration: 0s"} {
+		_, err := NewSubtitles(test.NewConfig(t, c))
+		assert.NotNil(t, err, c)
+	}
+}
+
+func TestSubtitles_SRT(t *testing.T) {
+	d := newTestMarksData()
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextSRT}
+	s, _ := NewSubtitles(nil)
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:00,200\nLabas, rytas.\n\n2\n00:00:00,300 --> 00:00:00,500\nKaip sekasi?\n", res)
+}
+
+func TestSubtitles_Lines(t *testing.T) {
+	d := newTestMarksData()
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextVTT}
+	s := &Subtitles{maxLineChars: 7, maxLines: 2, maxDuration: time.Second}
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.200\nLabas,\nrytas.\n\n"+
+		"00:00:00.300 --> 00:00:00.500\nKaip\nsekasi?\n", res)
+	s = &Subtitles{maxLineChars: 7, maxLines: 1, maxDuration: time.Second}
+	res, err = s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.100\nLabas,\n\n00:00:00.100 --> 00:00:00.200\nrytas.\n\n"+
+		"00:00:00.300 --> 00:00:00.400\nKaip\n\n00:00:00.400 --> 00:00:00.500\nsekasi?\n", res)
+}
+
+func TestSubtitles_Duration(t *testing.T) {
+	d := newTestMarksData()
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextVTT}
+	s := &Subtitles{maxLineChars: 42, maxLines: 2, maxDuration: 200 * time.Millisecond}
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.200\nLabas, rytas.\n\n"+
+		"00:00:00.300 --> 00:00:00.500\nKaip sekasi?\n", res)
+	s.maxDuration = 150 * time.Millisecond
+	res, err = s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.100\nLabas,\n\n00:00:00.100 --> 00:00:00.200\nrytas.\n\n"+
+		"00:00:00.300 --> 00:00:00.400\nKaip\n\n00:00:00.400 --> 00:00:00.500\nsekasi?\n", res)
+}
+
+func TestSubtitles_SSML(t *testing.T) {
+	audio := &AudioData{SampleRate: 1000, BitsPerSample: 8}
+	d := &TTSData{Audio: audio, Input: &api.TTSRequestConfig{OutputTextFormat: api.TextVTT},
+		SSMLParts: []*TTSData{
+			{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Labas", "rytas"},
+				Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+					newTestMarksWord("labas", 0, 100, nil),
+					newTestMarksWord("rytas", 100, 200, nil),
+				}}}},
+			{Cfg: TTSConfig{Type: SSMLPause}},
+			{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Kaip"},
+				Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+					newTestMarksWord("kaip", 1200, 1300, nil),
+				}}}},
+		}}
+	s, _ := NewSubtitles(nil)
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.200\nLabas rytas\n\n00:00:01.200 --> 00:00:01.300\nKaip\n", res)
+}
+
+func TestSubtitles_Sub(t *testing.T) {
+	d := newTestSubData()
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextSRT}
+	s, _ := NewSubtitles(nil)
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:00,500\nValstybė JAV yra.\n", res)
+}
+
+func TestFormatCueTime(t *testing.T) {
+	assert.Equal(t, "00:00:00.000", formatCueTime(0, "."))
+	assert.Equal(t, "01:02:03,045", formatCueTime(time.Hour+2*time.Minute+3*time.Second+45*time.Millisecond, ","))
+}
+
+func TestSubtitles_SourceText(t *testing.T) {
+	d := newTestMarksData()
+	d.OriginalTextParts = []*TTSTextPart{{Text: d.CleanedText[0], Source: "Labas, „rytas“. Kaip sekasi?!"}}
+	d.Input = &api.TTSRequestConfig{OutputTextFormat: api.TextSRT}
+	s, _ := NewSubtitles(nil)
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "1\n00:00:00,000 --> 00:00:00,200\nLabas, „rytas“.\n\n2\n00:00:00,300 --> 00:00:00,500\nKaip sekasi?!\n", res)
+}
+
+func TestSubtitles_SSMLSourceText(t *testing.T) {
+	audio := &AudioData{SampleRate: 1000, BitsPerSample: 8}
+	d := &TTSData{Audio: audio, Input: &api.TTSRequestConfig{OutputTextFormat: api.TextVTT},
+		SSMLParts: []*TTSData{
+			{Cfg: TTSConfig{Type: SSMLText}, Audio: audio, CleanedText: []string{"Labas &", "rytas"},
+				OriginalTextParts: []*TTSTextPart{{Text: "Labas &", Source: "Labas &amp;", Start: 7},
+					{Text: "rytas", Source: "rytas", Start: 29}},
+				Parts: []*TTSDataPart{{Words: []*ProcessedWord{
+					newTestMarksWord("labas", 0, 100, nil),
+					newTestMarksWord("rytas", 100, 200, nil),
+				}}}},
+		}}
+	s, _ := NewSubtitles(nil)
+	res, err := s.Map(context.TODO(), d)
+	require.Nil(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:00.000 --> 00:00:00.200\nLabas & rytas\n", res)
+}
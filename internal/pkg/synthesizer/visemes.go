@@ -0,0 +1,95 @@
+package synthesizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// VisemeSilence is a viseme of pause symbols, no marks are produced for it
+const VisemeSilence = "sil"
+
+// VisemeGroup maps phonemes to one viseme
+type VisemeGroup struct {
+	Viseme   string   `mapstructure:"viseme"`
+	Phonemes []string `mapstructure:"phonemes"`
+}
+
+// defaultVisemes uses the viseme names of Amazon Polly
+var defaultVisemes = []VisemeGroup{
+	{Viseme: "p", Phonemes: []string{"p", "b", "m"}},
+	{Viseme: "f", Phonemes: []string{"f", "v"}},
+	{Viseme: "t", Phonemes: []string{"t", "d", "n", "l"}},
+	{Viseme: "s", Phonemes: []string{"s", "z", "ts", "dz", "c"}},
+	{Viseme: "S", Phonemes: []string{"S", "Z", "tS", "dZ"}},
+	{Viseme: "k", Phonemes: []string{"k", "g", "x", "h", "N", "G"}},
+	{Viseme: "r", Phonemes: []string{"r"}},
+	{Viseme: "i", Phonemes: []string{"i", "I", "j", "ie"}},
+	{Viseme: "u", Phonemes: []string{"u", "U", "w", "uo", "iu"}},
+	{Viseme: "e", Phonemes: []string{"e", "eu", "Eu"}},
+	{Viseme: "E", Phonemes: []string{"E"}},
+	{Viseme: "a", Phonemes: []string{"a"}},
+	{Viseme: "o", Phonemes: []string{"o", "O", "io", "iO"}},
+	{Viseme: VisemeSilence, Phonemes: []string{"sil", "sp"}},
+}
+
+// Visemes maps transcription symbols to visemes
+type Visemes struct {
+	table map[string]string
+	def   string
+}
+
+// NewVisemes creates the phoneme to viseme table.
+// The config may override the viseme for unknown phonemes ('default') and add or replace the phonemes ('table')
+func NewVisemes(cfg *viper.Viper) (*Visemes, error) {
+	res := &Visemes{table: map[string]string{}, def: "@"}
+	res.add(defaultVisemes)
+	if cfg == nil {
+		return res, nil
+	}
+	if d := cfg.GetString("default"); d != "" {
+		res.def = d
+	}
+	var groups []VisemeGroup
+	if err := cfg.UnmarshalKey("table", &groups); err != nil {
+		return nil, fmt.Errorf("read viseme table: %w", err)
+	}
+	for _, g := range groups {
+		if g.Viseme == "" {
+			return nil, fmt.Errorf("no viseme for %v", g.Phonemes)
+		}
+	}
+	res.add(groups)
+	return res, nil
+}
+
+func (v *Visemes) add(groups []VisemeGroup) {
+	for _, g := range groups {
+		for _, p := range g.Phonemes {
+			v.table[p] = g.Viseme
+		}
+	}
+}
+
+// Get returns the viseme for the transcription symbol.
+// It drops the accent, length and palatalization marks if there is no exact match
+func (v *Visemes) Get(phoneme string) string {
+	if res, ok := v.table[phoneme]; ok {
+		return res
+	}
+	p := strings.NewReplacer("\"", "", "^", "", "`", "").Replace(phoneme)
+	if res, ok := v.table[p]; ok {
+		return res
+	}
+	p = strings.NewReplacer(":", "", "'", "").Replace(p)
+	if res, ok := v.table[p]; ok {
+		return res
+	}
+	if p != "" {
+		if res, ok := v.table[p[:1]]; ok {
+			return res
+		}
+	}
+	return v.def
+}
@@ -0,0 +1,79 @@
+package synthesizer
+
+import (
+	"testing"
+
+	"github.com/airenas/tts-line/internal/pkg/test"
+	"github.com/airenas/tts-line/pkg/ssml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisemes_Get(t *testing.T) {
+	v, err := NewVisemes(nil)
+	require.Nil(t, err)
+	tests := []struct {
+		phoneme string
+		want    string
+	}{
+		{phoneme: "p", want: "p"},
+		{phoneme: "m'", want: "p"},
+		{phoneme: "\"a:", want: "a"},
+		{phoneme: "^o", want: "o"},
+		{phoneme: "E", want: "E"},
+		{phoneme: "\"O:", want: "o"},
+		{phoneme: "iO", want: "o"},
+		{phoneme: "Eu", want: "e"},
+		{phoneme: "e", want: "e"},
+		{phoneme: "\"ie", want: "i"},
+		{phoneme: "ai", want: "a"},
+		{phoneme: "S'", want: "S"},
+		{phoneme: "sil", want: VisemeSilence},
+		{phoneme: "sp", want: VisemeSilence},
+		{phoneme: "#", want: "@"},
+		{phoneme: "", want: "@"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phoneme, func(t *testing.T) {
+			assert.Equal(t, tt.want, v.Get(tt.phoneme))
+		})
+	}
+}
+
+func TestVisemes_AllPhonemes(t *testing.T) {
+	v, err := NewVisemes(nil)
+	require.Nil(t, err)
+	for _, p := range ssml.Phonemes() {
+		t.Run(p, func(t *testing.T) {
+			res, ok := v.table[p]
+			assert.True(t, ok)
+			assert.NotEqual(t, v.def, res)
+			assert.NotEqual(t, VisemeSilence, res)
+		})
+	}
+}
+
+func TestNewVisemes_Config(t *testing.T) {
+	v, err := NewVisemes(test.NewConfig(t, `
+default: X
+table:
+  - viseme: O
+    phonemes: [o, "\"o:"]
+  - viseme: sil
+    phonemes: ["_"]
+`))
+	require.Nil(t, err)
+	assert.Equal(t, "O", v.Get("o"))
+	assert.Equal(t, "O", v.Get("\"o:"))
+	assert.Equal(t, "O", v.Get("^o"))
+	assert.Equal(t, VisemeSilence, v.Get("_"))
+	assert.Equal(t, "p", v.Get("b"))
+	assert.Equal(t, "X", v.Get("#"))
+}
+
+func TestNewVisemes_Fail(t *testing.T) {
+	_, err := NewVisemes(test.NewConfig(t, "table:\n  - phonemes: [o]\n"))
+	assert.NotNil(t, err)
+	_, err = NewVisemes(test.NewConfig(t, "table: olia\n"))
+	assert.NotNil(t, err)
+}
@@ -70,3 +70,17 @@ func NewErrBadSymbols(word, cleaned string) *ErrBadSymbols {
 func (r *ErrBadSymbols) Error() string {
 	return fmt.Sprintf("wrong symbols: '%s' (%s)", r.Orig, r.Cleaned)
 }
+
+// ErrBadAudio indicates an <audio> clip that is not available or has a wrong format
+type ErrBadAudio struct {
+	Key, Msg string
+}
+
+// NewErrBadAudio creates new error
+func NewErrBadAudio(key, msg string) *ErrBadAudio {
+	return &ErrBadAudio{Key: key, Msg: msg}
+}
+
+func (r *ErrBadAudio) Error() string {
+	return fmt.Sprintf("wrong audio '%s': %s", r.Key, r.Msg)
+}
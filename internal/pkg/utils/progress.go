@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Progress keeps count of scheduled and finished synthesis parts
+type Progress struct {
+	total, done atomic.Int64
+}
+
+type progressKey struct{}
+
+// WithProgress adds progress tracker to the context
+func WithProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// ProgressFromContext returns progress tracker from the context or nil
+func ProgressFromContext(ctx context.Context) *Progress {
+	res, _ := ctx.Value(progressKey{}).(*Progress)
+	return res
+}
+
+// AddTotal increases the count of scheduled parts
+func (p *Progress) AddTotal(n int) {
+	if p != nil {
+		p.total.Add(int64(n))
+	}
+}
+
+// AddDone increases the count of finished parts
+func (p *Progress) AddDone(n int) {
+	if p != nil {
+		p.done.Add(int64(n))
+	}
+}
+
+// Value returns finished part in range [0, 1]
+func (p *Progress) Value() float64 {
+	if p == nil {
+		return 0
+	}
+	total, done := p.total.Load(), p.done.Load()
+	if total <= 0 {
+		return 0
+	}
+	if done >= total {
+		return 1
+	}
+	return float64(done) / float64(total)
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress_Value(t *testing.T) {
+	p := &Progress{}
+	assert.Equal(t, 0.0, p.Value())
+	p.AddTotal(4)
+	assert.Equal(t, 0.0, p.Value())
+	p.AddDone(1)
+	assert.Equal(t, 0.25, p.Value())
+	p.AddDone(5)
+	assert.Equal(t, 1.0, p.Value())
+}
+
+func TestProgress_Nil(t *testing.T) {
+	var p *Progress
+	p.AddTotal(1)
+	p.AddDone(1)
+	assert.Equal(t, 0.0, p.Value())
+}
+
+func TestProgress_Context(t *testing.T) {
+	assert.Nil(t, ProgressFromContext(context.TODO()))
+	p := &Progress{}
+	assert.Equal(t, p, ProgressFromContext(WithProgress(context.TODO(), p)))
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// SkippedStages keeps the names of the optional pipeline stages skipped because of errors
+type SkippedStages struct {
+	lock  sync.Mutex
+	names []string
+}
+
+type skippedStagesKey struct{}
+
+// WithSkippedStages adds skipped stages collector to the context
+func WithSkippedStages(ctx context.Context, s *SkippedStages) context.Context {
+	return context.WithValue(ctx, skippedStagesKey{}, s)
+}
+
+// SkippedStagesFromContext returns skipped stages collector from the context or nil
+func SkippedStagesFromContext(ctx context.Context) *SkippedStages {
+	res, _ := ctx.Value(skippedStagesKey{}).(*SkippedStages)
+	return res
+}
+
+// Add adds the stage once
+func (s *SkippedStages) Add(name string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, n := range s.names {
+		if n == name {
+			return
+		}
+	}
+	s.names = append(s.names, name)
+}
+
+// Names returns the skipped stages in the order of adding, nil if none
+func (s *SkippedStages) Names() []string {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return SlicesCopy(s.names)
+}
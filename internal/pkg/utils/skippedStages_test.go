@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkippedStages(t *testing.T) {
+	s := &SkippedStages{}
+	assert.Nil(t, s.Names())
+	s.Add("obscene")
+	s.Add("acronyms")
+	s.Add("obscene")
+	assert.Equal(t, []string{"obscene", "acronyms"}, s.Names())
+}
+
+func TestSkippedStages_Nil(t *testing.T) {
+	var s *SkippedStages
+	s.Add("obscene")
+	assert.Nil(t, s.Names())
+}
+
+func TestSkippedStages_Context(t *testing.T) {
+	assert.Nil(t, SkippedStagesFromContext(context.TODO()))
+	s := &SkippedStages{}
+	assert.Equal(t, s, SkippedStagesFromContext(WithSkippedStages(context.TODO(), s)))
+}
@@ -46,6 +46,12 @@ type InterpretAsType int
 const (
 	InterpretAsTypeUnset      InterpretAsType = iota // unset
 	InterpretAsTypeCharacters                        // characters
+	InterpretAsTypeCardinal                          // cardinal
+	InterpretAsTypeOrdinal                           // ordinal
+	InterpretAsTypeDigits                            // digits
+	InterpretAsTypeTelephone                         // telephone
+	InterpretAsTypeDate                              // date
+	InterpretAsTypeTime                              // time
 )
 
 type InterpretAsDetailType int
@@ -75,6 +81,18 @@ func IsPause(p Part) bool {
 	return res
 }
 
+// Mark represents <mark> directive
+type Mark struct {
+	Name       string
+	Start, End int // character offsets of the tag in the input
+}
+
+// Audio represents <audio> directive, Src is the key of the prerecorded clip
+type Audio struct {
+	Src        string
+	Start, End int // character offsets of the tag in the input
+}
+
 // TextPart represents some part of text
 type TextPart struct {
 	Text              string
@@ -84,4 +102,10 @@ type TextPart struct {
 	UserOEPal         string // long/short OE and palatalization model
 	InterpretAs       InterpretAsType
 	InterpretAsDetail InterpretAsDetailType
+	InterpretAsFormat string // <say-as>:format, e.g. 'dmy' for a date
+	Written           string // written text of <sub>, Text keeps the alias to synthesize
+	Transcription     string // transcription of <phoneme> in the transcriber's symbols
+	SentenceEnd       bool   // the text ends a sentence, set by </s>
+	Source            string // raw text as in the input, entities are not decoded
+	Start             int    // character offset of Source in the input
 }
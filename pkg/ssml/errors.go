@@ -2,12 +2,40 @@ package ssml
 
 import "fmt"
 
+// Stable codes of the SSML errors and warnings
+const (
+	// CodeXMLSyntax - the input is not a well-formed XML
+	CodeXMLSyntax = "XML_SYNTAX"
+	// CodeUnknownTag - the tag is not supported
+	CodeUnknownTag = "UNKNOWN_TAG"
+	// CodeTagNotAllowed - the tag is not allowed in the parent tag
+	CodeTagNotAllowed = "TAG_NOT_ALLOWED"
+	// CodeInvalidTag - wrong or missing attributes of the tag
+	CodeInvalidTag = "INVALID_TAG"
+	// CodeInvalidContent - wrong text or missing content of the tag
+	CodeInvalidContent = "INVALID_CONTENT"
+	// CodeNoSpeak - no closed <speak> root element
+	CodeNoSpeak = "NO_SPEAK"
+	// CodeTextTooLong - the text is longer than the service accepts
+	CodeTextTooLong = "TEXT_TOO_LONG"
+
+	// CodeMergedPauses - a warning of the consecutive pauses that are summed
+	CodeMergedPauses = "MERGED_PAUSES"
+	// CodeUnknownLanguage - a warning of the language that is not supported by the service
+	CodeUnknownLanguage = "UNKNOWN_LANGUAGE"
+	// CodeNestedEmphasis - a warning of <emphasis> inside other <emphasis>
+	CodeNestedEmphasis = "NESTED_EMPHASIS"
+)
+
 // ErrParse indicates an SSML parse error
 type ErrParse struct {
-	Pos int64
-	Msg string
+	Pos          int64
+	Msg          string
+	Line, Column int    // 1-based start of the token
+	Tag          string // the tag of the error, may be empty
+	Code         string
 }
 
 func (e *ErrParse) Error() string {
-	return fmt.Sprintf("ssml: %d: %s", e.Pos, e.Msg)
+	return fmt.Sprintf("ssml: %d:%d: %s", e.Line, e.Column, e.Msg)
 }
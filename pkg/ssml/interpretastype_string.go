@@ -10,11 +10,17 @@ func _() {
 	var x [1]struct{}
 	_ = x[InterpretAsTypeUnset-0]
 	_ = x[InterpretAsTypeCharacters-1]
+	_ = x[InterpretAsTypeCardinal-2]
+	_ = x[InterpretAsTypeOrdinal-3]
+	_ = x[InterpretAsTypeDigits-4]
+	_ = x[InterpretAsTypeTelephone-5]
+	_ = x[InterpretAsTypeDate-6]
+	_ = x[InterpretAsTypeTime-7]
 }
 
-const _InterpretAsType_name = "unsetcharacters"
+const _InterpretAsType_name = "unsetcharacterscardinalordinaldigitstelephonedatetime"
 
-var _InterpretAsType_index = [...]uint8{0, 5, 15}
+var _InterpretAsType_index = [...]uint8{0, 5, 15, 23, 30, 36, 45, 49, 53}
 
 func (i InterpretAsType) String() string {
 	if i < 0 || i >= InterpretAsType(len(_InterpretAsType_index)-1) {
@@ -1,14 +1,18 @@
 package ssml
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/airenas/tts-line/internal/pkg/accent"
+	"github.com/airenas/tts-line/internal/pkg/sayas"
 	"github.com/airenas/tts-line/internal/pkg/utils"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/text/language"
@@ -56,15 +60,34 @@ type wrkData struct {
 	langFunc  func(string) (string, error)
 
 	lastWAcc, lastWSyll, lastWUser string
+	lastSubAlias                   string
+	lastTranscription              string
 
 	lastInterpretAs       InterpretAsType
 	lastInterpretAsDetail InterpretAsDetailType
+	lastInterpretAsFormat string
 
 	lastText *Text
+	sAt      int // index in res where the current <s> starts
 
 	res []Part
 	// cValues []*Text
 	emphasisCount int
+
+	input      *bytes.Buffer // read input, is used to calculate the offsets
+	from, to   int           // byte offsets of the current token
+	offsetByte int
+	offsetRune int
+}
+
+// runeOffset converts the byte offset of the input into the character offset
+func (w *wrkData) runeOffset(offset int) int {
+	if offset < w.offsetByte {
+		w.offsetByte, w.offsetRune = 0, 0
+	}
+	w.offsetRune += utf8.RuneCount(w.input.Bytes()[w.offsetByte:offset])
+	w.offsetByte = offset
+	return w.offsetRune
 }
 
 func (w *wrkData) validateNew(key string) error {
@@ -116,6 +139,11 @@ const (
 	TagLang     = "lang"
 	TagEmphasis = "emphasis"
 	TagSayAs    = "say-as"
+	TagMark     = "mark"
+	TagSub      = "sub"
+	TagPhoneme  = "phoneme"
+	TagAudio    = "audio"
+	TagS        = "s"
 )
 
 func init() {
@@ -131,33 +159,53 @@ func init() {
 	endFunctions[TagP] = endP
 	allowedInside[TagP] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
 
+	startFunctions[TagS] = startS
+	endFunctions[TagS] = endS
+	allowedInside[TagS] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+
 	startFunctions[TagBreak] = startBreak
 	endFunctions[TagBreak] = endBreak
-	allowedInside[TagBreak] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagBreak] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagVoice] = startVoice
 	endFunctions[TagVoice] = endVoice
-	allowedInside[TagVoice] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagVoice] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagProsody] = startProsody
 	endFunctions[TagProsody] = endProsody
-	allowedInside[TagProsody] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagProsody] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagWord] = startWord
 	endFunctions[TagWord] = endWord
-	allowedInside[TagWord] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagWord] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagLang] = startLang
 	endFunctions[TagLang] = endLang
-	allowedInside[TagLang] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagLang] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagEmphasis] = startEmphasis
 	endFunctions[TagEmphasis] = endEmphasis
-	allowedInside[TagEmphasis] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagEmphasis] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	startFunctions[TagSayAs] = startSayAs
 	endFunctions[TagSayAs] = endSayAs
-	allowedInside[TagSayAs] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang)
+	allowedInside[TagSayAs] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
+
+	startFunctions[TagMark] = startMark
+	endFunctions[TagMark] = endMark
+	allowedInside[TagMark] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
+
+	startFunctions[TagSub] = startSub
+	endFunctions[TagSub] = endSub
+	allowedInside[TagSub] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
+
+	startFunctions[TagAudio] = startAudio
+	endFunctions[TagAudio] = endAudio
+	allowedInside[TagAudio] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
+
+	startFunctions[TagPhoneme] = startPhoneme
+	endFunctions[TagPhoneme] = endPhoneme
+	allowedInside[TagPhoneme] = makeTagMap(TagSpeak, TagEmphasis, TagProsody, TagVoice, TagP, TagLang, TagS)
 
 	durationStrs = map[string]time.Duration{"none": 0, "x-weak": 250 * time.Millisecond,
 		"weak": 500 * time.Millisecond, "medium": 750 * time.Millisecond,
@@ -169,7 +217,11 @@ func init() {
 	pitchStrs = map[string]float64{"x-low": 0.55, "low": 0.8, "medium": 1, "high": 1.2, "x-high": 1.45, "default": 1}
 	emphasisLevels = map[string]EmphasisType{"reduced": EmphasisTypeReduced, "none": EmphasisTypeNone,
 		"moderate": EmphasisTypeModerate, "strong": EmphasisTypeStrong}
-	interpretAsTypes = map[string]InterpretAsType{InterpretAsTypeCharacters.String(): InterpretAsTypeCharacters}
+	interpretAsTypes = map[string]InterpretAsType{}
+	for _, t := range []InterpretAsType{InterpretAsTypeCharacters, InterpretAsTypeCardinal, InterpretAsTypeOrdinal,
+		InterpretAsTypeDigits, InterpretAsTypeTelephone, InterpretAsTypeDate, InterpretAsTypeTime} {
+		interpretAsTypes[t.String()] = t
+	}
 	interpretAsDetailTypes = map[string]InterpretAsDetailType{InterpretAsDetailTypeReadSymbols.String(): InterpretAsDetailTypeReadSymbols}
 }
 
@@ -183,68 +235,106 @@ func makeTagMap(s ...string) map[string]struct{} {
 
 // Parse parses xml into synthesis structure
 func Parse(r io.Reader, def *Text, voiceFunc func(string) (string, error)) ([]Part, error) {
+	return parse(r, def, voiceFunc, nil)
+}
+
+// parse runs the parser, it stops at the first error if v is nil,
+// otherwise the errors and warnings are collected into v and parsing continues while XML is well-formed
+func parse(r io.Reader, def *Text, voiceFunc func(string) (string, error), v *validation) ([]Part, error) {
 	wrk := &wrkData{res: make([]Part, 0),
 		//cValues: []*Text{def},
 		voiceFunc: voiceFunc,
 		langFunc:  checkLanguage,
+		input:     &bytes.Buffer{},
 	}
 	wrk.voices.push(def.Voice)
 
-	d := xml.NewDecoder(r)
+	d := xml.NewDecoder(io.TeeReader(r, wrk.input))
+	var line, column int
+	newErr := func(code, tag, msg string) *ErrParse {
+		return &ErrParse{Pos: d.InputOffset(), Msg: msg, Line: line, Column: column, Tag: tag, Code: code}
+	}
 
 	for {
 		// Read tokens from the XML document in a stream.
+		wrk.from = int(d.InputOffset())
+		line, column = d.InputPos()
 		t, err := d.Token()
+		wrk.to = int(d.InputOffset())
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("ssml: %v", err)
+			if v == nil {
+				return nil, fmt.Errorf("ssml: %v", err)
+			}
+			line, column = d.InputPos()
+			v.addError(newErr(CodeXMLSyntax, wrk.getLastTag(), err.Error()))
+			return wrk.res, nil
 		}
 		if t == nil {
 			break
 		}
+		var pErr *ErrParse
+		partsBefore, tag := len(wrk.res), wrk.getLastTag()
 		// Inspect the type of the token just read.
 		switch se := t.(type) {
 		case xml.StartElement:
 			key := getXMLKey(se.Name)
+			tag = key
 			f, ok := startFunctions[key]
 			if !ok {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: fmt.Sprintf("unknown tag <%s>", key)}
+				pErr = newErr(CodeUnknownTag, key, fmt.Sprintf("unknown tag <%s>", key))
+			} else if err := wrk.validateNew(key); err != nil {
+				pErr = newErr(CodeTagNotAllowed, key, err.Error())
+			} else if err := f(se, wrk); err != nil {
+				pErr = newErr(CodeInvalidTag, key, err.Error())
 			}
-			if err := wrk.validateNew(key); err != nil {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: err.Error()}
-			}
-			if err := f(se, wrk); err != nil {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: err.Error()}
+			if pErr != nil && v != nil {
+				v.failed[len(wrk.lastTag)] = true
+			} else if v != nil {
+				v.checkStart(key, wrk, line, column)
 			}
 			wrk.lastTag = append(wrk.lastTag, key)
 		case xml.EndElement:
 			key := getXMLKey(se.Name)
-			f, ok := endFunctions[key]
-			if !ok {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: fmt.Sprintf("unknown tag </%s>", key)}
-			}
-			if err := f(se, wrk); err != nil {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: err.Error()}
-			}
+			tag = key
 			l := len(wrk.lastTag) - 1
+			if v != nil && v.failed[l] {
+				delete(v.failed, l)
+			} else if f, ok := endFunctions[key]; !ok {
+				pErr = newErr(CodeUnknownTag, key, fmt.Sprintf("unknown tag </%s>", key))
+			} else if err := f(se, wrk); err != nil {
+				pErr = newErr(CodeInvalidContent, key, err.Error())
+			}
 			wrk.lastTag[l] = ""
 			wrk.lastTag = wrk.lastTag[:l]
 		case xml.CharData:
-			err := makeTextPart(se, wrk)
-			if err != nil {
-				return nil, &ErrParse{Pos: d.InputOffset(), Msg: err.Error()}
+			if err := makeTextPart(se, wrk); err != nil {
+				pErr = newErr(CodeInvalidContent, wrk.getLastTag(), err.Error())
 			}
 		case xml.Comment:
 		case xml.ProcInst:
 		case xml.Directive:
 		default:
-			return nil, &ErrParse{Pos: d.InputOffset(), Msg: fmt.Sprintf("unknown element %v", se)}
+			pErr = newErr(CodeXMLSyntax, wrk.getLastTag(), fmt.Sprintf("unknown element %v", se))
+		}
+		if pErr != nil {
+			if v == nil {
+				return nil, pErr
+			}
+			v.addError(pErr)
+		} else if v != nil {
+			v.checkParts(wrk.res, partsBefore, tag, line, column)
 		}
 	}
 	if wrk.speakTagEndCount != 1 {
-		return nil, &ErrParse{Pos: d.InputOffset(), Msg: "no </speak>"}
+		line, column = d.InputPos()
+		pErr := newErr(CodeNoSpeak, "", "no </speak>")
+		if v == nil {
+			return nil, pErr
+		}
+		v.addError(pErr)
 	}
 	return wrk.res, nil
 }
@@ -274,15 +364,38 @@ func makeTextPart(se xml.CharData, wrk *wrkData) error {
 		if lt == TagBreak {
 			return fmt.Errorf("data in <break>")
 		}
-		tp := TextPart{Text: s, Language: wrk.languages.peek()}
+		if lt == TagMark {
+			return fmt.Errorf("data in <mark>")
+		}
+		if lt == TagAudio {
+			return fmt.Errorf("data in <audio>")
+		}
+		raw := string(wrk.input.Bytes()[wrk.from:wrk.to])
+		lead := len(raw) - len(strings.TrimLeftFunc(raw, unicode.IsSpace))
+		tp := TextPart{Text: s, Language: wrk.languages.peek(),
+			Source: strings.TrimSpace(raw), Start: wrk.runeOffset(wrk.from + lead)}
 		if wrk.lastWAcc != "" {
 			tp.Accented = wrk.lastWAcc
 			wrk.lastWAcc = ""
 		}
+		if wrk.lastSubAlias != "" {
+			tp.Text, tp.Written = wrk.lastSubAlias, s
+			wrk.lastSubAlias = ""
+		}
+		if wrk.lastTranscription != "" {
+			tp.Transcription = wrk.lastTranscription
+			wrk.lastTranscription = ""
+		}
 		tp.Syllables = wrk.lastWSyll
 		tp.UserOEPal = wrk.lastWUser
 		tp.InterpretAs = wrk.lastInterpretAs
 		tp.InterpretAsDetail = wrk.lastInterpretAsDetail
+		tp.InterpretAsFormat = wrk.lastInterpretAsFormat
+		if sayas.IsSupported(tp.InterpretAs.String()) {
+			if _, err := sayas.Say(tp.InterpretAs.String(), tp.InterpretAsFormat, s); err != nil {
+				return fmt.Errorf("<say-as>: %w", err)
+			}
+		}
 		if wrk.lastText != nil {
 			wrk.lastText.Texts = append(wrk.lastText.Texts, tp)
 		} else {
@@ -339,6 +452,30 @@ func endP(se xml.EndElement, wrk *wrkData) error {
 	return nil
 }
 
+func startS(se xml.StartElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no <speak>")
+	}
+	wrk.lastText = nil
+	wrk.sAt = len(wrk.res)
+	return nil
+}
+
+// endS marks the last text of the sentence as the sentence end
+func endS(se xml.EndElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no </speak>")
+	}
+	for i := len(wrk.res) - 1; i >= wrk.sAt; i-- {
+		if t, ok := wrk.res[i].(*Text); ok && len(t.Texts) > 0 {
+			t.Texts[len(t.Texts)-1].SentenceEnd = true
+			break
+		}
+	}
+	wrk.lastText = nil
+	return nil
+}
+
 func startBreak(se xml.StartElement, wrk *wrkData) error {
 	if wrk.speakTagCount != 1 {
 		return fmt.Errorf("no <speak>")
@@ -386,6 +523,94 @@ func endBreak(se xml.EndElement, wrk *wrkData) error {
 	return nil
 }
 
+func startMark(se xml.StartElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no <speak>")
+	}
+	name := getAttr(se, "name")
+	if name == "" {
+		return fmt.Errorf("no <mark>:name")
+	}
+	wrk.lastText = nil
+	wrk.res = append(wrk.res, &Mark{Name: name, Start: wrk.runeOffset(wrk.from), End: wrk.runeOffset(wrk.to)})
+	return nil
+}
+
+func endMark(se xml.EndElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no </speak>")
+	}
+	return nil
+}
+
+func startAudio(se xml.StartElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no <speak>")
+	}
+	src := strings.TrimSpace(getAttr(se, "src"))
+	if src == "" {
+		return fmt.Errorf("no <audio>:src")
+	}
+	wrk.lastText = nil
+	wrk.res = append(wrk.res, &Audio{Src: src, Start: wrk.runeOffset(wrk.from), End: wrk.runeOffset(wrk.to)})
+	return nil
+}
+
+func endAudio(se xml.EndElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no </speak>")
+	}
+	return nil
+}
+
+func startSub(se xml.StartElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no <speak>")
+	}
+	alias := strings.TrimSpace(getAttr(se, "alias"))
+	if alias == "" {
+		return fmt.Errorf("no <sub>:alias")
+	}
+	wrk.lastSubAlias = alias
+	return nil
+}
+
+func endSub(se xml.EndElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no </speak>")
+	}
+	if wrk.lastSubAlias != "" {
+		return fmt.Errorf("no text in <sub>")
+	}
+	return nil
+}
+
+func startPhoneme(se xml.StartElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no <speak>")
+	}
+	alphabet := getAttr(se, "alphabet")
+	if alphabet == "" {
+		return fmt.Errorf("no <phoneme>:alphabet")
+	}
+	tr, err := makeTranscription(alphabet, getAttr(se, "ph"))
+	if err != nil {
+		return err
+	}
+	wrk.lastTranscription = tr
+	return nil
+}
+
+func endPhoneme(se xml.EndElement, wrk *wrkData) error {
+	if wrk.speakTagCount != 1 {
+		return fmt.Errorf("no </speak>")
+	}
+	if wrk.lastTranscription != "" {
+		return fmt.Errorf("no text in <phoneme>")
+	}
+	return nil
+}
+
 func startVoice(se xml.StartElement, wrk *wrkData) error {
 	if wrk.speakTagCount != 1 {
 		return fmt.Errorf("no <speak>")
@@ -479,12 +704,8 @@ func startWord(se xml.StartElement, wrk *wrkData) error {
 	if wrk.speakTagCount != 1 {
 		return fmt.Errorf("no <speak>")
 	}
-	lt := wrk.lastTag[len(wrk.lastTag)-1]
-	if lt != "speak" && lt != "p" && lt != "prosody" && lt != "voice" {
-		return fmt.Errorf("<intelektika:w> not allowed inside <%s>", lt)
-	}
 	a := getAttr(se, "acc")
-	if !okAccentedWord(a) {
+	if !IsAccentedWord(a) {
 		return fmt.Errorf("wrong <intelektika:w>:acc='%s'", a)
 	}
 	wrk.lastWAcc = a
@@ -581,14 +802,20 @@ func startSayAs(se xml.StartElement, wrk *wrkData) error {
 		log.Warn().Str("value", detailStr).Msg("Unknown value in <say-as>:detail")
 		return fmt.Errorf("wrong <say-as>:detail='%s'", detailStr)
 	}
+	format := getAttr(se, "format")
+	if err := sayas.CheckFormat(interpretAsStr, format); err != nil {
+		return fmt.Errorf("wrong <say-as>:format: %w", err)
+	}
 	wrk.lastInterpretAs = interpretAs
 	wrk.lastInterpretAsDetail = detail
+	wrk.lastInterpretAsFormat = format
 	return nil
 }
 
 func endSayAs(se xml.EndElement, wrk *wrkData) error {
 	wrk.lastInterpretAs = InterpretAsTypeUnset
 	wrk.lastInterpretAsDetail = InterpretAsDetailTypeUnset
+	wrk.lastInterpretAsFormat = ""
 	return nil
 }
 
@@ -601,8 +828,10 @@ func clearSylls(s string) string {
 	return strings.ReplaceAll(s, "-", "")
 }
 
-func okAccentedWord(a string) bool {
-	return a != "" && len(a) < 50 && accent.IsWordOrWithAccent(a)
+// IsAccentedWord checks if a is accepted as <intelektika:w> acc value,
+// the length limit is the same as the accenter's and does not count the accent marks
+func IsAccentedWord(a string) bool {
+	return a != "" && len(accent.ClearAccents(a)) < 50 && accent.IsWordOrWithAccent(a)
 }
 
 func getSpeed(str string) (float64, error) {
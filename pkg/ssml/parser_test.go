@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestParse(t *testing.T) {
@@ -58,6 +59,58 @@ func TestParse(t *testing.T) {
 		}, wantErr: false},
 		{name: "<break> with text", xml: `<speak><break strength="x-weak">olia</break></speak>`,
 			want: []Part{}, wantErr: true},
+		{name: "<mark>", xml: `<speak>olia <mark name="m1"/>olia2<mark name="m2"></mark></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia"}}},
+			&Mark{Name: "m1"},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia2"}}},
+			&Mark{Name: "m2"},
+		}, wantErr: false},
+		{name: "<mark> no name", xml: `<speak><mark/></speak>`, want: []Part{}, wantErr: true},
+		{name: "<mark> with text", xml: `<speak><mark name="m1">olia</mark></speak>`, want: []Part{}, wantErr: true},
+		{name: "<mark> inside <intelektika:w>", xml: `<speak xmlns:intelektika="urn:intelektika"><intelektika:w acc="olia"><mark name="m1"/>olia</intelektika:w></speak>`,
+			want: []Part{}, wantErr: true},
+		{name: "<sub>", xml: `<speak>Valstybė <sub alias=" Jungtinės Amerikos Valstijos ">JAV</sub> yra</speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Valstybė"}, {Text: "Jungtinės Amerikos Valstijos", Written: "JAV"},
+				{Text: "yra"}}},
+		}, wantErr: false},
+		{name: "<sub> no alias", xml: `<speak><sub>JAV</sub></speak>`, want: []Part{}, wantErr: true},
+		{name: "<sub> no text", xml: `<speak><sub alias="olia"></sub></speak>`, want: []Part{}, wantErr: true},
+		{name: "<sub> with tag", xml: `<speak><sub alias="olia"><break time="1s"/>JAV</sub></speak>`, want: []Part{}, wantErr: true},
+		{name: "<audio>", xml: `<speak>olia <audio src=" jingle.wav "/>olia2<audio src="end"></audio></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia"}}},
+			&Audio{Src: "jingle.wav"},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia2"}}},
+			&Audio{Src: "end"},
+		}, wantErr: false},
+		{name: "<audio> no src", xml: `<speak><audio/></speak>`, want: []Part{}, wantErr: true},
+		{name: "<audio> with text", xml: `<speak><audio src="a">olia</audio></speak>`, want: []Part{}, wantErr: true},
+		{name: "<s>", xml: `<speak><s>Dėkoju prof.</s><s>Jonas <emphasis>ačiū</emphasis></s> olia</speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Dėkoju prof.", SentenceEnd: true}}},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Jonas"}}},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "ačiū", SentenceEnd: true}}, Prosodies: []*Prosody{{Rate: 1, Emphasis: EmphasisTypeModerate, ID: 1}}},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia"}}},
+		}, wantErr: false},
+		{name: "<s> ends with <break>", xml: `<speak><p><s>olia<break time="1s"/></s></p></speak>`, want: []Part{
+			&Pause{Duration: time.Millisecond * 1250},
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia", SentenceEnd: true}}},
+			&Pause{Duration: time.Second, IsBreak: true},
+		}, wantErr: false},
+		{name: "<s> empty", xml: `<speak>olia<s></s></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "olia"}}},
+		}, wantErr: false},
+		{name: "<s> in <s>", xml: `<speak><s><s>olia</s></s></speak>`, want: []Part{}, wantErr: true},
+		{name: "<p> in <s>", xml: `<speak><s><p>olia</p></s></speak>`, want: []Part{}, wantErr: true},
+		{name: "<phoneme>", xml: `<speak>Miestas <phoneme alphabet="x-intelektika" ph="p a - r &quot;i: s">Paris</phoneme></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Miestas"}, {Text: "Paris", Transcription: "p a - r \"i: s"}}},
+		}, wantErr: false},
+		{name: "<phoneme> ipa", xml: `<speak><phoneme alphabet="ipa" ph="pɐˈrʲiːs">Paris</phoneme></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Paris", Transcription: "p a r' \"i: s"}}},
+		}, wantErr: false},
+		{name: "<phoneme> no alphabet", xml: `<speak><phoneme ph="a">a</phoneme></speak>`, want: []Part{}, wantErr: true},
+		{name: "<phoneme> wrong alphabet", xml: `<speak><phoneme alphabet="x-sampa" ph="a">a</phoneme></speak>`, want: []Part{}, wantErr: true},
+		{name: "<phoneme> no ph", xml: `<speak><phoneme alphabet="ipa">a</phoneme></speak>`, want: []Part{}, wantErr: true},
+		{name: "<phoneme> wrong symbol", xml: `<speak><phoneme alphabet="x-intelektika" ph="a q">a</phoneme></speak>`, want: []Part{}, wantErr: true},
+		{name: "<phoneme> no text", xml: `<speak><phoneme alphabet="ipa" ph="a"></phoneme></speak>`, want: []Part{}, wantErr: true},
 		{name: "<voice> strength", xml: `<speak><voice name="ooo">aaa</voice></speak>`,
 			want: []Part{
 				&Text{Voice: "ooo", Texts: []TextPart{{Text: "aaa"}}},
@@ -102,6 +155,21 @@ func TestParse(t *testing.T) {
 				{Language: "en", Text: "ok?"},
 			}}},
 			wantErr: false},
+		{name: "say-as date", xml: `<speak><say-as interpret-as="date" format="dmy">15.03.2024</say-as></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "15.03.2024", InterpretAs: InterpretAsTypeDate, InterpretAsFormat: "dmy"}}}},
+			wantErr: false},
+		{name: "say-as telephone", xml: `<speak>Skambinkite <say-as interpret-as="telephone">+370 612 34567</say-as></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "Skambinkite"}, {Text: "+370 612 34567", InterpretAs: InterpretAsTypeTelephone}}}},
+			wantErr: false},
+		{name: "say-as types", xml: `<speak><say-as interpret-as="cardinal">12</say-as><say-as interpret-as="ordinal">12</say-as>` +
+			`<say-as interpret-as="digits">12</say-as><say-as interpret-as="time">12:30</say-as></speak>`, want: []Part{
+			&Text{Voice: "aa", Texts: []TextPart{{Text: "12", InterpretAs: InterpretAsTypeCardinal}, {Text: "12", InterpretAs: InterpretAsTypeOrdinal},
+				{Text: "12", InterpretAs: InterpretAsTypeDigits}, {Text: "12:30", InterpretAs: InterpretAsTypeTime}}}},
+			wantErr: false},
+		{name: "say-as wrong date", xml: `<speak><say-as interpret-as="date" format="ymd">2024-13-01</say-as></speak>`, want: nil, wantErr: true},
+		{name: "say-as wrong date format", xml: `<speak><say-as interpret-as="date" format="yyy">2024</say-as></speak>`, want: nil, wantErr: true},
+		{name: "say-as format not for cardinal", xml: `<speak><say-as interpret-as="cardinal" format="ymd">2024</say-as></speak>`, want: nil, wantErr: true},
+		{name: "say-as wrong cardinal", xml: `<speak><say-as interpret-as="cardinal">12a</say-as></speak>`, want: nil, wantErr: true},
 		//////////////////////////////////////////////////////////////////////////////////////////
 		/// emphasis tests
 		//////////////////////////////////////////////////////////////////////////////////////////
@@ -256,6 +324,23 @@ func TestParse(t *testing.T) {
 			want: []Part{
 				&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li"}}},
 			}, wantErr: false},
+		{name: "<w> in <emphasis>", xml: `<speak><emphasis><intelektika:w acc="g{a/}li">gali</intelektika:w></emphasis></speak>`,
+			want: []Part{
+				&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li"}},
+					Prosodies: []*Prosody{{Rate: 1, Emphasis: EmphasisTypeModerate, ID: 1}}},
+			}, wantErr: false},
+		{name: "<w> in <lang>", xml: `<speak><lang xml:lang="en"><intelektika:w acc="g{a/}li">gali</intelektika:w></lang></speak>`,
+			want: []Part{
+				&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li", Language: "en"}}},
+			}, wantErr: false},
+		{name: "<w> in <s>", xml: `<speak><s><intelektika:w acc="g{a/}li">gali</intelektika:w></s></speak>`,
+			want: []Part{
+				&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li", SentenceEnd: true}}},
+			}, wantErr: false},
+		{name: "<w> in <say-as> fails", xml: `<speak><say-as interpret-as="characters"><intelektika:w acc="g{a/}li">gali</intelektika:w></say-as></speak>`,
+			wantErr: true},
+		{name: "<w> in <w> fails", xml: `<speak><intelektika:w acc="g{a/}li"><intelektika:w acc="g{a/}li">gali</intelektika:w></intelektika:w></speak>`,
+			wantErr: true},
 		{name: "<w> with namespace", xml: `<speak xmlns:intelektika="urn:intelektika"><intelektika:w acc="g{a/}li">gali</intelektika:w></speak>`,
 			want: []Part{
 				&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li"}}},
@@ -339,7 +424,7 @@ func TestParse(t *testing.T) {
 				return
 			}
 			for i := range got {
-				if diff := cmp.Diff(tt.want[i], got[i]); diff != "" {
+				if diff := cmp.Diff(tt.want[i], got[i], ignoreOffsets); diff != "" {
 					t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
 				}
 			}
@@ -347,6 +432,25 @@ func TestParse(t *testing.T) {
 	}
 }
 
+var ignoreOffsets = cmp.Options{cmpopts.IgnoreFields(TextPart{}, "Source", "Start"),
+	cmpopts.IgnoreFields(Mark{}, "Start", "End"), cmpopts.IgnoreFields(Audio{}, "Start", "End")}
+
+func TestParse_Offsets(t *testing.T) {
+	xml := "<speak>\n  Ąžuolas &amp; <mark name=\"m1\"/><intelektika:w acc=\"g{a/}li\">gali</intelektika:w>  </speak>"
+	got, err := Parse(strings.NewReader(xml), &Text{Voice: "aa"}, func(s string) (string, error) { return s, nil })
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Part{
+		&Text{Voice: "aa", Texts: []TextPart{{Text: "Ąžuolas &", Source: "Ąžuolas &amp;", Start: 10}}},
+		&Mark{Name: "m1", Start: 24, End: 41},
+		&Text{Voice: "aa", Texts: []TextPart{{Text: "gali", Accented: "g{a/}li", Source: "gali", Start: 70}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func Test_getDuration(t *testing.T) {
 	type args struct {
 		tm  string
@@ -427,7 +531,7 @@ func Test_getSpeed(t *testing.T) {
 	}
 }
 
-func Test_okAccentedWord(t *testing.T) {
+func TestIsAccentedWord(t *testing.T) {
 	tests := []struct {
 		name string
 		args string
@@ -438,12 +542,13 @@ func Test_okAccentedWord(t *testing.T) {
 		{name: "empty", args: " ", want: false},
 		{name: "empty", args: "", want: false},
 		{name: "long", args: strings.Repeat("a", 50), want: false},
+		{name: "long with acc", args: strings.Repeat("a", 48) + "{a/}", want: true},
 		{name: "wrong acc", args: "{a-}", want: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := okAccentedWord(tt.args); got != tt.want {
-				t.Errorf("okAccentedWord() = %v, want %v", got, tt.want)
+			if got := IsAccentedWord(tt.args); got != tt.want {
+				t.Errorf("IsAccentedWord() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,180 @@
+package ssml
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// AlphabetIPA is the International Phonetic Alphabet
+	AlphabetIPA = "ipa"
+	// AlphabetIntelektika is the symbol set of the transcriber
+	AlphabetIntelektika = "x-intelektika"
+)
+
+// phonemes is the symbol inventory of the transcriber without the accent, length and palatalization marks
+var phonemes = map[string]bool{
+	"p": true, "b": true, "m": true, "f": true, "v": true, "t": true, "d": true, "n": true, "l": true,
+	"s": true, "z": true, "ts": true, "dz": true, "c": true, "S": true, "Z": true, "tS": true, "dZ": true,
+	"k": true, "g": true, "x": true, "h": true, "N": true, "G": true, "r": true, "j": true, "w": true,
+	"a": true, "e": true, "E": true, "i": true, "I": true, "o": true, "O": true, "u": true, "U": true,
+	"ie": true, "uo": true, "iu": true, "io": true, "iO": true, "eu": true, "Eu": true,
+}
+
+// Phonemes returns the sorted symbol inventory of the transcriber without the accent, length and palatalization marks
+func Phonemes() []string {
+	return slices.Sorted(maps.Keys(phonemes))
+}
+
+const (
+	syllableSeparator = "-"
+	accentMarks       = "\"^`"
+	lengthMark        = ":"
+	palatalMark       = "'"
+)
+
+// ipaPhonemes maps IPA symbols to the transcriber's symbols
+var ipaPhonemes = map[string]string{
+	"p": "p", "b": "b", "m": "m", "f": "f", "v": "v", "ʋ": "v", "w": "w",
+	"t": "t", "d": "d", "n": "n", "l": "l", "ɫ": "l", "s": "s", "z": "z",
+	"ts": "ts", "t͡s": "ts", "ʦ": "ts", "dz": "dz", "d͡z": "dz", "ʣ": "dz",
+	"ʃ": "S", "ʒ": "Z", "tʃ": "tS", "t͡ʃ": "tS", "ʧ": "tS", "dʒ": "dZ", "d͡ʒ": "dZ", "ʤ": "dZ",
+	"c": "c", "k": "k", "g": "g", "ɡ": "g", "x": "x", "h": "h", "ɦ": "h", "ɣ": "G", "ŋ": "N",
+	"r": "r", "ɾ": "r", "j": "j",
+	"a": "a", "ɐ": "a", "ɑ": "a", "e": "e", "æ": "E", "ɛ": "E", "i": "i", "ɪ": "I",
+	"o": "o", "ɔ": "O", "u": "u", "ʊ": "U",
+	"ie": "ie", "iə": "ie", "iɛ": "ie", "uo": "uo", "uə": "uo", "uɔ": "uo",
+}
+
+var ipaMaxLen = maxKeyLen(ipaPhonemes)
+
+const (
+	ipaPrimaryStress   = 'ˈ'
+	ipaSecondaryStress = 'ˌ'
+	ipaLong            = 'ː'
+	ipaHalfLong        = 'ˑ'
+	ipaPalatalized     = 'ʲ'
+	ipaSyllableBreak   = '.'
+)
+
+// makeTranscription converts the <phoneme>:ph value into the transcriber's symbols separated by spaces
+func makeTranscription(alphabet, ph string) (string, error) {
+	switch alphabet {
+	case AlphabetIntelektika:
+		return checkTranscription(ph)
+	case AlphabetIPA:
+		return fromIPA(ph)
+	}
+	return "", fmt.Errorf("wrong <phoneme>:alphabet='%s'", alphabet)
+}
+
+func checkTranscription(ph string) (string, error) {
+	symbols := strings.Fields(ph)
+	if len(symbols) == 0 {
+		return "", fmt.Errorf("no <phoneme>:ph")
+	}
+	for _, s := range symbols {
+		if s == syllableSeparator {
+			continue
+		}
+		if !okSymbol(s) {
+			return "", fmt.Errorf("wrong <phoneme>:ph symbol '%s'", s)
+		}
+	}
+	if symbols[0] == syllableSeparator || symbols[len(symbols)-1] == syllableSeparator {
+		return "", fmt.Errorf("wrong <phoneme>:ph='%s', syllable separator at the edge", ph)
+	}
+	return strings.Join(symbols, " "), nil
+}
+
+// okSymbol checks the symbol: an optional accent, the phoneme, optional length and palatalization marks
+func okSymbol(s string) bool {
+	s = strings.TrimLeft(s, accentMarks)
+	s = strings.TrimSuffix(s, palatalMark)
+	s = strings.TrimSuffix(s, lengthMark)
+	return phonemes[s]
+}
+
+func fromIPA(ph string) (string, error) {
+	rns := []rune(strings.TrimSpace(ph))
+	if len(rns) == 0 {
+		return "", fmt.Errorf("no <phoneme>:ph")
+	}
+	var res []string
+	accent := false
+	for i := 0; i < len(rns); {
+		r := rns[i]
+		switch {
+		case unicode.IsSpace(r) || r == ipaSecondaryStress || r == ipaHalfLong:
+			i++
+			continue
+		case r == ipaPrimaryStress:
+			accent = true
+			i++
+			continue
+		case r == ipaSyllableBreak:
+			if len(res) > 0 && res[len(res)-1] != syllableSeparator {
+				res = append(res, syllableSeparator)
+			}
+			i++
+			continue
+		case r == ipaLong || r == ipaPalatalized:
+			if len(res) == 0 || res[len(res)-1] == syllableSeparator {
+				return "", fmt.Errorf("wrong <phoneme>:ph='%s', no symbol before '%c' at %d", ph, r, i)
+			}
+			mark := lengthMark
+			if r == ipaPalatalized {
+				mark = palatalMark
+			}
+			res[len(res)-1] += mark
+			i++
+			continue
+		}
+		s, l := matchIPA(rns[i:])
+		if l == 0 {
+			return "", fmt.Errorf("wrong <phoneme>:ph symbol '%c' at %d", r, i)
+		}
+		if accent && isVowel(s) {
+			s = "\"" + s
+			accent = false
+		}
+		res = append(res, s)
+		i += l
+	}
+	if len(res) > 0 && res[len(res)-1] == syllableSeparator {
+		res = res[:len(res)-1]
+	}
+	if len(res) == 0 {
+		return "", fmt.Errorf("no symbols in <phoneme>:ph='%s'", ph)
+	}
+	if accent {
+		return "", fmt.Errorf("wrong <phoneme>:ph='%s', no vowel after stress", ph)
+	}
+	return strings.Join(res, " "), nil
+}
+
+// matchIPA returns the longest IPA symbol at the start of rns and its length in runes
+func matchIPA(rns []rune) (string, int) {
+	for l := min(ipaMaxLen, len(rns)); l > 0; l-- {
+		if res, ok := ipaPhonemes[string(rns[:l])]; ok {
+			return res, l
+		}
+	}
+	return "", 0
+}
+
+func isVowel(s string) bool {
+	return strings.ContainsAny(s[:1], "aeEiIoOuU")
+}
+
+func maxKeyLen(m map[string]string) int {
+	res := 0
+	for k := range m {
+		res = max(res, utf8.RuneCountInString(k))
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package ssml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeTranscription(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+		ph       string
+		want     string
+		wantErr  bool
+	}{
+		{name: "intelektika", alphabet: "x-intelektika", ph: " v a  - o l' \"i a ", want: "v a - o l' \"i a"},
+		{name: "intelektika accents", alphabet: "x-intelektika", ph: "^o: `a tS dZ ie uo", want: "^o: `a tS dZ ie uo"},
+		{name: "intelektika wrong", alphabet: "x-intelektika", ph: "v a q", wantErr: true},
+		{name: "intelektika ipa symbol", alphabet: "x-intelektika", ph: "ʃ a", wantErr: true},
+		{name: "intelektika separator", alphabet: "x-intelektika", ph: "- a", wantErr: true},
+		{name: "intelektika empty", alphabet: "x-intelektika", ph: " ", wantErr: true},
+		{name: "ipa", alphabet: "ipa", ph: "ˈʃɛʃtas", want: "S \"E S t a s"},
+		{name: "ipa affricates", alphabet: "ipa", ph: "t͡ʃɛ dʒɪ ʦa", want: "tS E dZ I ts a"},
+		{name: "ipa diphthongs", alphabet: "ipa", ph: "lʲiɛ.ˈtuo", want: "l' ie - t \"uo"},
+		{name: "ipa length", alphabet: "ipa", ph: "ˈmɑːmɐ", want: "m \"a: m a"},
+		{name: "ipa ŋ ɡ", alphabet: "ipa", ph: "ˌbaŋɡa", want: "b a N g a"},
+		{name: "ipa syllables", alphabet: "ipa", ph: "a.ˈsɔ.", want: "a - s \"O"},
+		{name: "ipa wrong", alphabet: "ipa", ph: "aθa", wantErr: true},
+		{name: "ipa length first", alphabet: "ipa", ph: "ːa", wantErr: true},
+		{name: "ipa no vowel", alphabet: "ipa", ph: "aˈs", wantErr: true},
+		{name: "ipa empty", alphabet: "ipa", ph: "", wantErr: true},
+		{name: "unknown alphabet", alphabet: "x-sampa", ph: "a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := makeTranscription(tt.alphabet, tt.ph)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package ssml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Issue is an error or a warning found by Validate
+type Issue struct {
+	Line, Column int // 1-based position of the token in the input
+	Tag          string
+	Code         string
+	Msg          string
+}
+
+// ValidateResult holds all errors and warnings of the SSML
+type ValidateResult struct {
+	Errors   []*Issue
+	Warnings []*Issue
+	TextLen  int // characters of the texts to synthesize
+}
+
+// Validate parses xml and collects all errors and warnings.
+// The parsing continues after an error while XML is well-formed.
+// knownLanguage may be nil, then the languages are not checked
+func Validate(r io.Reader, def *Text, voiceFunc func(string) (string, error), knownLanguage func(string) bool) *ValidateResult {
+	v := &validation{failed: map[int]bool{}, knownLanguage: knownLanguage}
+	parts, _ := parse(r, def, voiceFunc, v)
+	for _, p := range parts {
+		if t, ok := p.(*Text); ok {
+			for _, tp := range t.Texts {
+				v.res.TextLen += utf8.RuneCountInString(strings.TrimSpace(tp.Text))
+			}
+		}
+	}
+	return &v.res
+}
+
+type validation struct {
+	res           ValidateResult
+	failed        map[int]bool // the depths of the failed start tags, their ends are not checked
+	knownLanguage func(string) bool
+}
+
+func (v *validation) addError(err *ErrParse) {
+	v.res.Errors = append(v.res.Errors, &Issue{Line: err.Line, Column: err.Column, Tag: err.Tag, Code: err.Code, Msg: err.Msg})
+}
+
+func (v *validation) addWarning(line, column int, tag, code, msg string) {
+	v.res.Warnings = append(v.res.Warnings, &Issue{Line: line, Column: column, Tag: tag, Code: code, Msg: msg})
+}
+
+// checkStart makes the warnings of the successfully started tag
+func (v *validation) checkStart(key string, wrk *wrkData, line, column int) {
+	switch key {
+	case TagSpeak, TagLang:
+		if lang := wrk.languages.peek(); lang != "" && v.knownLanguage != nil && !v.knownLanguage(lang) {
+			v.addWarning(line, column, key, CodeUnknownLanguage, fmt.Sprintf("language '%s' is not supported", lang))
+		}
+	case TagEmphasis:
+		c := 0
+		for _, p := range wrk.prosodies.values {
+			if p.Emphasis != EmphasisTypeUnset {
+				c++
+			}
+		}
+		if c > 1 {
+			v.addWarning(line, column, key, CodeNestedEmphasis, "nested <emphasis>, the emphasis effects are stacked")
+		}
+	}
+}
+
+// checkParts makes the warnings of the parts added by the last token
+func (v *validation) checkParts(parts []Part, from int, tag string, line, column int) {
+	for i := max(from, 1); i < len(parts); i++ {
+		p, ok := parts[i].(*Pause)
+		if !ok {
+			continue
+		}
+		if pp, ok := parts[i-1].(*Pause); ok && (p.IsBreak || pp.IsBreak) {
+			v.addWarning(line, column, tag, CodeMergedPauses,
+				fmt.Sprintf("the pause is merged with the previous one, the total is %v", p.Duration+pp.Duration))
+		}
+	}
+}
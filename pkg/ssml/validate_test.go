@@ -0,0 +1,77 @@
+package ssml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		xml          string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{name: "ok", xml: `<speak>olia <break time="1s"/> olia</speak>`},
+		{name: "several errors", xml: "<speak>\n  <break time=\"1x\"/>olia\n  <s><p>olia</p></s>\n  <olia>a</olia>\n</speak>",
+			wantErrors: []string{"2:3:break:INVALID_TAG", "3:6:p:TAG_NOT_ALLOWED", "4:3:olia:UNKNOWN_TAG"}},
+		{name: "content", xml: `<speak><sub alias="a"></sub><break time="1s">olia</break></speak>`,
+			wantErrors: []string{"1:23:sub:INVALID_CONTENT", "1:46:break:INVALID_CONTENT"}},
+		{name: "syntax", xml: "<speak>\n<break time=\"1s\"></speak>", wantErrors: []string{"2:26:break:XML_SYNTAX"}},
+		{name: "no speak", xml: ``, wantErrors: []string{"1:1::NO_SPEAK"}},
+		{name: "voice", xml: `<speak><voice name="olia">olia</voice></speak>`, wantErrors: []string{"1:8:voice:INVALID_TAG"}},
+		{name: "merged pauses", xml: `<speak>olia <break time="1s"/><break time="2s"/><p>olia</p></speak>`,
+			wantWarnings: []string{"1:31:break:MERGED_PAUSES", "1:49:p:MERGED_PAUSES"}},
+		{name: "paragraphs not warned", xml: `<speak><p>olia</p><p>olia</p></speak>`},
+		{name: "nested emphasis", xml: `<speak><emphasis>olia <prosody rate="slow"><emphasis>a</emphasis></prosody></emphasis></speak>`,
+			wantWarnings: []string{"1:44:emphasis:NESTED_EMPHASIS"}},
+		{name: "unknown language", xml: `<speak xml:lang="lt"><lang xml:lang="en-US">olia</lang><lang xml:lang="de">olia</lang></speak>`,
+			wantWarnings: []string{"1:56:lang:UNKNOWN_LANGUAGE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Validate(strings.NewReader(tt.xml), &Text{Voice: "aa"}, testVoiceFunc,
+				func(s string) bool { return s == "lt" || s == "en" })
+			assert.Equal(t, tt.wantErrors, toTestIssues(got.Errors))
+			assert.Equal(t, tt.wantWarnings, toTestIssues(got.Warnings))
+		})
+	}
+}
+
+func TestValidate_NoLanguageCheck(t *testing.T) {
+	got := Validate(strings.NewReader(`<speak><lang xml:lang="de">olia</lang></speak>`), &Text{Voice: "aa"}, testVoiceFunc, nil)
+	assert.Empty(t, got.Errors)
+	assert.Empty(t, got.Warnings)
+}
+
+func TestParse_ErrorPosition(t *testing.T) {
+	_, err := Parse(strings.NewReader("<speak>\n <olia/></speak>"), &Text{Voice: "aa"}, testVoiceFunc)
+	var errP *ErrParse
+	assert.ErrorAs(t, err, &errP)
+	assert.Equal(t, ErrParse{Pos: 16, Msg: "unknown tag <olia>", Line: 2, Column: 2, Tag: "olia", Code: CodeUnknownTag}, *errP)
+	assert.Equal(t, "ssml: 2:2: unknown tag <olia>", err.Error())
+}
+
+func TestValidate_TextLen(t *testing.T) {
+	got := Validate(strings.NewReader(`<speak>olia <break time="1s"/><s>ąžuolas</s><audio src="a"/></speak>`), &Text{Voice: "aa"}, testVoiceFunc, nil)
+	assert.Empty(t, got.Errors)
+	assert.Equal(t, 11, got.TextLen)
+}
+
+func testVoiceFunc(s string) (string, error) {
+	if s == "olia" {
+		return "", fmt.Errorf("unknown voice '%s'", s)
+	}
+	return s, nil
+}
+
+func toTestIssues(issues []*Issue) []string {
+	var res []string
+	for _, i := range issues {
+		res = append(res, fmt.Sprintf("%d:%d:%s:%s", i.Line, i.Column, i.Tag, i.Code))
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package ssml
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// StartTag returns <prosody> or <emphasis> start tag that is parsed back into the same prosody
+func (p *Prosody) StartTag() string {
+	if p.Emphasis != EmphasisTypeUnset {
+		return fmt.Sprintf(`<%s level="%s">`, TagEmphasis, emphasisLevelName(p.Emphasis))
+	}
+	res := strings.Builder{}
+	res.WriteString("<" + TagProsody)
+	if p.Rate != 1 || (p.Volume == 0 && p.Pitch.Kind == PitchChangeNone) {
+		fmt.Fprintf(&res, ` rate="%s%%"`, formatNum(rateToPercents(p.Rate)))
+	}
+	if p.Volume != 0 {
+		fmt.Fprintf(&res, ` volume="%s%sdB"`, sign(p.Volume), formatNum(p.Volume))
+	}
+	switch p.Pitch.Kind {
+	case PitchChangeHertz:
+		fmt.Fprintf(&res, ` pitch="%s%sHz"`, sign(p.Pitch.Value), formatNum(p.Pitch.Value))
+	case PitchChangeSemitone:
+		fmt.Fprintf(&res, ` pitch="%s%sst"`, sign(p.Pitch.Value), formatNum(p.Pitch.Value))
+	case PitchChangeMultiplier:
+		v := (p.Pitch.Value - 1) * 100
+		fmt.Fprintf(&res, ` pitch="%s%s%%"`, sign(v), formatNum(v))
+	}
+	res.WriteString(">")
+	return res.String()
+}
+
+// EndTag returns the end tag of StartTag
+func (p *Prosody) EndTag() string {
+	if p.Emphasis != EmphasisTypeUnset {
+		return "</" + TagEmphasis + ">"
+	}
+	return "</" + TagProsody + ">"
+}
+
+func emphasisLevelName(e EmphasisType) string {
+	for k, v := range emphasisLevels {
+		if v == e {
+			return k
+		}
+	}
+	return "moderate"
+}
+
+// rateToPercents is the inverse of parseRatePercents
+func rateToPercents(r float64) float64 {
+	if r >= 1 {
+		return 100 - (r-1)*50
+	}
+	return 100 + (1-r)*200
+}
+
+// formatNum drops the float noise of the calculations
+func formatNum(v float64) string {
+	return fmt.Sprintf("%g", math.Round(v*1e6)/1e6+0) // +0 drops the negative zero
+}
+
+func sign(v float64) string {
+	if v < 0 {
+		return ""
+	}
+	return "+"
+}
@@ -0,0 +1,61 @@
+package ssml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProsody_StartTag(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want string
+	}{
+		{name: "rate slow", xml: `<prosody rate="slow">`, want: `<prosody rate="75%">`},
+		{name: "rate fast", xml: `<prosody rate="150%">`, want: `<prosody rate="150%">`},
+		{name: "volume", xml: `<prosody volume="-3.5dB">`, want: `<prosody volume="-3.5dB">`},
+		{name: "silent", xml: `<prosody volume="silent">`, want: `<prosody volume="-1000dB">`},
+		{name: "pitch Hz", xml: `<prosody pitch="+10Hz">`, want: `<prosody pitch="+10Hz">`},
+		{name: "pitch st", xml: `<prosody pitch="-2st">`, want: `<prosody pitch="-2st">`},
+		{name: "pitch %", xml: `<prosody pitch="+10%">`, want: `<prosody pitch="+10%">`},
+		{name: "pitch default", xml: `<prosody pitch="default">`, want: `<prosody pitch="+0%">`},
+		{name: "all", xml: `<prosody rate="50%" volume="+3dB" pitch="low">`, want: `<prosody rate="50%" volume="+3dB" pitch="-20%">`},
+		{name: "emphasis", xml: `<emphasis>`, want: `<emphasis level="moderate">`},
+		{name: "emphasis reduced", xml: `<emphasis level="reduced">`, want: `<emphasis level="reduced">`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseTestProsody(t, tt.xml)
+			assert.Equal(t, tt.want, p.StartTag())
+			got := parseTestProsody(t, p.StartTag())
+			assert.InDelta(t, p.Rate, got.Rate, 1e-6)
+			assert.Equal(t, p.Volume, got.Volume)
+			assert.Equal(t, p.Pitch.Kind, got.Pitch.Kind)
+			assert.InDelta(t, p.Pitch.Value, got.Pitch.Value, 1e-6)
+			assert.Equal(t, p.Emphasis, got.Emphasis)
+		})
+	}
+}
+
+func TestProsody_EndTag(t *testing.T) {
+	assert.Equal(t, "</prosody>", (&Prosody{Rate: 2}).EndTag())
+	assert.Equal(t, "</emphasis>", (&Prosody{Rate: 1, Emphasis: EmphasisTypeStrong}).EndTag())
+}
+
+func parseTestProsody(t *testing.T, tag string) *Prosody {
+	t.Helper()
+	end := "</prosody>"
+	if strings.HasPrefix(tag, "<emphasis") {
+		end = "</emphasis>"
+	}
+	parts, err := Parse(strings.NewReader("<speak>"+tag+"olia"+end+"</speak>"), &Text{Voice: "aa"}, testVoiceFunc)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(parts))
+	txt, ok := parts[0].(*Text)
+	require.True(t, ok)
+	require.Equal(t, 1, len(txt.Prosodies))
+	return txt.Prosodies[0]
+}